@@ -21,13 +21,15 @@ func main() {
 	flag.StringVar(&migrationsDir, "migrations-dir", "/migrations", "Directory containing migration SQL files")
 	flag.Parse()
 
+	// lib/pq reads PGSSLROOTCERT from the environment itself.
 	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		envOrDefault("PGHOST", "localhost"),
 		envOrDefault("PGPORT", "5432"),
 		envOrDefault("PGUSER", "postgres"),
 		envOrDefault("PGPASSWORD", "postgres"),
 		envOrDefault("PGDATABASE", "postgres"),
+		envOrDefault("PGSSLMODE", "disable"),
 	)
 
 	db, err := sql.Open("postgres", dsn)
@@ -9,6 +9,7 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -72,6 +73,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Pods are watched only to label postgres replication roles, so restrict the
+	// cache to pods created from operator-managed templates.
+	managedPodSelector, selectorErr := labels.Parse("app.kubernetes.io/managed-by=db-operator")
+	if selectorErr != nil {
+		setupLog.Error(selectorErr, "unable to build managed pod label selector")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
@@ -84,10 +93,13 @@ func main() {
 			ByObject: map[client.Object]cache.ByObject{
 				&v1alpha1.PostgresDatabase{}:   {Label: instanceSelector},
 				&v1alpha1.PostgresCredential{}: {Label: instanceSelector},
+				&v1alpha1.PostgresRole{}:       {Label: instanceSelector},
+				&v1alpha1.PostgresRestore{}:    {Label: instanceSelector},
 				&v1alpha1.RedisDatabase{}:      {Label: instanceSelector},
 				&v1alpha1.RedisCredential{}:    {Label: instanceSelector},
 				&v1alpha1.NatsCluster{}:        {Label: instanceSelector},
 				&v1alpha1.NatsAccount{}:        {Label: instanceSelector},
+				&corev1.Pod{}:                  {Label: managedPodSelector},
 			},
 		},
 	})
@@ -119,6 +131,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := (&controller.PostgresRoleReconciler{
+		InstanceName: instanceName,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PostgresRole")
+		os.Exit(1)
+	}
+
+	if err := (&controller.PostgresRestoreReconciler{
+		InstanceName: instanceName,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PostgresRestore")
+		os.Exit(1)
+	}
+
 	if err := (&controller.RedisDatabaseReconciler{
 		InstanceName: instanceName,
 	}).SetupWithManager(mgr); err != nil {
@@ -147,6 +173,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := controller.SetupResourceMetrics(mgr); err != nil {
+		setupLog.Error(err, "unable to register resource metrics")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -0,0 +1,71 @@
+package certs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benjamin-wright/db-operator/internal/certs"
+)
+
+var (
+	now   = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	names = []string{"db-primary.ns.svc.cluster.local", "*.db.ns.svc.cluster.local"}
+)
+
+// issue returns a CA and a server certificate for names signed by it.
+func issue(t *testing.T, validity time.Duration) (caPEM, certPEM []byte) {
+	t.Helper()
+	caPEM, caKey, err := certs.NewCA("test-ca", now, 10*365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("NewCA: %v", err)
+	}
+	certPEM, _, err = certs.IssueServer(caPEM, caKey, names, now, validity)
+	if err != nil {
+		t.Fatalf("IssueServer: %v", err)
+	}
+	return caPEM, certPEM
+}
+
+func TestIssueServer_CoversNames(t *testing.T) {
+	caPEM, certPEM := issue(t, 365*24*time.Hour)
+
+	cert, err := certs.ParseCertificate(certPEM)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	if cert.Subject.CommonName != names[0] {
+		t.Errorf("common name: got %q, want %q", cert.Subject.CommonName, names[0])
+	}
+	if certs.NeedsRenewal(certPEM, caPEM, []string{"db-primary.ns.svc.cluster.local", "db-0.db.ns.svc.cluster.local"}, now, 30*24*time.Hour) {
+		t.Error("freshly issued certificate should not need renewal")
+	}
+}
+
+func TestNeedsRenewal_NearExpiry(t *testing.T) {
+	caPEM, certPEM := issue(t, 20*24*time.Hour)
+	if !certs.NeedsRenewal(certPEM, caPEM, names, now, 30*24*time.Hour) {
+		t.Error("certificate expiring within the renewal window should need renewal")
+	}
+}
+
+func TestNeedsRenewal_MissingName(t *testing.T) {
+	caPEM, certPEM := issue(t, 365*24*time.Hour)
+	if !certs.NeedsRenewal(certPEM, caPEM, []string{"db-ro.ns.svc.cluster.local"}, now, 30*24*time.Hour) {
+		t.Error("certificate missing a required name should need renewal")
+	}
+}
+
+func TestNeedsRenewal_OtherCA(t *testing.T) {
+	_, certPEM := issue(t, 365*24*time.Hour)
+	otherCA, _ := issue(t, 365*24*time.Hour)
+	if !certs.NeedsRenewal(certPEM, otherCA, names, now, 30*24*time.Hour) {
+		t.Error("certificate signed by a different CA should need renewal")
+	}
+}
+
+func TestNeedsRenewal_Garbage(t *testing.T) {
+	caPEM, _ := issue(t, 365*24*time.Hour)
+	if !certs.NeedsRenewal([]byte("not a certificate"), caPEM, names, now, 30*24*time.Hour) {
+		t.Error("unparsable certificate should need renewal")
+	}
+}
@@ -110,6 +110,8 @@ func newExecSQL(index *pgwatcher.Index) func(context.Context, *mcp.CallToolReque
 			Port:     info.Port,
 			User:     info.User,
 			Password: info.Password,
+			SSLMode:  info.SSLMode,
+			CACert:   info.CACert,
 		}, input.Database, input.SQL, rowLimit)
 		if err != nil {
 			return nil, nil, err
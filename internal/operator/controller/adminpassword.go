@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+// pendingPasswordSuffix is appended to the password key of an admin Secret to
+// stage the next password while a rotation is under way. The password is
+// written there before the server is changed, so a rotation interrupted
+// between the two steps can be finished instead of losing the password.
+const pendingPasswordSuffix = "_PENDING"
+
+// adminPasswordRotationDue reports whether the admin password of obj should be
+// rotated now: either the RotateAdminPasswordAnnotation carries a value not yet
+// acted on, or spec.adminPasswordRotation's interval has passed since the last
+// rotation (or since obj was created, before the first). It also returns the
+// annotation value to record once the rotation is done.
+func adminPasswordRotationDue(
+	obj metav1.Object,
+	rotation *v1alpha1.AdminPasswordRotation,
+	rotatedAt *metav1.Time,
+	lastRequest string,
+) (bool, string) {
+	request := obj.GetAnnotations()[v1alpha1.RotateAdminPasswordAnnotation]
+	if request != "" && request != lastRequest {
+		return true, request
+	}
+	if rotation == nil {
+		return false, lastRequest
+	}
+	return !time.Now().Before(nextAdminPasswordRotation(obj, rotation, rotatedAt)), lastRequest
+}
+
+// nextAdminPasswordRotation returns when the next scheduled rotation is due.
+func nextAdminPasswordRotation(obj metav1.Object, rotation *v1alpha1.AdminPasswordRotation, rotatedAt *metav1.Time) time.Time {
+	last := obj.GetCreationTimestamp().Time
+	if rotatedAt != nil {
+		last = rotatedAt.Time
+	}
+	return last.Add(rotation.Interval.Duration)
+}
+
+// requeueForAdminPasswordRotation shortens result so the reconciler runs again
+// when the next scheduled rotation of obj is due.
+func requeueForAdminPasswordRotation(result *ctrl.Result, obj metav1.Object, rotation *v1alpha1.AdminPasswordRotation, rotatedAt *metav1.Time) {
+	if rotation != nil {
+		requeueAt(result, nextAdminPasswordRotation(obj, rotation, rotatedAt))
+	}
+}
+
+// requeueAt shortens result so the reconciler runs again by at.
+func requeueAt(result *ctrl.Result, at time.Time) {
+	wait := max(time.Until(at), time.Second)
+	if result.RequeueAfter == 0 || wait < result.RequeueAfter {
+		result.RequeueAfter = wait
+	}
+}
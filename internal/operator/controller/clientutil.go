@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+// objectGetter is the read side shared by the per-kind clients.
+type objectGetter interface {
+	get(ctx context.Context, key client.ObjectKey, obj client.Object) (bool, error)
+}
+
+// objectLister is the list side shared by the per-kind clients.
+type objectLister interface {
+	list(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error
+}
+
+// activeRestore returns the name of a PostgresRestore targeting pgdb that has
+// not yet finished, or "" when there is none. Users and grants are not touched
+// while a restore runs, since the replayed dump recreates roles and databases.
+func activeRestore(ctx context.Context, c objectLister, pgdb *v1alpha1.PostgresDatabase) (string, error) {
+	var restores v1alpha1.PostgresRestoreList
+	if err := c.list(ctx, &restores, client.InNamespace(pgdb.Namespace)); err != nil {
+		return "", fmt.Errorf("listing PostgresRestores: %w", err)
+	}
+	for i := range restores.Items {
+		restore := &restores.Items[i]
+		if restore.Spec.DatabaseRef == pgdb.Name && !restoreFinished(restore) {
+			return restore.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// objectReader combines objectGetter and objectLister.
+type objectReader interface {
+	objectGetter
+	objectLister
+}
+
+// adminConnWait explains why postgresAdminConn cannot connect yet, as the
+// reason and message of a Pending phase.
+type adminConnWait struct {
+	reason, message string
+}
+
+// postgresAdminConn fetches the PostgresDatabase named dbRef in namespace and
+// returns it with an admin connection to its primary. When the database cannot
+// be managed yet (not Ready, being restored, or missing its admin Secret or CA
+// bundle) it returns a non-nil wait instead.
+func postgresAdminConn(ctx context.Context, c objectReader, namespace, dbRef string) (*v1alpha1.PostgresDatabase, PostgresConn, *adminConnWait, error) {
+	var pgdb v1alpha1.PostgresDatabase
+	found, err := c.get(ctx, client.ObjectKey{Name: dbRef, Namespace: namespace}, &pgdb)
+	if err != nil {
+		return nil, PostgresConn{}, nil, fmt.Errorf("fetching target PostgresDatabase: %w", err)
+	}
+	if !found {
+		return nil, PostgresConn{}, &adminConnWait{"DatabaseNotFound",
+			fmt.Sprintf("target PostgresDatabase %q not found", dbRef)}, nil
+	}
+
+	if pgdb.Status.Phase != v1alpha1.DatabasePhaseReady {
+		return nil, PostgresConn{}, &adminConnWait{"DatabaseNotReady",
+			fmt.Sprintf("waiting for PostgresDatabase %q to become Ready", dbRef)}, nil
+	}
+
+	restoring, err := activeRestore(ctx, c, &pgdb)
+	if err != nil {
+		return nil, PostgresConn{}, nil, err
+	}
+	if restoring != "" {
+		return nil, PostgresConn{}, &adminConnWait{"RestoreInProgress",
+			fmt.Sprintf("waiting for PostgresRestore %q to finish", restoring)}, nil
+	}
+
+	conn, wait, err := postgresPrimaryConn(ctx, c, &pgdb)
+	if err != nil || wait != nil {
+		return nil, PostgresConn{}, wait, err
+	}
+	return &pgdb, conn, nil, nil
+}
+
+// postgresPrimaryConn returns an admin connection to the primary of pgdb,
+// without checking that the database is Ready or free of restores.
+func postgresPrimaryConn(ctx context.Context, c objectGetter, pgdb *v1alpha1.PostgresDatabase) (PostgresConn, *adminConnWait, error) {
+	if pgdb.Status.SecretName == "" {
+		return PostgresConn{}, &adminConnWait{"AdminSecretNotReady",
+			"PostgresDatabase admin Secret name is not yet populated"}, nil
+	}
+
+	var adminSecret corev1.Secret
+	adminFound, err := c.get(ctx, client.ObjectKey{Name: pgdb.Status.SecretName, Namespace: pgdb.Namespace}, &adminSecret)
+	if err != nil {
+		return PostgresConn{}, nil, fmt.Errorf("fetching admin Secret %q: %w", pgdb.Status.SecretName, err)
+	}
+	if !adminFound {
+		return PostgresConn{}, &adminConnWait{"AdminSecretNotFound",
+			fmt.Sprintf("admin Secret %q not yet visible in cache", pgdb.Status.SecretName)}, nil
+	}
+
+	caCert, err := postgresCACert(ctx, c, pgdb)
+	if errors.Is(err, errCertificateNotReady) {
+		return PostgresConn{}, &adminConnWait{"TLSNotReady",
+			fmt.Sprintf("waiting for the CA bundle of PostgresDatabase %q", pgdb.Name)}, nil
+	}
+	if err != nil {
+		return PostgresConn{}, nil, err
+	}
+
+	return PostgresConn{
+		Host:     postgresHost(pgdb),
+		User:     string(adminSecret.Data["PGUSER"]),
+		Password: string(adminSecret.Data["PGPASSWORD"]),
+		CACert:   caCert,
+	}, nil, nil
+}
+
+// postgresCACert returns the CA bundle that clients verify pgdb's server
+// certificate against, or "" when TLS is disabled. It returns
+// errCertificateNotReady until the TLS Secret carries a CA bundle.
+func postgresCACert(ctx context.Context, c objectGetter, pgdb *v1alpha1.PostgresDatabase) (string, error) {
+	if pgdb.Spec.TLS == nil {
+		return "", nil
+	}
+	var secret corev1.Secret
+	found, err := c.get(ctx, client.ObjectKey{Namespace: pgdb.Namespace, Name: tlsSecretName(pgdb)}, &secret)
+	if err != nil {
+		return "", fmt.Errorf("fetching TLS Secret: %w", err)
+	}
+	if !found || len(secret.Data["ca.crt"]) == 0 {
+		return "", errCertificateNotReady
+	}
+	return string(secret.Data["ca.crt"]), nil
+}
+
+// isConflict reports whether err is a Kubernetes API conflict error.
+func isConflict(err error) bool { return apierrors.IsConflict(err) }
+
+// isForbidden reports whether err is a Kubernetes API forbidden error.
+func isForbidden(err error) bool { return apierrors.IsForbidden(err) }
+
+// isNotFound reports whether err is a Kubernetes API not-found error.
+func isNotFound(err error) bool { return apierrors.IsNotFound(err) }
+
+// pvcName returns the deterministic PVC name for the given StatefulSet ordinal.
+func pvcName(stsName string, ordinal int) string {
+	return fmt.Sprintf("data-%s-%d", stsName, ordinal)
+}
+
+// postgresClaimKeys returns the keys of the data PVCs for the first n
+// StatefulSet ordinals.
+func postgresClaimKeys(pgdb *v1alpha1.PostgresDatabase, n int32) []client.ObjectKey {
+	keys := make([]client.ObjectKey, 0, n)
+	for i := range int(n) {
+		keys = append(keys, client.ObjectKey{Namespace: pgdb.Namespace, Name: pvcName(statefulSetName(pgdb), i)})
+	}
+	return keys
+}
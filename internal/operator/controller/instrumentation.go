@@ -0,0 +1,381 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/benjamin-wright/db-operator/internal/redisacl"
+	"github.com/benjamin-wright/db-operator/internal/rediscluster"
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+var (
+	postgresOperationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_operator_postgres_operation_failures_total",
+		Help: "Calls to a PostgreSQL instance that returned an error, by operation.",
+	}, []string{"operation"})
+
+	redisOperationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_operator_redis_operation_failures_total",
+		Help: "Calls to a Redis instance that returned an error, by operation.",
+	}, []string{"operation"})
+
+	postgresEnsureUserDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "db_operator_postgres_ensure_user_duration_seconds",
+		Help:    "Time taken to create or update a PostgreSQL login role and its grants.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	redisEnsureACLUserDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "db_operator_redis_ensure_acl_user_duration_seconds",
+		Help:    "Time taken to create or update a Redis ACL user.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	resourcesDesc = prometheus.NewDesc(
+		"db_operator_resources",
+		"Custom resources handled by this operator instance, by kind and status phase.",
+		[]string{"kind", "phase"}, nil,
+	)
+
+	pendingCredentialsDesc = prometheus.NewDesc(
+		"db_operator_credentials_pending_database",
+		"Credentials in the Pending phase whose target database is missing or not Ready, by kind.",
+		[]string{"kind"}, nil,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		postgresOperationFailures,
+		redisOperationFailures,
+		postgresEnsureUserDuration,
+		redisEnsureACLUserDuration,
+	)
+}
+
+// SetupResourceMetrics registers a collector that reports the phase of every
+// custom resource in the manager's cache each time the metrics endpoint is
+// scraped, so deleted resources drop out without any bookkeeping.
+func SetupResourceMetrics(mgr ctrl.Manager) error {
+	return metrics.Registry.Register(resourceCollector{reader: mgr.GetCache()})
+}
+
+// resourceCollector computes the per-phase gauges from the cache at scrape
+// time. The cache is already limited to this operator instance's resources.
+type resourceCollector struct {
+	reader client.Reader
+}
+
+func (c resourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- resourcesDesc
+	ch <- pendingCredentialsDesc
+}
+
+func (c resourceCollector) Collect(ch chan<- prometheus.Metric) {
+	var (
+		pgdbs    v1alpha1.PostgresDatabaseList
+		pgcreds  v1alpha1.PostgresCredentialList
+		pgroles  v1alpha1.PostgresRoleList
+		restores v1alpha1.PostgresRestoreList
+		rdbs     v1alpha1.RedisDatabaseList
+		rcreds   v1alpha1.RedisCredentialList
+		clusters v1alpha1.NatsClusterList
+		accounts v1alpha1.NatsAccountList
+	)
+
+	pgdbsListed := c.collectPhases(ch, "PostgresDatabase", &pgdbs, func() []string {
+		return phasesOf(pgdbs.Items, func(o *v1alpha1.PostgresDatabase) v1alpha1.DatabasePhase { return o.Status.Phase })
+	})
+	pgcredsListed := c.collectPhases(ch, "PostgresCredential", &pgcreds, func() []string {
+		return phasesOf(pgcreds.Items, func(o *v1alpha1.PostgresCredential) v1alpha1.CredentialPhase { return o.Status.Phase })
+	})
+	c.collectPhases(ch, "PostgresRole", &pgroles, func() []string {
+		return phasesOf(pgroles.Items, func(o *v1alpha1.PostgresRole) v1alpha1.PostgresRolePhase { return o.Status.Phase })
+	})
+	c.collectPhases(ch, "PostgresRestore", &restores, func() []string {
+		return phasesOf(restores.Items, func(o *v1alpha1.PostgresRestore) v1alpha1.RestorePhase { return o.Status.Phase })
+	})
+	rdbsListed := c.collectPhases(ch, "RedisDatabase", &rdbs, func() []string {
+		return phasesOf(rdbs.Items, func(o *v1alpha1.RedisDatabase) v1alpha1.RedisDatabasePhase { return o.Status.Phase })
+	})
+	rcredsListed := c.collectPhases(ch, "RedisCredential", &rcreds, func() []string {
+		return phasesOf(rcreds.Items, func(o *v1alpha1.RedisCredential) v1alpha1.RedisCredentialPhase { return o.Status.Phase })
+	})
+	c.collectPhases(ch, "NatsCluster", &clusters, func() []string {
+		return phasesOf(clusters.Items, func(o *v1alpha1.NatsCluster) v1alpha1.NatsClusterPhase { return o.Status.Phase })
+	})
+	c.collectPhases(ch, "NatsAccount", &accounts, func() []string {
+		return phasesOf(accounts.Items, func(o *v1alpha1.NatsAccount) v1alpha1.NatsAccountPhase { return o.Status.Phase })
+	})
+
+	if pgdbsListed && pgcredsListed {
+		ready := map[client.ObjectKey]bool{}
+		for i := range pgdbs.Items {
+			ready[client.ObjectKeyFromObject(&pgdbs.Items[i])] = pgdbs.Items[i].Status.Phase == v1alpha1.DatabasePhaseReady
+		}
+		pending := 0
+		for _, cred := range pgcreds.Items {
+			key := client.ObjectKey{Namespace: cred.Namespace, Name: cred.Spec.DatabaseRef}
+			if cred.Status.Phase == v1alpha1.CredentialPhasePending && !ready[key] {
+				pending++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(pendingCredentialsDesc, prometheus.GaugeValue, float64(pending), "PostgresCredential")
+	}
+	if rdbsListed && rcredsListed {
+		ready := map[client.ObjectKey]bool{}
+		for i := range rdbs.Items {
+			ready[client.ObjectKeyFromObject(&rdbs.Items[i])] = rdbs.Items[i].Status.Phase == v1alpha1.RedisDatabasePhaseReady
+		}
+		pending := 0
+		for _, cred := range rcreds.Items {
+			key := client.ObjectKey{Namespace: cred.Namespace, Name: cred.Spec.DatabaseRef}
+			if cred.Status.Phase == v1alpha1.RedisCredentialPhasePending && !ready[key] {
+				pending++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(pendingCredentialsDesc, prometheus.GaugeValue, float64(pending), "RedisCredential")
+	}
+}
+
+// collectPhases lists kind into list and reports how many items are in each
+// phase. It returns false, reporting nothing, when the list fails, e.g. before
+// the cache has synced.
+func (c resourceCollector) collectPhases(ch chan<- prometheus.Metric, kind string, list client.ObjectList, phases func() []string) bool {
+	if err := c.reader.List(context.Background(), list); err != nil {
+		ctrl.Log.WithName("metrics").V(1).Info("skipping resource metrics", "kind", kind, "error", err.Error())
+		return false
+	}
+	counts := map[string]int{}
+	for _, phase := range phases() {
+		counts[phase]++
+	}
+	for phase, n := range counts {
+		ch <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(n), kind, phase)
+	}
+	return true
+}
+
+// phasesOf returns the phase of each item, reporting resources not yet
+// reconciled as Pending.
+func phasesOf[T any, P ~string](items []T, phase func(*T) P) []string {
+	phases := make([]string, 0, len(items))
+	for i := range items {
+		p := string(phase(&items[i]))
+		if p == "" {
+			p = "Pending"
+		}
+		phases = append(phases, p)
+	}
+	return phases
+}
+
+// countPostgresFailure records a failed call to a PostgreSQL instance and
+// returns err unchanged.
+func countPostgresFailure(operation string, err error) error {
+	if err != nil {
+		postgresOperationFailures.WithLabelValues(operation).Inc()
+	}
+	return err
+}
+
+// countRedisFailure records a failed call to a Redis instance and returns err
+// unchanged.
+func countRedisFailure(operation string, err error) error {
+	if err != nil {
+		redisOperationFailures.WithLabelValues(operation).Inc()
+	}
+	return err
+}
+
+// instrumentedPostgresManager wraps a PostgresManager, counting failed calls
+// and timing EnsureUser.
+type instrumentedPostgresManager struct {
+	inner PostgresManager
+}
+
+func (m instrumentedPostgresManager) EnsureDatabase(conn PostgresConn, dbName string) error {
+	return countPostgresFailure("EnsureDatabase", m.inner.EnsureDatabase(conn, dbName))
+}
+
+func (m instrumentedPostgresManager) EnsureExtensions(conn PostgresConn, dbName string, extensions []v1alpha1.PostgresExtension) ([]v1alpha1.PostgresExtensionStatus, error) {
+	statuses, err := m.inner.EnsureExtensions(conn, dbName, extensions)
+	return statuses, countPostgresFailure("EnsureExtensions", err)
+}
+
+func (m instrumentedPostgresManager) EnsureUser(conn PostgresConn, dbName, username, password string, entry v1alpha1.DatabasePermissionEntry) error {
+	start := time.Now()
+	err := m.inner.EnsureUser(conn, dbName, username, password, entry)
+	postgresEnsureUserDuration.Observe(time.Since(start).Seconds())
+	return countPostgresFailure("EnsureUser", err)
+}
+
+func (m instrumentedPostgresManager) DropUser(conn PostgresConn, dbName, username string) error {
+	return countPostgresFailure("DropUser", m.inner.DropUser(conn, dbName, username))
+}
+
+func (m instrumentedPostgresManager) SetPassword(conn PostgresConn, username, password string) error {
+	return countPostgresFailure("SetPassword", m.inner.SetPassword(conn, username, password))
+}
+
+func (m instrumentedPostgresManager) EnsureAlias(conn PostgresConn, alias, username string) error {
+	return countPostgresFailure("EnsureAlias", m.inner.EnsureAlias(conn, alias, username))
+}
+
+func (m instrumentedPostgresManager) EnsureOwner(conn PostgresConn, dbName, username string) error {
+	return countPostgresFailure("EnsureOwner", m.inner.EnsureOwner(conn, dbName, username))
+}
+
+func (m instrumentedPostgresManager) FindOwner(conn PostgresConn, dbName string) (string, error) {
+	owner, err := m.inner.FindOwner(conn, dbName)
+	return owner, countPostgresFailure("FindOwner", err)
+}
+
+func (m instrumentedPostgresManager) ListDatabases(conn PostgresConn) (map[string]LogicalDatabase, error) {
+	databases, err := m.inner.ListDatabases(conn)
+	return databases, countPostgresFailure("ListDatabases", err)
+}
+
+func (m instrumentedPostgresManager) DropDatabase(conn PostgresConn, dbName string) error {
+	return countPostgresFailure("DropDatabase", m.inner.DropDatabase(conn, dbName))
+}
+
+func (m instrumentedPostgresManager) WALPosition(conn PostgresConn) (int64, error) {
+	pos, err := m.inner.WALPosition(conn)
+	return pos, countPostgresFailure("WALPosition", err)
+}
+
+func (m instrumentedPostgresManager) Promote(conn PostgresConn) error {
+	return countPostgresFailure("Promote", m.inner.Promote(conn))
+}
+
+func (m instrumentedPostgresManager) ConfigRevision(conn PostgresConn) (string, error) {
+	revision, err := m.inner.ConfigRevision(conn)
+	return revision, countPostgresFailure("ConfigRevision", err)
+}
+
+func (m instrumentedPostgresManager) ReloadConfig(conn PostgresConn) error {
+	return countPostgresFailure("ReloadConfig", m.inner.ReloadConfig(conn))
+}
+
+func (m instrumentedPostgresManager) InvalidParameters(conn PostgresConn) ([]string, error) {
+	names, err := m.inner.InvalidParameters(conn)
+	return names, countPostgresFailure("InvalidParameters", err)
+}
+
+func (m instrumentedPostgresManager) SetPrimaryConnPassword(conn PostgresConn, password string) error {
+	return countPostgresFailure("SetPrimaryConnPassword", m.inner.SetPrimaryConnPassword(conn, password))
+}
+
+func (m instrumentedPostgresManager) SetRoleAttributes(conn PostgresConn, username string, attrs RoleAttributes) error {
+	return countPostgresFailure("SetRoleAttributes", m.inner.SetRoleAttributes(conn, username, attrs))
+}
+
+func (m instrumentedPostgresManager) EnsureMonitoringRole(conn PostgresConn, roleName string) error {
+	return countPostgresFailure("EnsureMonitoringRole", m.inner.EnsureMonitoringRole(conn, roleName))
+}
+
+func (m instrumentedPostgresManager) EnsureGroupRole(conn PostgresConn, dbName, roleName string, entry v1alpha1.DatabasePermissionEntry) error {
+	return countPostgresFailure("EnsureGroupRole", m.inner.EnsureGroupRole(conn, dbName, roleName, entry))
+}
+
+func (m instrumentedPostgresManager) SetMemberships(conn PostgresConn, username string, roles []string) ([]string, error) {
+	revoked, err := m.inner.SetMemberships(conn, username, roles)
+	return revoked, countPostgresFailure("SetMemberships", err)
+}
+
+func (m instrumentedPostgresManager) RevokeUndeclared(conn PostgresConn, username string, entries []v1alpha1.DatabasePermissionEntry) ([]string, error) {
+	revoked, err := m.inner.RevokeUndeclared(conn, username, entries)
+	return revoked, countPostgresFailure("RevokeUndeclared", err)
+}
+
+// instrumentedRedisManager wraps a RedisManager, counting failed calls and
+// timing EnsureACLUser.
+type instrumentedRedisManager struct {
+	inner RedisManager
+}
+
+func (m instrumentedRedisManager) EnsureACLUser(ctx context.Context, host, adminPass, username, password string, keyPatterns []string, aclCategories []v1alpha1.RedisACLCategory, commands []string) error {
+	start := time.Now()
+	err := m.inner.EnsureACLUser(ctx, host, adminPass, username, password, keyPatterns, aclCategories, commands)
+	redisEnsureACLUserDuration.Observe(time.Since(start).Seconds())
+	return countRedisFailure("EnsureACLUser", err)
+}
+
+func (m instrumentedRedisManager) GetACLUser(ctx context.Context, host, adminPass, username string) (*redisacl.User, error) {
+	user, err := m.inner.GetACLUser(ctx, host, adminPass, username)
+	return user, countRedisFailure("GetACLUser", err)
+}
+
+func (m instrumentedRedisManager) DropACLUser(ctx context.Context, host, adminPass, username string) error {
+	return countRedisFailure("DropACLUser", m.inner.DropACLUser(ctx, host, adminPass, username))
+}
+
+func (m instrumentedRedisManager) AddDefaultPassword(ctx context.Context, host, adminPass, password string) error {
+	return countRedisFailure("AddDefaultPassword", m.inner.AddDefaultPassword(ctx, host, adminPass, password))
+}
+
+func (m instrumentedRedisManager) ResetDefaultPasswords(ctx context.Context, host, password string) error {
+	return countRedisFailure("ResetDefaultPasswords", m.inner.ResetDefaultPasswords(ctx, host, password))
+}
+
+func (m instrumentedRedisManager) ConfigureMemory(ctx context.Context, host, adminPass string, maxMemory int64, policy v1alpha1.RedisEvictionPolicy) error {
+	return countRedisFailure("ConfigureMemory", m.inner.ConfigureMemory(ctx, host, adminPass, maxMemory, policy))
+}
+
+func (m instrumentedRedisManager) SetMasterAuth(ctx context.Context, host, adminPass, masterAuth string) error {
+	return countRedisFailure("SetMasterAuth", m.inner.SetMasterAuth(ctx, host, adminPass, masterAuth))
+}
+
+func (m instrumentedRedisManager) SentinelMaster(ctx context.Context, sentinelHost, adminPass, masterName string) (string, error) {
+	host, err := m.inner.SentinelMaster(ctx, sentinelHost, adminPass, masterName)
+	return host, countRedisFailure("SentinelMaster", err)
+}
+
+func (m instrumentedRedisManager) AddSentinelPassword(ctx context.Context, sentinelHost, adminPass, password string) error {
+	return countRedisFailure("AddSentinelPassword", m.inner.AddSentinelPassword(ctx, sentinelHost, adminPass, password))
+}
+
+func (m instrumentedRedisManager) ResetSentinelPasswords(ctx context.Context, sentinelHost, password string) error {
+	return countRedisFailure("ResetSentinelPasswords", m.inner.ResetSentinelPasswords(ctx, sentinelHost, password))
+}
+
+func (m instrumentedRedisManager) SetSentinelAuth(ctx context.Context, sentinelHost, adminPass, masterName, password string) error {
+	return countRedisFailure("SetSentinelAuth", m.inner.SetSentinelAuth(ctx, sentinelHost, adminPass, masterName, password))
+}
+
+func (m instrumentedRedisManager) ClusterNodes(ctx context.Context, host, adminPass string) (string, error) {
+	nodes, err := m.inner.ClusterNodes(ctx, host, adminPass)
+	return nodes, countRedisFailure("ClusterNodes", err)
+}
+
+func (m instrumentedRedisManager) ClusterState(ctx context.Context, host, adminPass string) (string, error) {
+	state, err := m.inner.ClusterState(ctx, host, adminPass)
+	return state, countRedisFailure("ClusterState", err)
+}
+
+func (m instrumentedRedisManager) ClusterMeet(ctx context.Context, host, adminPass, ip string) error {
+	return countRedisFailure("ClusterMeet", m.inner.ClusterMeet(ctx, host, adminPass, ip))
+}
+
+func (m instrumentedRedisManager) ClusterAddSlots(ctx context.Context, host, adminPass string, slots rediscluster.SlotRange) error {
+	return countRedisFailure("ClusterAddSlots", m.inner.ClusterAddSlots(ctx, host, adminPass, slots))
+}
+
+func (m instrumentedRedisManager) ClusterReplicate(ctx context.Context, host, adminPass, masterID string) error {
+	return countRedisFailure("ClusterReplicate", m.inner.ClusterReplicate(ctx, host, adminPass, masterID))
+}
+
+func (m instrumentedRedisManager) ClusterForget(ctx context.Context, host, adminPass, nodeID string) error {
+	return countRedisFailure("ClusterForget", m.inner.ClusterForget(ctx, host, adminPass, nodeID))
+}
+
+func (m instrumentedRedisManager) ClusterMigrateSlot(ctx context.Context, adminPass string, slot int, from, to RedisClusterPeer) error {
+	return countRedisFailure("ClusterMigrateSlot", m.inner.ClusterMigrateSlot(ctx, adminPass, slot, from, to))
+}
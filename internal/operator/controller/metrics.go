@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+const (
+	// Default exporter images, used unless spec.metrics.image overrides them.
+	postgresExporterImage = "quay.io/prometheuscommunity/postgres-exporter:v0.16.0"
+	redisExporterImage    = "oliver006/redis_exporter:v1.67.0"
+	natsExporterImage     = "natsio/prometheus-nats-exporter:0.16.0"
+
+	// postgresExporterRole is the login role postgres_exporter connects as.
+	// It is a member of pg_monitor and has no password.
+	postgresExporterRole = "postgres_exporter"
+
+	// redisExporterUser is the ACL user redis_exporter logs in as.
+	redisExporterUser = "redis_exporter"
+
+	// Ports each exporter serves /metrics on, its upstream default.
+	postgresMetricsPort = 9187
+	redisMetricsPort    = 9121
+	natsMetricsPort     = 7777
+
+	// metricsPortName names the exporter's container and Service port, and is
+	// the port a ServiceMonitor scrapes.
+	metricsPortName = "metrics"
+)
+
+// redisExporterCommands are the commands redis_exporter needs to read server
+// statistics. CONFIG is left out, since CONFIG GET reveals the admin password,
+// and the exporter is told to skip it.
+var redisExporterCommands = []string{
+	"ping", "info", "client|setname", "slowlog|get", "slowlog|len",
+	"latency|latest", "latency|histogram", "cluster|info",
+}
+
+// serviceMonitorGVK identifies Prometheus Operator ServiceMonitors. Like
+// cert-manager Certificates they are handled as unstructured objects, so the
+// operator runs whether or not the CRD is installed.
+var serviceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+// objectWriter is the write side shared by the per-kind clients.
+type objectWriter interface {
+	objectGetter
+	create(ctx context.Context, obj client.Object) error
+	update(ctx context.Context, obj client.Object) error
+	delete(ctx context.Context, obj client.Object) error
+}
+
+// metricsContainer returns the exporter sidecar for metrics, listening on
+// port. Callers add the arguments and environment the exporter needs.
+func metricsContainer(metrics *v1alpha1.MetricsSpec, defaultImage string, port int32) corev1.Container {
+	image := metrics.Image
+	if image == "" {
+		image = defaultImage
+	}
+	container := corev1.Container{
+		Name:  metricsPortName,
+		Image: image,
+		Ports: []corev1.ContainerPort{
+			{Name: metricsPortName, ContainerPort: port, Protocol: corev1.ProtocolTCP},
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(port)},
+			},
+			PeriodSeconds: 10,
+		},
+	}
+	if metrics.Resources != nil {
+		container.Resources = *metrics.Resources.DeepCopy()
+	}
+	return container
+}
+
+// metricsServicePort exposes the exporter sidecar on a Service.
+func metricsServicePort(port int32) corev1.ServicePort {
+	return corev1.ServicePort{
+		Name:       metricsPortName,
+		Port:       port,
+		TargetPort: intstr.FromString(metricsPortName),
+		Protocol:   corev1.ProtocolTCP,
+	}
+}
+
+// desiredServiceMonitor scrapes the metrics port of the Services carrying
+// selector in owner's namespace. It is named after owner and returns nil when
+// metrics or its ServiceMonitor are not requested.
+func desiredServiceMonitor(owner client.Object, scheme *runtime.Scheme, metrics *v1alpha1.MetricsSpec, selector map[string]string) *unstructured.Unstructured {
+	if metrics == nil || metrics.ServiceMonitor == nil {
+		return nil
+	}
+
+	matchLabels := map[string]any{}
+	for k, v := range selector {
+		matchLabels[k] = v
+	}
+	endpoint := map[string]any{"port": metricsPortName}
+	if metrics.ServiceMonitor.Interval != "" {
+		endpoint["interval"] = metrics.ServiceMonitor.Interval
+	}
+
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(serviceMonitorGVK)
+	monitor.SetName(owner.GetName())
+	monitor.SetNamespace(owner.GetNamespace())
+	monitor.SetLabels(mergeOperatorKeys(selector, metrics.ServiceMonitor.Labels))
+	monitor.Object["spec"] = map[string]any{
+		"selector":  map[string]any{"matchLabels": matchLabels},
+		"endpoints": []any{endpoint},
+	}
+	_ = controllerutil.SetControllerReference(owner, monitor, scheme)
+	return monitor
+}
+
+// reconcileServiceMonitor creates or updates desired, or deletes the
+// ServiceMonitor named by key when desired is nil. Nothing is done while the
+// ServiceMonitor CRD is not installed.
+func reconcileServiceMonitor(ctx context.Context, c objectWriter, key types.NamespacedName, desired *unstructured.Unstructured) error {
+	if desired == nil {
+		monitor := &unstructured.Unstructured{}
+		monitor.SetGroupVersionKind(serviceMonitorGVK)
+		monitor.SetName(key.Name)
+		monitor.SetNamespace(key.Namespace)
+		if err := c.delete(ctx, monitor); err != nil && !meta.IsNoMatchError(err) {
+			return fmt.Errorf("deleting ServiceMonitor: %w", err)
+		}
+		return nil
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(serviceMonitorGVK)
+	found, err := c.get(ctx, key, existing)
+	if meta.IsNoMatchError(err) {
+		log.FromContext(ctx).V(1).Info("ServiceMonitor CRD not installed; skipping ServiceMonitor")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetching ServiceMonitor: %w", err)
+	}
+	if !found {
+		if err := c.create(ctx, desired); err != nil {
+			return fmt.Errorf("creating ServiceMonitor: %w", err)
+		}
+		return nil
+	}
+	if !equality.Semantic.DeepEqual(existing.Object["spec"], desired.Object["spec"]) ||
+		!equality.Semantic.DeepEqual(existing.GetLabels(), desired.GetLabels()) {
+		existing.Object["spec"] = desired.Object["spec"]
+		existing.SetLabels(desired.GetLabels())
+		if err := c.update(ctx, existing); err != nil {
+			return fmt.Errorf("updating ServiceMonitor: %w", err)
+		}
+	}
+	return nil
+}
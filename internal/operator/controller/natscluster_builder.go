@@ -6,6 +6,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -63,10 +64,17 @@ func (b natsClusterBuilder) desiredService(nats *v1alpha1.NatsCluster) *corev1.S
 			},
 		},
 	}
+	if nats.Spec.Metrics != nil {
+		svc.Spec.Ports = append(svc.Spec.Ports, metricsServicePort(natsMetricsPort))
+	}
 	_ = controllerutil.SetControllerReference(nats, svc, b.scheme)
 	return svc
 }
 
+func (b natsClusterBuilder) desiredServiceMonitor(nats *v1alpha1.NatsCluster) *unstructured.Unstructured {
+	return desiredServiceMonitor(nats, b.scheme, nats.Spec.Metrics, labelsForNatsCluster(nats, b.instanceName))
+}
+
 func (b natsClusterBuilder) desiredDeployment(nats *v1alpha1.NatsCluster, cfgChecksum string) *appsv1.Deployment {
 	replicas := int32(1)
 
@@ -162,10 +170,26 @@ func (b natsClusterBuilder) desiredDeployment(nats *v1alpha1.NatsCluster, cfgChe
 			},
 		},
 	}
+	if nats.Spec.Metrics != nil {
+		deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers, natsExporterContainer(nats))
+	}
+	applyPodTemplate(&deploy.Spec.Template, "nats", nats.Spec.PodTemplate)
 	_ = controllerutil.SetControllerReference(nats, deploy, b.scheme)
 	return deploy
 }
 
+// natsExporterContainer runs prometheus-nats-exporter against the monitoring
+// endpoint of the server in the same pod.
+func natsExporterContainer(nats *v1alpha1.NatsCluster) corev1.Container {
+	container := metricsContainer(nats.Spec.Metrics, natsExporterImage, natsMetricsPort)
+	container.Args = []string{"-port", fmt.Sprint(natsMetricsPort), "-varz", "-connz", "-routez", "-subz"}
+	if nats.Spec.JetStream != nil {
+		container.Args = append(container.Args, "-jsz=all")
+	}
+	container.Args = append(container.Args, fmt.Sprintf("http://localhost:%d", natsconfig.MonitorPort))
+	return container
+}
+
 // desiredJetStreamPVC constructs the PVC for NATS JetStream storage.
 // Callers must only invoke this when nats.Spec.JetStream is non-nil.
 func (b natsClusterBuilder) desiredJetStreamPVC(nats *v1alpha1.NatsCluster) *corev1.PersistentVolumeClaim {
@@ -176,7 +200,8 @@ func (b natsClusterBuilder) desiredJetStreamPVC(nats *v1alpha1.NatsCluster) *cor
 			Labels:    labelsForNatsCluster(nats, b.instanceName),
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: storageClassName(nats.Spec.JetStream.StorageClassName),
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: corev1.ResourceList{
 					corev1.ResourceStorage: nats.Spec.JetStream.StorageSize.DeepCopy(),
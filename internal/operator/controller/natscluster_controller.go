@@ -45,6 +45,8 @@ type NatsClusterReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile handles create/update/delete events for NatsCluster resources.
 func (r *NatsClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -98,7 +100,12 @@ func (r *NatsClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		reconcileErr = err
 		result = r.setNatsClusterPhase(&nats, v1alpha1.NatsClusterPhaseFailed,
 			"ServiceReconcileFailed", err.Error())
-	} else if err := r.reconcileJetStreamPVC(ctx, &nats); err != nil {
+	} else if err := reconcileServiceMonitor(ctx, &r.client, client.ObjectKeyFromObject(&nats),
+		r.builder.desiredServiceMonitor(&nats)); err != nil {
+		reconcileErr = err
+		result = r.setNatsClusterPhase(&nats, v1alpha1.NatsClusterPhaseFailed,
+			"ServiceMonitorReconcileFailed", err.Error())
+	} else if resizing, err := r.reconcileJetStreamPVC(ctx, &nats); err != nil {
 		reconcileErr = err
 		result = r.setNatsClusterPhase(&nats, v1alpha1.NatsClusterPhaseFailed,
 			"PVCReconcileFailed", err.Error())
@@ -110,6 +117,11 @@ func (r *NatsClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				"DeploymentReconcileFailed", err.Error())
 		} else {
 			result = r.updateNatsPhaseFromDeployment(&nats, deploy)
+			// PVC status changes do not trigger a reconcile, so poll until an
+			// expansion completes.
+			if resizing && result.RequeueAfter == 0 {
+				result.RequeueAfter = 5 * time.Second
+			}
 		}
 	}
 
@@ -162,6 +174,10 @@ func (r *NatsClusterReconciler) reconcileDelete(ctx context.Context, nats *v1alp
 		return ctrl.Result{}, fmt.Errorf("deleting ConfigMap: %w", err)
 	}
 
+	if err := reconcileServiceMonitor(ctx, &r.client, client.ObjectKeyFromObject(nats), nil); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if nats.Spec.JetStream != nil {
 		pvc := &corev1.PersistentVolumeClaim{
 			ObjectMeta: metav1.ObjectMeta{Name: natsJetStreamPVCName(nats), Namespace: nats.Namespace},
@@ -271,25 +287,63 @@ func (r *NatsClusterReconciler) reconcileNatsService(ctx context.Context, nats *
 
 // reconcileJetStreamPVC ensures the JetStream PVC exists when JetStream is enabled.
 // It is a no-op when JetStream is not configured.
-func (r *NatsClusterReconciler) reconcileJetStreamPVC(ctx context.Context, nats *v1alpha1.NatsCluster) error {
+//
+// A larger storageSize is applied to the existing PVC in place when its
+// StorageClass allows volume expansion; otherwise the PVC keeps its size and
+// the StorageResizing condition says why. A PVC's StorageClass cannot change,
+// so a storageClassName that differs from the existing PVC's, as when
+// jetStream is removed and added back, is reported there too rather than
+// ignored. The returned bool reports whether an expansion is still in
+// progress.
+func (r *NatsClusterReconciler) reconcileJetStreamPVC(ctx context.Context, nats *v1alpha1.NatsCluster) (bool, error) {
 	if nats.Spec.JetStream == nil {
-		return nil
+		return false, nil
 	}
 
 	desired := r.builder.desiredJetStreamPVC(nats)
 	var existing corev1.PersistentVolumeClaim
 	found, err := r.client.get(ctx, client.ObjectKeyFromObject(desired), &existing)
 	if err != nil {
-		return fmt.Errorf("fetching JetStream PVC: %w", err)
+		return false, fmt.Errorf("fetching JetStream PVC: %w", err)
+	}
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return false, fmt.Errorf("creating JetStream PVC: %w", err)
+		}
+		return false, nil
 	}
-	if found {
-		return nil // already exists; PVC size changes require manual intervention
+
+	if class := nats.Spec.JetStream.StorageClassName; class != "" && class != derefString(existing.Spec.StorageClassName) {
+		setStorageResizeSkipped(&nats.Status.Conditions, nats.Generation, "StorageClassChangeNotSupported",
+			fmt.Sprintf("the JetStream volume keeps StorageClass %q; a volume's StorageClass cannot be changed",
+				derefString(existing.Spec.StorageClassName)))
+		return false, nil
 	}
 
-	if err := r.client.create(ctx, desired); err != nil {
-		return fmt.Errorf("creating JetStream PVC: %w", err)
+	claims := []client.ObjectKey{client.ObjectKeyFromObject(desired)}
+	currentSize := existing.Spec.Resources.Requests[corev1.ResourceStorage]
+	desiredSize := desired.Spec.Resources.Requests[corev1.ResourceStorage]
+	switch currentSize.Cmp(desiredSize) {
+	case -1:
+		expandable, err := volumeClaimsExpandable(ctx, &r.client, claims)
+		if err != nil {
+			return false, err
+		}
+		if !expandable {
+			setStorageResizeSkipped(&nats.Status.Conditions, nats.Generation, "ExpansionNotSupported",
+				"the StorageClass does not allow volume expansion; the JetStream volume keeps its current size")
+			return false, nil
+		}
+		if err := expandVolumeClaims(ctx, &r.client, claims, desiredSize); err != nil {
+			return false, err
+		}
+	case 1:
+		setStorageResizeSkipped(&nats.Status.Conditions, nats.Generation, "ShrinkNotSupported",
+			"volumes cannot shrink; the JetStream volume keeps its current size")
+		return false, nil
 	}
-	return nil
+
+	return reconcileStorageResizing(ctx, &r.client, &nats.Status.Conditions, nats.Generation, claims)
 }
 
 // reconcileNatsDeployment ensures the NATS Deployment exists and is up to date.
@@ -11,6 +11,8 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -252,6 +254,185 @@ var _ = Describe("NatsClusterReconciler", func() {
 		})
 	})
 
+	// ── Online storage expansion ─────────────────────────────────────────────
+	Context("when the JetStream storageSize grows on an expandable StorageClass", Ordered, func() {
+		var (
+			ns        *corev1.Namespace
+			sc        *storagev1.StorageClass
+			lookup    types.NamespacedName
+			pvcLookup types.NamespacedName
+			pvcUID    types.UID
+		)
+
+		BeforeAll(func() {
+			var nats *v1alpha1.NatsCluster
+			sc = newExpandableStorageClass()
+			ns, nats, lookup, _ = newTestNatsClusterResources("test-nats-js", "2.10")
+			nats.Spec.JetStream = &v1alpha1.NatsJetStreamConfig{
+				StorageSize:      resource.MustParse("256Mi"),
+				StorageClassName: sc.Name,
+			}
+			Expect(K8sClient.Create(Ctx, nats)).To(Succeed())
+			pvcLookup = types.NamespacedName{Name: nats.Name + "-jetstream", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.NatsCluster
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.NatsClusterPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+
+			var pvc corev1.PersistentVolumeClaim
+			Expect(K8sClient.Get(Ctx, pvcLookup, &pvc)).To(Succeed())
+			Expect(pvc.Spec.StorageClassName).To(HaveValue(Equal(sc.Name)))
+			pvcUID = pvc.UID
+
+			var latest v1alpha1.NatsCluster
+			Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+			latest.Spec.JetStream.StorageSize = resource.MustParse("512Mi")
+			Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+			_ = K8sClient.Delete(Ctx, sc)
+		})
+
+		It("should expand the existing PVC", func() {
+			Eventually(func(g Gomega) {
+				var pvc corev1.PersistentVolumeClaim
+				g.Expect(K8sClient.Get(Ctx, pvcLookup, &pvc)).To(Succeed())
+				g.Expect(pvc.UID).To(Equal(pvcUID))
+				storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(storage.Cmp(resource.MustParse("512Mi"))).To(Equal(0))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should report the expansion in the StorageResizing condition", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.NatsCluster
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				expectStorageResizing(g, fetched.Status.Conditions)
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should reject a change of storageClassName", func() {
+			var latest v1alpha1.NatsCluster
+			Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+			latest.Spec.JetStream.StorageClassName = ""
+			err := K8sClient.Update(Ctx, &latest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("storageClassName is immutable"))
+		})
+
+		It("should report a storageClassName that differs from the PVC's after jetStream is added back", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.NatsCluster
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.JetStream = nil
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.NatsCluster
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.JetStream = &v1alpha1.NatsJetStreamConfig{
+					StorageSize:      resource.MustParse("512Mi"),
+					StorageClassName: sc.Name + "-other",
+				}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.NatsCluster
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "StorageResizing")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("StorageClassChangeNotSupported"))
+			}, Timeout, Interval).Should(Succeed())
+
+			var pvc corev1.PersistentVolumeClaim
+			Expect(K8sClient.Get(Ctx, pvcLookup, &pvc)).To(Succeed())
+			Expect(pvc.UID).To(Equal(pvcUID))
+			Expect(pvc.Spec.StorageClassName).To(HaveValue(Equal(sc.Name)))
+		})
+	})
+
+	// ── Pod template overrides ───────────────────────────────────────────────
+	Context("when a podTemplate is set", Ordered, func() {
+		var (
+			ns     *corev1.Namespace
+			nats   *v1alpha1.NatsCluster
+			lookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, nats, lookup, _ = newTestNatsClusterResources("test-nats", "2.10")
+			nats.Spec.PodTemplate = newPodTemplateOverrides()
+			Expect(K8sClient.Create(Ctx, nats)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.NatsCluster
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.NatsClusterPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should run the nats pod with the overrides applied", func() {
+			var pods corev1.PodList
+			Expect(K8sClient.List(Ctx, &pods, client.InNamespace(ns.Name), client.MatchingLabels{
+				"app.kubernetes.io/instance": nats.Name,
+			})).To(Succeed())
+			Expect(pods.Items).NotTo(BeEmpty())
+			expectPodTemplateApplied(Default, &pods.Items[0], "nats", "nats")
+		})
+	})
+
+	// ── Metrics ──────────────────────────────────────────────────────────────
+	Context("when metrics are enabled", Ordered, func() {
+		var (
+			ns     *corev1.Namespace
+			nats   *v1alpha1.NatsCluster
+			lookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, nats, lookup, _ = newTestNatsClusterResources("test-nats", "2.10")
+			nats.Spec.Metrics = &v1alpha1.MetricsSpec{}
+			Expect(K8sClient.Create(Ctx, nats)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.NatsCluster
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.NatsClusterPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should expose a metrics port on the Service", func() {
+			var svc corev1.Service
+			Expect(K8sClient.Get(Ctx, lookup, &svc)).To(Succeed())
+			Expect(svc.Spec.Ports).To(ContainElement(HaveField("Name", "metrics")))
+		})
+
+		It("should serve the server's varz metrics from the exporter sidecar", func() {
+			var pods corev1.PodList
+			Expect(K8sClient.List(Ctx, &pods, client.InNamespace(ns.Name), client.MatchingLabels{
+				"app.kubernetes.io/instance": nats.Name,
+			})).To(Succeed())
+			Expect(pods.Items).NotTo(BeEmpty())
+
+			Eventually(func(g Gomega) {
+				g.Expect(ScrapeMetrics(ns.Name, pods.Items[0].Name, 7777)).To(ContainSubstring("gnatsd_varz_connections"))
+			}, Timeout, 2*time.Second).Should(Succeed())
+		})
+	})
+
 	// ── Instance label filtering ─────────────────────────────────────────────
 	Context("when a NatsCluster has no operator-instance label", Ordered, func() {
 		var (
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+// applyPodTemplate merges a CR's podTemplate overrides into the pod template
+// built by the operator. Operator labels and annotations win over user ones so
+// selectors and config checksums keep working; resources apply only to the
+// named database container, leaving any sidecars alone.
+func applyPodTemplate(tmpl *corev1.PodTemplateSpec, container string, overrides *v1alpha1.PodTemplateOverrides) {
+	if overrides == nil {
+		return
+	}
+
+	tmpl.Labels = mergeOperatorKeys(tmpl.Labels, overrides.Labels)
+	tmpl.Annotations = mergeOperatorKeys(tmpl.Annotations, overrides.Annotations)
+
+	if overrides.Resources != nil {
+		for i := range tmpl.Spec.Containers {
+			if tmpl.Spec.Containers[i].Name == container {
+				tmpl.Spec.Containers[i].Resources = *overrides.Resources.DeepCopy()
+			}
+		}
+	}
+
+	applyPodPlacement(&tmpl.Spec, overrides)
+	if overrides.Affinity != nil {
+		tmpl.Spec.Affinity = overrides.Affinity.DeepCopy()
+	}
+	tmpl.Spec.PriorityClassName = overrides.PriorityClassName
+}
+
+// applyPodPlacement copies the node selector and tolerations from overrides
+// onto spec. Short-lived Job pods that work on a database, such as backups,
+// take only these, so they can reach the same tainted or dedicated nodes
+// without inheriting the database pods' affinity rules.
+func applyPodPlacement(spec *corev1.PodSpec, overrides *v1alpha1.PodTemplateOverrides) {
+	if overrides == nil {
+		return
+	}
+	if len(overrides.NodeSelector) > 0 {
+		spec.NodeSelector = maps.Clone(overrides.NodeSelector)
+	}
+	if len(overrides.Tolerations) > 0 {
+		spec.Tolerations = make([]corev1.Toleration, len(overrides.Tolerations))
+		for i := range overrides.Tolerations {
+			overrides.Tolerations[i].DeepCopyInto(&spec.Tolerations[i])
+		}
+	}
+}
+
+// mergeOperatorKeys returns user entries overlaid with the operator's own, so
+// the operator's values win on conflicting keys.
+func mergeOperatorKeys(operator, user map[string]string) map[string]string {
+	if len(user) == 0 {
+		return operator
+	}
+	merged := maps.Clone(user)
+	maps.Copy(merged, operator)
+	return merged
+}
@@ -5,7 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -15,6 +19,8 @@ import (
 	// Pure Go Postgres driver.
 	"github.com/lib/pq"
 
+	"github.com/benjamin-wright/db-operator/internal/pgconfig"
+	"github.com/benjamin-wright/db-operator/internal/pgconn"
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
 
@@ -66,17 +72,100 @@ func (c *postgresCredentialClient) list(ctx context.Context, obj client.ObjectLi
 // PostgresManager — external Postgres dependency interface
 // ────────────────────────────────────────────────────────────────────────────
 
+// PostgresConn identifies a Postgres instance and the credentials used to
+// reach it.
+type PostgresConn struct {
+	Host     string
+	User     string
+	Password string
+	// CACert is the PEM bundle that signs the server certificate. When set,
+	// connections use TLS and verify the server against it; when empty, they
+	// do not use TLS.
+	CACert string
+}
+
+// at returns a copy of c that connects to host instead.
+func (c PostgresConn) at(host string) PostgresConn {
+	c.Host = host
+	return c
+}
+
+// withPassword returns a copy of c that authenticates with password instead.
+func (c PostgresConn) withPassword(password string) PostgresConn {
+	c.Password = password
+	return c
+}
+
+// LogicalDatabase describes a logical database as ListDatabases reports it.
+type LogicalDatabase struct {
+	// Size is the size of the database in bytes.
+	Size int64
+	// Created reports whether EnsureDatabase created the database, as
+	// recorded by the comment it sets on databases it creates.
+	Created bool
+}
+
+// createdDatabaseComment marks the databases EnsureDatabase creates, so
+// databases created by anyone else are never dropped as unreferenced.
+const createdDatabaseComment = "created by db-operator"
+
 // PostgresManager abstracts direct Postgres interactions so the reconciler can
 // be tested without a live database.
 type PostgresManager interface {
-	EnsureDatabase(host, adminUser, adminPass, dbName string) error
-	EnsureUser(host, adminUser, adminPass, dbName, username, password string, permissions []v1alpha1.DatabasePermission, tables []string) error
-	DropUser(host, adminUser, adminPass, dbName, username string) error
+	EnsureDatabase(conn PostgresConn, dbName string) error
+	// EnsureExtensions installs extensions in dbName, updating any pinned to
+	// another version, and returns every extension installed there.
+	EnsureExtensions(conn PostgresConn, dbName string, extensions []v1alpha1.PostgresExtension) ([]v1alpha1.PostgresExtensionStatus, error)
+	EnsureUser(conn PostgresConn, dbName, username, password string, entry v1alpha1.DatabasePermissionEntry) error
+	DropUser(conn PostgresConn, dbName, username string) error
+	// SetPassword replaces the password of username. An empty password removes
+	// it, so the role can no longer log in with one.
+	SetPassword(conn PostgresConn, username, password string) error
+	// EnsureAlias creates alias as a login role that is a member of username
+	// and switches to it on connect, so sessions opened as alias act as username.
+	EnsureAlias(conn PostgresConn, alias, username string) error
 	// EnsureOwner makes username the owner of dbName and grants it full schema access.
-	EnsureOwner(host, adminUser, adminPass, dbName, username string) error
+	EnsureOwner(conn PostgresConn, dbName, username string) error
 	// FindOwner returns the current PostgreSQL owner role of dbName, or an empty
 	// string if the database does not exist.
-	FindOwner(host, adminUser, adminPass, dbName string) (string, error)
+	FindOwner(conn PostgresConn, dbName string) (string, error)
+	// ListDatabases describes every connectable logical database apart from
+	// postgres and templates, by name.
+	ListDatabases(conn PostgresConn) (map[string]LogicalDatabase, error)
+	// DropDatabase drops dbName, ending any sessions connected to it.
+	DropDatabase(conn PostgresConn, dbName string) error
+	// WALPosition returns how far the instance at conn has written WAL (on a
+	// primary) or received it (on a standby), as a byte offset comparable
+	// across instances of the same cluster.
+	WALPosition(conn PostgresConn) (int64, error)
+	// Promote ends recovery on the standby at conn, making it a primary. It is
+	// a no-op when conn reaches a primary already.
+	Promote(conn PostgresConn) error
+	// ConfigRevision returns the revision of the operator-rendered
+	// postgresql.conf that the instance at conn has loaded, or "" if none.
+	ConfigRevision(conn PostgresConn) (string, error)
+	// ReloadConfig asks the instance at conn to re-read its configuration files.
+	ReloadConfig(conn PostgresConn) error
+	// InvalidParameters lists configuration file parameters the instance at
+	// conn could not apply, excluding those only waiting for a restart.
+	InvalidParameters(conn PostgresConn) ([]string, error)
+	// SetPrimaryConnPassword replaces the password the standby at conn uses to
+	// stream from the primary. It is a no-op on a primary.
+	SetPrimaryConnPassword(conn PostgresConn, password string) error
+	// SetRoleAttributes applies attrs to username, if the role exists.
+	SetRoleAttributes(conn PostgresConn, username string, attrs RoleAttributes) error
+	// EnsureMonitoringRole creates roleName as a login role without a
+	// password and makes it a member of pg_monitor.
+	EnsureMonitoringRole(conn PostgresConn, roleName string) error
+	// EnsureGroupRole creates roleName as a role that cannot log in and grants
+	// it the privileges of entry in dbName.
+	EnsureGroupRole(conn PostgresConn, dbName, roleName string, entry v1alpha1.DatabasePermissionEntry) error
+	// SetMemberships makes username a member of exactly the listed roles.
+	SetMemberships(conn PostgresConn, username string, roles []string) ([]string, error)
+	// RevokeUndeclared revokes every privilege granted directly to username,
+	// in any database of the instance, that entries do not declare. It returns
+	// a description of each privilege it revoked.
+	RevokeUndeclared(conn PostgresConn, username string, entries []v1alpha1.DatabasePermissionEntry) ([]string, error)
 }
 
 // postgresManager is the production implementation of PostgresManager.
@@ -93,13 +182,16 @@ var validPermissions = map[v1alpha1.DatabasePermission]struct{}{
 	v1alpha1.PermissionTruncate:   {},
 	v1alpha1.PermissionReferences: {},
 	v1alpha1.PermissionTrigger:    {},
+	v1alpha1.PermissionUsage:      {},
+	v1alpha1.PermissionCreate:     {},
+	v1alpha1.PermissionExecute:    {},
 	v1alpha1.PermissionAll:        {},
 }
 
 // EnsureDatabase connects to the maintenance database and creates the specified
 // logical database if it does not already exist.
-func (p postgresManager) EnsureDatabase(host, adminUser, adminPass, dbName string) error {
-	db, err := openPostgres(host, adminUser, adminPass, "postgres")
+func (p postgresManager) EnsureDatabase(conn PostgresConn, dbName string) error {
+	db, err := openPostgres(conn, "postgres")
 	if err != nil {
 		return fmt.Errorf("connecting to Postgres: %w", err)
 	}
@@ -115,18 +207,90 @@ func (p postgresManager) EnsureDatabase(host, adminUser, adminPass, dbName strin
 		if _, err := db.Exec(createSQL); err != nil {
 			return fmt.Errorf("creating database %q: %w", dbName, err)
 		}
+		commentSQL := fmt.Sprintf("COMMENT ON DATABASE %s IS %s",
+			pq.QuoteIdentifier(dbName), pq.QuoteLiteral(createdDatabaseComment))
+		if _, err := db.Exec(commentSQL); err != nil {
+			return fmt.Errorf("marking database %q as created by the operator: %w", dbName, err)
+		}
 	}
 
 	return nil
 }
 
+// EnsureExtensions connects to dbName and runs CREATE EXTENSION for each
+// extension that is not installed, with CASCADE so its dependencies are
+// installed too. A pinned extension installed at another version is updated
+// with ALTER EXTENSION ... UPDATE.
+func (p postgresManager) EnsureExtensions(conn PostgresConn, dbName string, extensions []v1alpha1.PostgresExtension) ([]v1alpha1.PostgresExtensionStatus, error) {
+	db, err := openPostgres(conn, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	installed, err := installedExtensions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ext := range extensions {
+		quotedName := pq.QuoteIdentifier(ext.Name)
+		current, ok := installed[ext.Name]
+		switch {
+		case !ok:
+			createSQL := fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s", quotedName)
+			if ext.Version != "" {
+				createSQL += " VERSION " + pq.QuoteLiteral(ext.Version)
+			}
+			if _, err := db.Exec(createSQL + " CASCADE"); err != nil {
+				return nil, fmt.Errorf("creating extension %q in database %q: %w", ext.Name, dbName, err)
+			}
+		case ext.Version != "" && ext.Version != current:
+			updateSQL := fmt.Sprintf("ALTER EXTENSION %s UPDATE TO %s", quotedName, pq.QuoteLiteral(ext.Version))
+			if _, err := db.Exec(updateSQL); err != nil {
+				return nil, fmt.Errorf("updating extension %q in database %q: %w", ext.Name, dbName, err)
+			}
+		}
+	}
+
+	if installed, err = installedExtensions(db); err != nil {
+		return nil, err
+	}
+	statuses := make([]v1alpha1.PostgresExtensionStatus, 0, len(installed))
+	for _, name := range slices.Sorted(maps.Keys(installed)) {
+		statuses = append(statuses, v1alpha1.PostgresExtensionStatus{Name: name, Version: installed[name]})
+	}
+	return statuses, nil
+}
+
+// installedExtensions returns the version of each extension installed in the
+// database db is connected to, by name.
+func installedExtensions(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query("SELECT extname, extversion FROM pg_extension")
+	if err != nil {
+		return nil, fmt.Errorf("listing extensions: %w", err)
+	}
+	defer rows.Close()
+
+	installed := map[string]string{}
+	for rows.Next() {
+		var name, version string
+		if err := rows.Scan(&name, &version); err != nil {
+			return nil, fmt.Errorf("reading extension: %w", err)
+		}
+		installed[name] = version
+	}
+	return installed, rows.Err()
+}
+
 // EnsureUser connects to the target Postgres instance and creates the specified role
-// with the given password and permissions if it does not already exist.
-// When tables is non-empty, privileges are granted only on those specific tables;
-// no ALTER DEFAULT PRIVILEGES is emitted in that case because PostgreSQL has no
-// mechanism to pre-grant future tables by name.
-func (p postgresManager) EnsureUser(host, adminUser, adminPass, dbName, username, password string, permissions []v1alpha1.DatabasePermission, tables []string) error {
-	db, err := openPostgres(host, adminUser, adminPass, dbName)
+// with the given password if it does not already exist, then grants it the
+// privileges of entry in each of the entry's schemas.
+// When entry names specific tables, sequences or functions, privileges are
+// granted only on those objects; no ALTER DEFAULT PRIVILEGES is emitted in that
+// case because PostgreSQL has no mechanism to pre-grant future objects by name.
+func (p postgresManager) EnsureUser(conn PostgresConn, dbName, username, password string, entry v1alpha1.DatabasePermissionEntry) error {
+	db, err := openPostgres(conn, dbName)
 	if err != nil {
 		return fmt.Errorf("connecting to Postgres: %w", err)
 	}
@@ -145,84 +309,151 @@ func (p postgresManager) EnsureUser(host, adminUser, adminPass, dbName, username
 		}
 	}
 
-	if len(permissions) > 0 {
-		privs := make([]string, len(permissions))
-		for i, p := range permissions {
-			if _, ok := validPermissions[p]; !ok {
-				return fmt.Errorf("unknown permission %q", p)
+	return grantPrivileges(db, dbName, username, entry)
+}
+
+// EnsureGroupRole creates roleName as a role that cannot log in if it does
+// not already exist, then grants it the privileges of entry like EnsureUser.
+func (p postgresManager) EnsureGroupRole(conn PostgresConn, dbName, roleName string, entry v1alpha1.DatabasePermissionEntry) error {
+	db, err := openPostgres(conn, dbName)
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname = $1)", roleName).Scan(&exists); err != nil {
+		return fmt.Errorf("checking if role exists: %w", err)
+	}
+
+	if !exists {
+		if _, err := db.Exec(fmt.Sprintf("CREATE ROLE %s WITH NOLOGIN", pq.QuoteIdentifier(roleName))); err != nil {
+			return fmt.Errorf("creating role %q: %w", roleName, err)
+		}
+	}
+
+	return grantPrivileges(db, dbName, roleName, entry)
+}
+
+// EnsureMonitoringRole creates roleName if it does not already exist and
+// grants it pg_monitor, which reads every statistics view without any access
+// to data. The role has no password, so it can only log in where pg_hba.conf
+// trusts the connection.
+func (p postgresManager) EnsureMonitoringRole(conn PostgresConn, roleName string) error {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname = $1)", roleName).Scan(&exists); err != nil {
+		return fmt.Errorf("checking if role exists: %w", err)
+	}
+
+	quotedRole := pq.QuoteIdentifier(roleName)
+	if !exists {
+		if _, err := db.Exec(fmt.Sprintf("CREATE ROLE %s WITH LOGIN", quotedRole)); err != nil {
+			return fmt.Errorf("creating role %q: %w", roleName, err)
+		}
+	}
+	if _, err := db.Exec(fmt.Sprintf("GRANT pg_monitor TO %s", quotedRole)); err != nil {
+		return fmt.Errorf("granting pg_monitor to %q: %w", roleName, err)
+	}
+	return nil
+}
+
+// grantPrivileges grants username the privileges of entry in each of the
+// entry's schemas, on the database db is connected to.
+func grantPrivileges(db *sql.DB, dbName, username string, entry v1alpha1.DatabasePermissionEntry) error {
+	if len(entry.Permissions) == 0 {
+		return nil
+	}
+	for _, p := range entry.Permissions {
+		if _, ok := validPermissions[p]; !ok {
+			return fmt.Errorf("unknown permission %q", p)
+		}
+	}
+	quotedUser := pq.QuoteIdentifier(username)
+
+	schemas := entry.Schemas
+	if len(schemas) == 0 {
+		schemas = []string{"public"}
+	}
+	named := len(entry.Tables) > 0 || len(entry.Sequences) > 0 || len(entry.Functions) > 0
+
+	// Propagate default privileges for the current database owner so that objects
+	// created by the owner after this grant are auto-granted to this user.
+	var owner string
+	if !named {
+		if err := db.QueryRow(
+			"SELECT pg_catalog.pg_get_userbyid(datdba) FROM pg_database WHERE datname = $1", dbName,
+		).Scan(&owner); err != nil {
+			return fmt.Errorf("looking up owner of database %q: %w", dbName, err)
+		}
+		if owner == username {
+			owner = ""
+		}
+	}
+
+	for _, schema := range schemas {
+		if schema == "" {
+			return errors.New("schemas entry is an empty string")
+		}
+		quotedSchema := pq.QuoteIdentifier(schema)
+
+		if privClause := privilegeClause(entry.Permissions, schemaPrivileges); privClause != "" {
+			grantSQL := fmt.Sprintf("GRANT %s ON SCHEMA %s TO %s", privClause, quotedSchema, quotedUser)
+			if _, err := db.Exec(grantSQL); err != nil {
+				return fmt.Errorf("granting schema permissions to %q: %w", username, err)
+			}
+		}
+
+		for _, kind := range grantObjectKinds {
+			privClause := privilegeClause(entry.Permissions, kind.privileges)
+			if privClause == "" {
+				continue
 			}
-			privs[i] = string(p)
-		}
-		privClause := strings.Join(privs, ", ")
-		quotedUser := pq.QuoteIdentifier(username)
-
-		if len(tables) > 0 {
-			// Table-scoped grant: privileges apply only to the named tables.
-			// No ALTER DEFAULT PRIVILEGES is emitted — PostgreSQL cannot pre-grant
-			// on specific future tables by name.
-			quotedTables := make([]string, len(tables))
-			for i, t := range tables {
-				if t == "" {
-					return fmt.Errorf("tables entry %d is an empty string", i)
+
+			if named {
+				// Object-scoped grant: privileges apply only to the named objects.
+				names := kind.names(entry)
+				if len(names) == 0 {
+					continue
 				}
-				quotedTables[i] = pq.QuoteIdentifier(t)
-			}
-			grantSQL := fmt.Sprintf("GRANT %s ON TABLE %s TO %s",
-				privClause, strings.Join(quotedTables, ", "), quotedUser)
-			if _, err := db.Exec(grantSQL); err != nil {
-				return fmt.Errorf("granting table-scoped permissions to %q: %w", username, err)
+				quotedNames := make([]string, len(names))
+				for i, name := range names {
+					if name == "" {
+						return fmt.Errorf("%s entry %d is an empty string", kind.plural, i)
+					}
+					quotedNames[i] = quotedSchema + "." + pq.QuoteIdentifier(name)
+				}
+				grantSQL := fmt.Sprintf("GRANT %s ON %s %s TO %s",
+					privClause, kind.keyword, strings.Join(quotedNames, ", "), quotedUser)
+				if _, err := db.Exec(grantSQL); err != nil {
+					return fmt.Errorf("granting %s-scoped permissions to %q: %w", kind.plural, username, err)
+				}
+				continue
 			}
-		} else {
-			// All-tables grant: privileges apply to every current and future table.
-			grantSQL := fmt.Sprintf("GRANT %s ON ALL TABLES IN SCHEMA public TO %s", privClause, quotedUser)
+
+			// Schema-wide grant: privileges apply to every current and future object.
+			grantSQL := fmt.Sprintf("GRANT %s ON ALL %s IN SCHEMA %s TO %s",
+				privClause, kind.keywordPlural, quotedSchema, quotedUser)
 			if _, err := db.Exec(grantSQL); err != nil {
-				return fmt.Errorf("granting permissions to %q: %w", username, err)
+				return fmt.Errorf("granting %s permissions to %q: %w", kind.plural, username, err)
 			}
 
-			defaultSQL := fmt.Sprintf("ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT %s ON TABLES TO %s", privClause, quotedUser)
+			defaultSQL := fmt.Sprintf("ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT %s ON %s TO %s",
+				quotedSchema, privClause, kind.keywordPlural, quotedUser)
 			if _, err := db.Exec(defaultSQL); err != nil {
-				return fmt.Errorf("setting default privileges for %q: %w", username, err)
+				return fmt.Errorf("setting default %s privileges for %q: %w", kind.plural, username, err)
 			}
 
-			// Propagate default privileges for the current database owner so that tables
-			// and sequences created by the owner after this grant are auto-granted to this user.
-			var owner string
-			if err := db.QueryRow(
-				"SELECT pg_catalog.pg_get_userbyid(datdba) FROM pg_database WHERE datname = $1", dbName,
-			).Scan(&owner); err != nil {
-				return fmt.Errorf("looking up owner of database %q: %w", dbName, err)
-			}
-			if owner != "" && owner != username {
-				quotedOwner := pq.QuoteIdentifier(owner)
-				ownerTableSQL := fmt.Sprintf(
-					"ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA public GRANT %s ON TABLES TO %s",
-					quotedOwner, privClause, quotedUser)
-				if _, err := db.Exec(ownerTableSQL); err != nil {
-					return fmt.Errorf("setting owner-scoped default table privileges for %q: %w", username, err)
-				}
-
-				// Sequences only accept SELECT, UPDATE, and ALL — filter out table-only
-				// privileges (INSERT, DELETE, TRUNCATE, REFERENCES, TRIGGER) before
-				// building the sequences grant.
-				validSeqPerms := map[v1alpha1.DatabasePermission]bool{
-					v1alpha1.PermissionSelect: true,
-					v1alpha1.PermissionUpdate: true,
-					v1alpha1.PermissionAll:    true,
-				}
-				var seqPrivs []string
-				for _, perm := range permissions {
-					if validSeqPerms[perm] {
-						seqPrivs = append(seqPrivs, string(perm))
-					}
-				}
-				if len(seqPrivs) > 0 {
-					seqPrivClause := strings.Join(seqPrivs, ", ")
-					ownerSeqSQL := fmt.Sprintf(
-						"ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA public GRANT %s ON SEQUENCES TO %s",
-						quotedOwner, seqPrivClause, quotedUser)
-					if _, err := db.Exec(ownerSeqSQL); err != nil {
-						return fmt.Errorf("setting owner-scoped default sequence privileges for %q: %w - %+v", username, err, seqPrivClause)
-					}
+			if owner != "" {
+				ownerSQL := fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA %s GRANT %s ON %s TO %s",
+					pq.QuoteIdentifier(owner), quotedSchema, privClause, kind.keywordPlural, quotedUser)
+				if _, err := db.Exec(ownerSQL); err != nil {
+					return fmt.Errorf("setting owner-scoped default %s privileges for %q: %w", kind.plural, username, err)
 				}
 			}
 		}
@@ -231,13 +462,89 @@ func (p postgresManager) EnsureUser(host, adminUser, adminPass, dbName, username
 	return nil
 }
 
+// grantObjectKind describes a kind of schema object that EnsureUser grants on.
+type grantObjectKind struct {
+	// keyword and keywordPlural name the object kind in GRANT and ALTER DEFAULT
+	// PRIVILEGES statements.
+	keyword       string
+	keywordPlural string
+	// plural names the object kind in error messages.
+	plural string
+	// privileges is the subset of permissions PostgreSQL accepts on the kind.
+	privileges map[v1alpha1.DatabasePermission]bool
+	// names returns the objects of this kind an entry is limited to.
+	names func(entry v1alpha1.DatabasePermissionEntry) []string
+}
+
+// schemaPrivileges is the subset of permissions granted on the schemas
+// themselves. ALL is left out so that it keeps meaning every privilege on the
+// objects in a schema rather than also allowing new ones to be created there.
+var schemaPrivileges = map[v1alpha1.DatabasePermission]bool{
+	v1alpha1.PermissionUsage:  true,
+	v1alpha1.PermissionCreate: true,
+}
+
+// grantObjectKinds lists the object kinds EnsureUser grants on, in order.
+var grantObjectKinds = []grantObjectKind{
+	{
+		keyword:       "TABLE",
+		keywordPlural: "TABLES",
+		plural:        "tables",
+		privileges: map[v1alpha1.DatabasePermission]bool{
+			v1alpha1.PermissionSelect:     true,
+			v1alpha1.PermissionInsert:     true,
+			v1alpha1.PermissionUpdate:     true,
+			v1alpha1.PermissionDelete:     true,
+			v1alpha1.PermissionTruncate:   true,
+			v1alpha1.PermissionReferences: true,
+			v1alpha1.PermissionTrigger:    true,
+			v1alpha1.PermissionAll:        true,
+		},
+		names: func(entry v1alpha1.DatabasePermissionEntry) []string { return entry.Tables },
+	},
+	{
+		keyword:       "SEQUENCE",
+		keywordPlural: "SEQUENCES",
+		plural:        "sequences",
+		privileges: map[v1alpha1.DatabasePermission]bool{
+			v1alpha1.PermissionUsage:  true,
+			v1alpha1.PermissionSelect: true,
+			v1alpha1.PermissionUpdate: true,
+			v1alpha1.PermissionAll:    true,
+		},
+		names: func(entry v1alpha1.DatabasePermissionEntry) []string { return entry.Sequences },
+	},
+	{
+		keyword:       "FUNCTION",
+		keywordPlural: "FUNCTIONS",
+		plural:        "functions",
+		privileges: map[v1alpha1.DatabasePermission]bool{
+			v1alpha1.PermissionExecute: true,
+			v1alpha1.PermissionAll:     true,
+		},
+		names: func(entry v1alpha1.DatabasePermissionEntry) []string { return entry.Functions },
+	},
+}
+
+// privilegeClause joins the permissions that accepted allows into the
+// privilege list of a GRANT statement, or returns "" if there are none.
+func privilegeClause(permissions []v1alpha1.DatabasePermission, accepted map[v1alpha1.DatabasePermission]bool) string {
+	var privs []string
+	for _, p := range permissions {
+		if accepted[p] {
+			privs = append(privs, string(p))
+		}
+	}
+	return strings.Join(privs, ", ")
+}
+
 // EnsureOwner makes username the OWNER of dbName and grants it full access on the
 // public schema. It connects to the maintenance database for the ALTER DATABASE
 // statement (which cannot run inside the target database), then connects to the
 // target database to set schema-level grants.
-func (p postgresManager) EnsureOwner(host, adminUser, adminPass, dbName, username string) error {
+func (p postgresManager) EnsureOwner(conn PostgresConn, dbName, username string) error {
 	// ALTER DATABASE … OWNER TO must run outside the target database.
-	maintenanceDB, err := openPostgres(host, adminUser, adminPass, "postgres")
+	maintenanceDB, err := openPostgres(conn, "postgres")
 	if err != nil {
 		return fmt.Errorf("connecting to maintenance database: %w", err)
 	}
@@ -250,7 +557,7 @@ func (p postgresManager) EnsureOwner(host, adminUser, adminPass, dbName, usernam
 	}
 
 	// Schema-level grants must run inside the target database.
-	db, err := openPostgres(host, adminUser, adminPass, dbName)
+	db, err := openPostgres(conn, dbName)
 	if err != nil {
 		return fmt.Errorf("connecting to database %q: %w", dbName, err)
 	}
@@ -272,8 +579,8 @@ func (p postgresManager) EnsureOwner(host, adminUser, adminPass, dbName, usernam
 
 // FindOwner returns the current PostgreSQL owner of dbName, or an empty string
 // if the database does not exist.
-func (p postgresManager) FindOwner(host, adminUser, adminPass, dbName string) (string, error) {
-	db, err := openPostgres(host, adminUser, adminPass, "postgres")
+func (p postgresManager) FindOwner(conn PostgresConn, dbName string) (string, error) {
+	db, err := openPostgres(conn, "postgres")
 	if err != nil {
 		return "", fmt.Errorf("connecting to Postgres: %w", err)
 	}
@@ -292,6 +599,587 @@ func (p postgresManager) FindOwner(host, adminUser, adminPass, dbName string) (s
 	return owner, nil
 }
 
+// ListDatabases queries pg_database on the maintenance database.
+func (p postgresManager) ListDatabases(conn PostgresConn) (map[string]LogicalDatabase, error) {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT datname, pg_catalog.pg_database_size(oid),
+		pg_catalog.shobj_description(oid, 'pg_database') IS NOT DISTINCT FROM $1
+		FROM pg_database WHERE datallowconn AND NOT datistemplate AND datname <> 'postgres'`,
+		createdDatabaseComment)
+	if err != nil {
+		return nil, fmt.Errorf("listing databases: %w", err)
+	}
+	defer rows.Close()
+
+	databases := map[string]LogicalDatabase{}
+	for rows.Next() {
+		var name string
+		var logical LogicalDatabase
+		if err := rows.Scan(&name, &logical.Size, &logical.Created); err != nil {
+			return nil, fmt.Errorf("reading database: %w", err)
+		}
+		databases[name] = logical
+	}
+	return databases, rows.Err()
+}
+
+// DropDatabase connects to the maintenance database and drops dbName if it
+// exists. WITH (FORCE) terminates the sessions still connected to it.
+func (p postgresManager) DropDatabase(conn PostgresConn, dbName string) error {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	dropSQL := fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", pq.QuoteIdentifier(dbName))
+	if _, err := db.Exec(dropSQL); err != nil {
+		return fmt.Errorf("dropping database %q: %w", dbName, err)
+	}
+	return nil
+}
+
+// WALPosition returns the WAL byte offset of the instance at conn. Standbys
+// report the furthest of received and replayed WAL, so the standby that has
+// seen the most of the primary's history reports the largest value.
+func (p postgresManager) WALPosition(conn PostgresConn) (int64, error) {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return 0, fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	var position int64
+	err = db.QueryRow(`SELECT (CASE WHEN pg_is_in_recovery()
+		THEN GREATEST(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn(), '0/0'::pg_lsn)
+		ELSE pg_current_wal_lsn() END - '0/0'::pg_lsn)::bigint`).Scan(&position)
+	if err != nil {
+		return 0, fmt.Errorf("querying WAL position: %w", err)
+	}
+	return position, nil
+}
+
+// Promote ends recovery on the standby at conn and waits for it to accept writes.
+func (p postgresManager) Promote(conn PostgresConn) error {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	var inRecovery bool
+	if err := db.QueryRow("SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return fmt.Errorf("checking recovery state: %w", err)
+	}
+	if !inRecovery {
+		return nil
+	}
+
+	var promoted bool
+	if err := db.QueryRow("SELECT pg_promote(true, 60)").Scan(&promoted); err != nil {
+		return fmt.Errorf("promoting standby: %w", err)
+	}
+	if !promoted {
+		return errors.New("standby did not finish promotion within 60 seconds")
+	}
+	return nil
+}
+
+// ConfigRevision reads the revision recorded in pgconfig.ChecksumSetting. The
+// setting is absent on an instance that has not loaded a rendered config.
+func (p postgresManager) ConfigRevision(conn PostgresConn) (string, error) {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return "", fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	var revision sql.NullString
+	if err := db.QueryRow("SELECT current_setting($1, true)", pgconfig.ChecksumSetting).Scan(&revision); err != nil {
+		return "", fmt.Errorf("reading config revision: %w", err)
+	}
+	return revision.String, nil
+}
+
+// ReloadConfig signals the postmaster at conn to reload its configuration.
+func (p postgresManager) ReloadConfig(conn PostgresConn) error {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("SELECT pg_reload_conf()"); err != nil {
+		return fmt.Errorf("reloading configuration: %w", err)
+	}
+	return nil
+}
+
+// InvalidParameters returns the names of settings that pg_file_settings reports
+// as not applied. Restart-only settings changed since startup are reported the
+// same way, so those marked pending_restart in pg_settings are left out.
+func (p postgresManager) InvalidParameters(conn PostgresConn) ([]string, error) {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT DISTINCT f.name FROM pg_file_settings f
+		WHERE f.error IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM pg_settings s WHERE s.name = f.name AND s.pending_restart)
+		ORDER BY f.name`)
+	if err != nil {
+		return nil, fmt.Errorf("querying file settings: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scanning file setting: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// SetPassword runs ALTER ROLE from the maintenance database. Sessions already
+// open under the role are not affected.
+func (p postgresManager) SetPassword(conn PostgresConn, username, password string) error {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	passwordClause := "NULL"
+	if password != "" {
+		passwordClause = pq.QuoteLiteral(password)
+	}
+	alterSQL := fmt.Sprintf("ALTER ROLE %s WITH PASSWORD %s", pq.QuoteIdentifier(username), passwordClause)
+	if _, err := db.Exec(alterSQL); err != nil {
+		return fmt.Errorf("setting password of role %q: %w", username, err)
+	}
+	return nil
+}
+
+// EnsureAlias creates alias without a password if it does not already exist.
+// Membership gives it every privilege granted to username, and the per-role
+// "role" setting makes objects it creates belong to username.
+func (p postgresManager) EnsureAlias(conn PostgresConn, alias, username string) error {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname = $1)", alias).Scan(&exists); err != nil {
+		return fmt.Errorf("checking if role exists: %w", err)
+	}
+
+	quotedAlias := pq.QuoteIdentifier(alias)
+	quotedUser := pq.QuoteIdentifier(username)
+	if !exists {
+		createSQL := fmt.Sprintf("CREATE ROLE %s WITH LOGIN IN ROLE %s", quotedAlias, quotedUser)
+		if _, err := db.Exec(createSQL); err != nil {
+			return fmt.Errorf("creating role %q: %w", alias, err)
+		}
+	}
+	if _, err := db.Exec(fmt.Sprintf("ALTER ROLE %s SET role = %s", quotedAlias, quotedUser)); err != nil {
+		return fmt.Errorf("setting session role of %q: %w", alias, err)
+	}
+	return nil
+}
+
+// primaryConnPasswordPattern matches the password option of a libpq connection
+// string, whether its value is quoted or not.
+var primaryConnPasswordPattern = regexp.MustCompile(`password=('(?:[^'\\]|\\.)*'|\S*)`)
+
+// SetPrimaryConnPassword rewrites primary_conninfo with ALTER SYSTEM and
+// reloads the configuration, which makes the WAL receiver reconnect with the
+// new password. The password is written unquoted, as generatePassword only
+// produces alphanumerics, so postgresStartScript can replace it the same way.
+func (p postgresManager) SetPrimaryConnPassword(conn PostgresConn, password string) error {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	var inRecovery bool
+	if err := db.QueryRow("SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return fmt.Errorf("checking recovery state: %w", err)
+	}
+	if !inRecovery {
+		return nil
+	}
+
+	var conninfo string
+	if err := db.QueryRow("SELECT current_setting('primary_conninfo')").Scan(&conninfo); err != nil {
+		return fmt.Errorf("reading primary_conninfo: %w", err)
+	}
+	updated := primaryConnPasswordPattern.ReplaceAllLiteralString(conninfo, "password="+password)
+	if updated == conninfo {
+		return nil
+	}
+	if _, err := db.Exec("ALTER SYSTEM SET primary_conninfo = " + pq.QuoteLiteral(updated)); err != nil {
+		return fmt.Errorf("updating primary_conninfo: %w", err)
+	}
+	if _, err := db.Exec("SELECT pg_reload_conf()"); err != nil {
+		return fmt.Errorf("reloading configuration: %w", err)
+	}
+	return nil
+}
+
+// RoleAttributes are the role-level options and per-role configuration
+// parameters SetRoleAttributes applies.
+type RoleAttributes struct {
+	// ConnectionLimit is the CONNECTION LIMIT of the role; -1 means none.
+	ConnectionLimit int32
+	// ValidUntil is when the role's password expires; nil means never.
+	ValidUntil *time.Time
+	// Settings maps configuration parameters to the value the role's sessions
+	// start with. An empty value resets the parameter to the server default.
+	Settings map[string]string
+	// SearchPath is the schema search path the role's sessions start with;
+	// empty resets it to the server default.
+	SearchPath []string
+}
+
+// SetRoleAttributes runs ALTER ROLE for the connection limit, password expiry
+// and each setting in attrs. Settings take effect for sessions opened
+// afterwards; a role that does not exist is skipped.
+func (p postgresManager) SetRoleAttributes(conn PostgresConn, username string, attrs RoleAttributes) error {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname = $1)", username).Scan(&exists); err != nil {
+		return fmt.Errorf("checking if role exists: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	quotedUser := pq.QuoteIdentifier(username)
+	validUntil := "infinity"
+	if attrs.ValidUntil != nil {
+		validUntil = attrs.ValidUntil.UTC().Format(time.RFC3339)
+	}
+	alterSQL := fmt.Sprintf("ALTER ROLE %s WITH CONNECTION LIMIT %d VALID UNTIL %s",
+		quotedUser, attrs.ConnectionLimit, pq.QuoteLiteral(validUntil))
+	if _, err := db.Exec(alterSQL); err != nil {
+		return fmt.Errorf("altering role %q: %w", username, err)
+	}
+
+	names := slices.Sorted(maps.Keys(attrs.Settings))
+	for _, name := range names {
+		value := attrs.Settings[name]
+		settingSQL := fmt.Sprintf("ALTER ROLE %s RESET %s", quotedUser, pq.QuoteIdentifier(name))
+		if value != "" {
+			settingSQL = fmt.Sprintf("ALTER ROLE %s SET %s = %s", quotedUser, pq.QuoteIdentifier(name), pq.QuoteLiteral(value))
+		}
+		if _, err := db.Exec(settingSQL); err != nil {
+			return fmt.Errorf("setting %s for role %q: %w", name, username, err)
+		}
+	}
+
+	// search_path is a list, so each schema is passed as its own identifier;
+	// a single quoted literal would be read as one schema name.
+	searchPathSQL := fmt.Sprintf("ALTER ROLE %s RESET search_path", quotedUser)
+	if len(attrs.SearchPath) > 0 {
+		schemas := make([]string, len(attrs.SearchPath))
+		for i, schema := range attrs.SearchPath {
+			schemas[i] = pq.QuoteIdentifier(schema)
+		}
+		searchPathSQL = fmt.Sprintf("ALTER ROLE %s SET search_path = %s", quotedUser, strings.Join(schemas, ", "))
+	}
+	if _, err := db.Exec(searchPathSQL); err != nil {
+		return fmt.Errorf("setting search_path for role %q: %w", username, err)
+	}
+	return nil
+}
+
+// SetMemberships grants username membership in each of roles and revokes its
+// membership in any other role, returning a description of each membership
+// it revoked.
+func (p postgresManager) SetMemberships(conn PostgresConn, username string, roles []string) ([]string, error) {
+	db, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT DISTINCT g.rolname FROM pg_auth_members m
+		JOIN pg_roles g ON g.oid = m.roleid
+		JOIN pg_roles u ON u.oid = m.member
+		WHERE u.rolname = $1`, username)
+	if err != nil {
+		return nil, fmt.Errorf("reading memberships of %q: %w", username, err)
+	}
+	var current []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning membership: %w", err)
+		}
+		current = append(current, role)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading memberships of %q: %w", username, err)
+	}
+
+	quotedUser := pq.QuoteIdentifier(username)
+	for _, role := range roles {
+		if slices.Contains(current, role) {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(role), quotedUser)); err != nil {
+			return nil, fmt.Errorf("granting membership in %q to %q: %w", role, username, err)
+		}
+	}
+
+	var revoked []string
+	for _, role := range current {
+		if slices.Contains(roles, role) {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(role), quotedUser)); err != nil {
+			return revoked, fmt.Errorf("revoking membership in %q from %q: %w", role, username, err)
+		}
+		revoked = append(revoked, "membership in role "+pq.QuoteIdentifier(role))
+	}
+	return revoked, nil
+}
+
+// grantedPrivilege is a privilege held directly by a role, as read from the
+// ACL columns of the system catalogs.
+type grantedPrivilege struct {
+	// kind is the object keyword used in GRANT and REVOKE: DATABASE, SCHEMA,
+	// TABLE, SEQUENCE or FUNCTION. Default privileges use the plural keyword
+	// of ALTER DEFAULT PRIVILEGES instead: TABLES, SEQUENCES, FUNCTIONS, TYPES
+	// or SCHEMAS.
+	kind   string
+	schema string
+	name   string
+	// target is the quoted object reference for REVOKE. For default privileges
+	// it is the quoted role whose future objects they apply to.
+	target    string
+	privilege string
+}
+
+// grantedPrivilegesSQL lists the privileges granted to the role named $1 on
+// the schemas, relations and functions of the connected database, skipping
+// objects the role owns. Views, materialized views and foreign tables are
+// reported as tables, which is how GRANT treats them.
+const grantedPrivilegesSQL = `WITH r AS (SELECT oid FROM pg_roles WHERE rolname = $1)
+SELECT 'SCHEMA', n.nspname, n.nspname, format('%I', n.nspname), a.privilege_type
+FROM pg_namespace n, aclexplode(n.nspacl) a, r
+WHERE a.grantee = r.oid AND n.nspowner <> r.oid
+UNION ALL
+SELECT CASE WHEN c.relkind = 'S' THEN 'SEQUENCE' ELSE 'TABLE' END, n.nspname, c.relname,
+	format('%I.%I', n.nspname, c.relname), a.privilege_type
+FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace, aclexplode(c.relacl) a, r
+WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S') AND a.grantee = r.oid AND c.relowner <> r.oid
+UNION ALL
+SELECT 'FUNCTION', n.nspname, p.proname,
+	format('%I.%I(%s)', n.nspname, p.proname, pg_get_function_identity_arguments(p.oid)), a.privilege_type
+FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace, aclexplode(p.proacl) a, r
+WHERE p.prokind = 'f' AND a.grantee = r.oid AND p.proowner <> r.oid
+UNION ALL
+SELECT CASE d.defaclobjtype WHEN 'r' THEN 'TABLES' WHEN 'S' THEN 'SEQUENCES' WHEN 'f' THEN 'FUNCTIONS'
+	WHEN 'T' THEN 'TYPES' ELSE 'SCHEMAS' END, coalesce(n.nspname, ''), '',
+	format('%I', pg_get_userbyid(d.defaclrole)), a.privilege_type
+FROM pg_default_acl d LEFT JOIN pg_namespace n ON n.oid = d.defaclnamespace, aclexplode(d.defaclacl) a, r
+WHERE a.grantee = r.oid AND d.defaclrole <> r.oid`
+
+// databasePrivilegesSQL lists the privileges granted to the role named $1 on
+// the databases of the instance, skipping databases the role owns.
+const databasePrivilegesSQL = `WITH r AS (SELECT oid FROM pg_roles WHERE rolname = $1)
+SELECT 'DATABASE', '', d.datname, format('%I', d.datname), a.privilege_type
+FROM pg_database d, aclexplode(d.datacl) a, r
+WHERE a.grantee = r.oid AND d.datdba <> r.oid`
+
+// privilegeKeywordPattern matches the privilege names aclexplode reports.
+// They are checked before being interpolated into a REVOKE statement.
+var privilegeKeywordPattern = regexp.MustCompile(`^[A-Z]+( [A-Z]+)*$`)
+
+// RevokeUndeclared compares the ACLs of every connectable database with
+// entries and revokes what they do not declare, including default privileges
+// and grants in databases entries do not mention. Privileges held through
+// PUBLIC, role membership or ownership are left alone.
+func (p postgresManager) RevokeUndeclared(conn PostgresConn, username string, entries []v1alpha1.DatabasePermissionEntry) ([]string, error) {
+	mainDB, err := openPostgres(conn, "postgres")
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	defer mainDB.Close()
+
+	revoked, err := revokeUndeclaredIn(mainDB, databasePrivilegesSQL, "", username, entries)
+	if err != nil {
+		return revoked, err
+	}
+
+	var databases []string
+	rows, err := mainDB.Query("SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate ORDER BY datname")
+	if err != nil {
+		return revoked, fmt.Errorf("listing databases: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return revoked, fmt.Errorf("scanning database name: %w", err)
+		}
+		databases = append(databases, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return revoked, fmt.Errorf("listing databases: %w", err)
+	}
+
+	for _, dbName := range databases {
+		db, err := openPostgres(conn, dbName)
+		if err != nil {
+			return revoked, fmt.Errorf("connecting to database %q: %w", dbName, err)
+		}
+		inDB, err := revokeUndeclaredIn(db, grantedPrivilegesSQL, dbName, username, entries)
+		db.Close()
+		revoked = append(revoked, inDB...)
+		if err != nil {
+			return revoked, err
+		}
+	}
+	return revoked, nil
+}
+
+// revokeUndeclaredIn runs query on db to find the privileges of username and
+// revokes those entries do not declare in dbName. An empty dbName means the
+// privileges are on databases themselves.
+func revokeUndeclaredIn(db *sql.DB, query, dbName, username string, entries []v1alpha1.DatabasePermissionEntry) ([]string, error) {
+	rows, err := db.Query(query, username)
+	if err != nil {
+		return nil, fmt.Errorf("reading privileges of %q: %w", username, err)
+	}
+	var granted []grantedPrivilege
+	for rows.Next() {
+		var g grantedPrivilege
+		if err := rows.Scan(&g.kind, &g.schema, &g.name, &g.target, &g.privilege); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning privilege: %w", err)
+		}
+		granted = append(granted, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading privileges of %q: %w", username, err)
+	}
+
+	quotedUser := pq.QuoteIdentifier(username)
+	var revoked []string
+	for _, g := range granted {
+		if privilegeDeclared(entries, dbName, g) {
+			continue
+		}
+		if !privilegeKeywordPattern.MatchString(g.privilege) {
+			return revoked, fmt.Errorf("unexpected privilege %q on %s %s", g.privilege, g.kind, g.target)
+		}
+
+		var revokeSQL, description string
+		switch g.kind {
+		case "TABLES", "SEQUENCES", "FUNCTIONS", "TYPES", "SCHEMAS":
+			inSchema := ""
+			if g.schema != "" {
+				inSchema = " IN SCHEMA " + pq.QuoteIdentifier(g.schema)
+			}
+			revokeSQL = fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s%s REVOKE %s ON %s FROM %s",
+				g.target, inSchema, g.privilege, g.kind, quotedUser)
+			description = fmt.Sprintf("default %s on %s created by %s%s", g.privilege, g.kind, g.target, inSchema)
+		default:
+			revokeSQL = fmt.Sprintf("REVOKE %s ON %s %s FROM %s", g.privilege, g.kind, g.target, quotedUser)
+			description = fmt.Sprintf("%s on %s %s", g.privilege, g.kind, g.target)
+		}
+		if dbName != "" {
+			description += " in database " + pq.QuoteIdentifier(dbName)
+		}
+
+		if _, err := db.Exec(revokeSQL); err != nil {
+			return revoked, fmt.Errorf("revoking %s from %q: %w", description, username, err)
+		}
+		revoked = append(revoked, description)
+	}
+	return revoked, nil
+}
+
+// privilegeDeclared reports whether any of entries grants g in dbName, using
+// the same rules as EnsureUser: schema privileges need USAGE or CREATE listed;
+// object privileges need a permission the object kind accepts, on the named
+// objects or, for entries naming none, on every object and default privilege
+// in the entry's schemas. Privileges on databases are never declared.
+func privilegeDeclared(entries []v1alpha1.DatabasePermissionEntry, dbName string, g grantedPrivilege) bool {
+	for _, entry := range entries {
+		if !slices.Contains(entry.Databases, dbName) {
+			continue
+		}
+		schemas := entry.Schemas
+		if len(schemas) == 0 {
+			schemas = []string{"public"}
+		}
+		if !slices.Contains(schemas, g.schema) {
+			continue
+		}
+		named := len(entry.Tables) > 0 || len(entry.Sequences) > 0 || len(entry.Functions) > 0
+
+		if g.kind == "SCHEMA" {
+			if schemaPrivileges[v1alpha1.DatabasePermission(g.privilege)] &&
+				slices.Contains(entry.Permissions, v1alpha1.DatabasePermission(g.privilege)) {
+				return true
+			}
+			continue
+		}
+
+		for _, kind := range grantObjectKinds {
+			if g.kind != kind.keyword && g.kind != kind.keywordPlural {
+				continue
+			}
+			if !permitsPrivilege(entry.Permissions, kind, g.privilege) {
+				continue
+			}
+			if !named {
+				return true
+			}
+			if g.kind == kind.keyword && slices.Contains(kind.names(entry), g.name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// permitsPrivilege reports whether permissions grant privilege on objects of
+// kind. ALL permits every privilege kind supports, including ones newer
+// PostgreSQL releases add to it.
+func permitsPrivilege(permissions []v1alpha1.DatabasePermission, kind grantObjectKind, privilege string) bool {
+	if kind.privileges[v1alpha1.PermissionAll] && slices.Contains(permissions, v1alpha1.PermissionAll) {
+		return true
+	}
+	p := v1alpha1.DatabasePermission(privilege)
+	return kind.privileges[p] && slices.Contains(permissions, p)
+}
+
 // DropUser removes the specified role from the Postgres cluster.
 //
 // Dropping a role requires that it owns no objects and holds no privileges in
@@ -304,14 +1192,14 @@ func (p postgresManager) FindOwner(host, adminUser, adminPass, dbName string) (s
 //  3. DROP OWNED revokes all remaining per-database privileges and removes
 //     pg_default_acl entries that reference the role as grantor or grantee.
 //  4. DROP ROLE IF EXISTS removes the cluster-level role.
-func (p postgresManager) DropUser(host, adminUser, adminPass, dbName, username string) error {
+func (p postgresManager) DropUser(conn PostgresConn, dbName, username string) error {
 	quotedUser := pq.QuoteIdentifier(username)
-	quotedAdmin := pq.QuoteIdentifier(adminUser)
+	quotedAdmin := pq.QuoteIdentifier(conn.User)
 
 	// Steps 1 & 4 use the maintenance database so that cluster-level operations
 	// (REASSIGN OWNED for databases/tablespaces and DROP ROLE) can execute
 	// outside the target database.
-	mainDB, err := openPostgres(host, adminUser, adminPass, "postgres")
+	mainDB, err := openPostgres(conn, "postgres")
 	if err != nil {
 		return fmt.Errorf("connecting to Postgres: %w", err)
 	}
@@ -325,7 +1213,7 @@ func (p postgresManager) DropUser(host, adminUser, adminPass, dbName, username s
 	}
 
 	// Steps 2 & 3 run inside the target database.
-	db, err := openPostgres(host, adminUser, adminPass, dbName)
+	db, err := openPostgres(conn, dbName)
 	if err != nil {
 		return fmt.Errorf("connecting to Postgres: %w", err)
 	}
@@ -351,21 +1239,37 @@ func (p postgresManager) DropUser(host, adminUser, adminPass, dbName, username s
 	return nil
 }
 
-// isTableNotFoundError reports whether err originated from PostgreSQL error code
-// 42P01 (undefined_table), which is returned when a GRANT targets a table that
-// does not exist in the database.
-func isTableNotFoundError(err error) bool {
+// objectNotFoundReason returns the condition reason for err when it originated
+// from a GRANT naming an object that does not exist: PostgreSQL error code
+// 42P01 (undefined_table, also raised for sequences), 42883 (undefined_function)
+// or 3F000 (invalid_schema_name). It returns "" for any other error.
+func objectNotFoundReason(err error) string {
 	var pqErr *pq.Error
-	if errors.As(err, &pqErr) {
-		return pqErr.Code == "42P01"
+	if !errors.As(err, &pqErr) {
+		return ""
 	}
-	return false
+	switch pqErr.Code {
+	case "42P01":
+		return "TableNotFound"
+	case "42883":
+		return "FunctionNotFound"
+	case "3F000":
+		return "SchemaNotFound"
+	}
+	return ""
 }
 
-// openPostgres opens a verified connection to a Postgres instance.
-func openPostgres(host, user, password, dbName string) (*sql.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable connect_timeout=5",
-		host, postgresPort, user, password, dbName)
+// openPostgres opens a verified connection to a Postgres instance. When conn
+// carries a CA bundle the connection uses TLS and checks the server
+// certificate against it and the host name; otherwise TLS is disabled.
+func openPostgres(conn PostgresConn, dbName string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s connect_timeout=5",
+		conn.Host, postgresPort, conn.User, conn.Password, dbName)
+	if conn.CACert != "" {
+		dsn += " sslmode=verify-full sslinline=true sslrootcert=" + pgconn.QuoteValue(conn.CACert)
+	} else {
+		dsn += " sslmode=disable"
+	}
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
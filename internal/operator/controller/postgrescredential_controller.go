@@ -3,6 +3,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -10,9 +12,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
@@ -21,6 +26,17 @@ const (
 	// credentialFinalizerName is added to PostgresCredential resources to ensure
 	// the Postgres user and credential Secret are cleaned up before deletion.
 	credentialFinalizerName = "games-hub.io/postgres-credential"
+
+	// privilegesInSyncCondition reports whether the role's privileges match
+	// spec.permissions, and what was last revoked to make them match.
+	privilegesInSyncCondition = "PrivilegesInSync"
+
+	// privilegeResyncInterval is how often a Ready credential is reconciled
+	// to catch privileges granted by hand since the last pass.
+	privilegeResyncInterval = 10 * time.Minute
+
+	// maxRevokedInMessage caps how many revoked privileges a condition lists.
+	maxRevokedInMessage = 10
 )
 
 // PostgresCredentialReconciler reconciles a PostgresCredential object.
@@ -36,6 +52,7 @@ type PostgresCredentialReconciler struct {
 // +kubebuilder:rbac:groups=games-hub.io,resources=postgrescredentials/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=games-hub.io,resources=postgrescredentials/finalizers,verbs=update
 // +kubebuilder:rbac:groups=games-hub.io,resources=postgresdatabases,verbs=get;list;watch
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresrestores,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile handles create/update/delete events for PostgresCredential resources.
@@ -89,85 +106,96 @@ func (r *PostgresCredentialReconciler) Reconcile(ctx context.Context, req ctrl.R
 // Postgres user, and mutates pgcred status in memory. The caller is responsible
 // for persisting status via a single r.Status().Update() call.
 func (r *PostgresCredentialReconciler) reconcileCredential(ctx context.Context, pgcred *v1alpha1.PostgresCredential) (ctrl.Result, error) {
-	var pgdb v1alpha1.PostgresDatabase
-	dbKey := types.NamespacedName{Name: pgcred.Spec.DatabaseRef, Namespace: pgcred.Namespace}
-	pgdbFound, err := r.client.get(ctx, dbKey, &pgdb)
+	pgdb, conn, wait, err := postgresAdminConn(ctx, &r.client, pgcred.Namespace, pgcred.Spec.DatabaseRef)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("fetching target PostgresDatabase: %w", err)
+		return ctrl.Result{}, err
 	}
-	if !pgdbFound {
-		return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhasePending,
-			"DatabaseNotFound", fmt.Sprintf("target PostgresDatabase %q not found", pgcred.Spec.DatabaseRef)), nil
+	if wait != nil {
+		return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhasePending, wait.reason, wait.message), nil
 	}
+	caCert := conn.CACert
 
-	if pgdb.Status.Phase != v1alpha1.DatabasePhaseReady {
-		return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhasePending,
-			"DatabaseNotReady", fmt.Sprintf("waiting for PostgresDatabase %q to become Ready", pgcred.Spec.DatabaseRef)), nil
-	}
-
-	if pgdb.Status.SecretName == "" {
-		return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhasePending,
-			"AdminSecretNotReady", "PostgresDatabase admin Secret name is not yet populated"), nil
-	}
-
-	var adminSecret corev1.Secret
-	adminSecretKey := types.NamespacedName{Name: pgdb.Status.SecretName, Namespace: pgdb.Namespace}
-	adminFound, err := r.client.get(ctx, adminSecretKey, &adminSecret)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("fetching admin Secret %q: %w", pgdb.Status.SecretName, err)
-	}
-	if !adminFound {
-		return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhasePending,
-			"AdminSecretNotFound", fmt.Sprintf("admin Secret %q not yet visible in cache", pgdb.Status.SecretName)), nil
+	memberOf, ok, roleResult, err := r.resolveMemberOf(ctx, pgcred)
+	if !ok {
+		return roleResult, err
 	}
 
-	adminUser := string(adminSecret.Data["PGUSER"])
-	adminPass := string(adminSecret.Data["PGPASSWORD"])
-	host := postgresHost(&pgdb)
-
 	var existingSecret corev1.Secret
 	credSecretKey := types.NamespacedName{Name: pgcred.Spec.SecretName, Namespace: pgcred.Namespace}
 	secretFound, err := r.client.get(ctx, credSecretKey, &existingSecret)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("fetching credential Secret: %w", err)
 	}
+
+	// Grants are applied on every reconcile, not just when the Secret is first
+	// created, so permissions added to the spec later take effect. The password
+	// is only used if the role has to be created.
+	var password string
 	if !secretFound {
-		password, err := generatePassword(24)
+		password, err = generatePassword(24)
 		if err != nil {
 			return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
 				"PasswordGenerationFailed", err.Error()), err
 		}
+	} else if string(existingSecret.Data["PGUSER"]) == pgcred.Spec.Username {
+		password = string(existingSecret.Data["PGPASSWORD"])
+	}
 
-		for _, entry := range pgcred.Spec.Permissions {
-			for _, dbName := range entry.Databases {
-				if err := r.pgDB.EnsureDatabase(host, adminUser, adminPass, dbName); err != nil {
-					return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
-						"DatabaseCreationFailed", err.Error()), err
+	// A credential without permissions entries still needs its role, for
+	// instance to inherit privileges through spec.memberOf.
+	if len(pgcred.Spec.Permissions) == 0 {
+		if err := r.pgDB.EnsureUser(conn, "postgres", pgcred.Spec.Username, password, v1alpha1.DatabasePermissionEntry{}); err != nil {
+			return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+				"UserCreationFailed", err.Error()), err
+		}
+	}
+	for _, entry := range pgcred.Spec.Permissions {
+		for _, dbName := range entry.Databases {
+			if err := r.pgDB.EnsureDatabase(conn, dbName); err != nil {
+				return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+					"DatabaseCreationFailed", err.Error()), err
+			}
+			if err := r.pgDB.EnsureUser(conn, dbName, pgcred.Spec.Username, password, entry); err != nil {
+				reason := "UserCreationFailed"
+				if notFound := objectNotFoundReason(err); notFound != "" {
+					reason = notFound
 				}
-				if err := r.pgDB.EnsureUser(host, adminUser, adminPass, dbName, pgcred.Spec.Username, password, entry.Permissions, entry.Tables); err != nil {
-					reason := "UserCreationFailed"
-					if isTableNotFoundError(err) {
-						reason = "TableNotFound"
-					}
-					return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
-						reason, err.Error()), err
+				return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+					reason, err.Error()), err
+			}
+			if pgcred.Spec.DatabaseOwner {
+				conflict, conflictResult, conflictErr := r.checkOwnerConflict(ctx, pgcred, conn, dbName)
+				if conflictErr != nil {
+					return conflictResult, conflictErr
 				}
-				if pgcred.Spec.DatabaseOwner {
-					conflict, conflictResult, conflictErr := r.checkOwnerConflict(ctx, pgcred, host, adminUser, adminPass, dbName)
-					if conflictErr != nil {
-						return conflictResult, conflictErr
-					}
-					if conflict {
-						return conflictResult, nil
-					}
-					if err := r.pgDB.EnsureOwner(host, adminUser, adminPass, dbName, pgcred.Spec.Username); err != nil {
-						return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
-							"OwnerSetupFailed", err.Error()), err
-					}
+				if conflict {
+					return conflictResult, nil
+				}
+				if err := r.pgDB.EnsureOwner(conn, dbName, pgcred.Spec.Username); err != nil {
+					return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+						"OwnerSetupFailed", err.Error()), err
 				}
 			}
 		}
+	}
+
+	// The rotation alias logs in on its own, so it needs the same limits.
+	for _, role := range []string{pgcred.Spec.Username, rotationAlias(pgcred)} {
+		if role == "" {
+			continue
+		}
+		if err := r.pgDB.SetRoleAttributes(conn, role, roleAttributes(pgcred)); err != nil {
+			return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+				"RoleAttributesFailed", err.Error()), err
+		}
+	}
+
+	if err := r.correctPrivilegeDrift(ctx, pgcred, conn, memberOf); err != nil {
+		return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+			"PrivilegeRevocationFailed", err.Error()), err
+	}
 
+	if !secretFound {
 		// Only set PGDATABASE when the credential targets exactly one database.
 		// With multiple databases the key is omitted so that applications must
 		// explicitly name the target database; a missing target will produce a
@@ -176,12 +204,21 @@ func (r *PostgresCredentialReconciler) reconcileCredential(ctx context.Context,
 		secretData := map[string]string{
 			"PGUSER":     pgcred.Spec.Username,
 			"PGPASSWORD": password,
-			"PGHOST":     host,
+			"PGHOST":     conn.Host,
+			"PGHOST_RO":  postgresReadOnlyHost(pgdb),
 			"PGPORT":     fmt.Sprintf("%d", postgresPort),
+			"PGSSLMODE":  postgresSSLMode(caCert),
+		}
+		if caCert != "" {
+			secretData["ca.crt"] = caCert
 		}
 		if db := singleDatabase(pgcred.Spec.Permissions); db != "" {
 			secretData["PGDATABASE"] = db
 		}
+		if pgdb.Spec.Pooler != nil {
+			secretData["PGBOUNCER_HOST"] = poolerHost(pgdb)
+			secretData["PGBOUNCER_PORT"] = fmt.Sprintf("%d", pgbouncerPort)
+		}
 
 		secret := &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
@@ -194,11 +231,170 @@ func (r *PostgresCredentialReconciler) reconcileCredential(ctx context.Context,
 		if err := r.client.createOwned(ctx, pgcred, secret); err != nil {
 			return ctrl.Result{}, fmt.Errorf("creating credential Secret: %w", err)
 		}
+	} else {
+		if err := r.expireGracePeriod(pgcred, conn); err != nil {
+			return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+				"PasswordRotationFailed", err.Error()), err
+		}
+
+		changed := updateConnectionKeys(&existingSecret, pgdb, caCert)
+		previous := string(existingSecret.Data["PGUSER"])
+		rotated, err := r.rotatePassword(ctx, pgcred, conn, &existingSecret)
+		if err != nil {
+			return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+				"PasswordRotationFailed", err.Error()), err
+		}
+
+		// Connection keys and a rotated password go out in one update, so
+		// readers never see the new password without the matching PGUSER.
+		if changed || rotated {
+			if err := r.client.update(ctx, &existingSecret); err != nil {
+				return ctrl.Result{}, fmt.Errorf("updating credential Secret: %w", err)
+			}
+		}
+		if rotated {
+			now := metav1.Now()
+			pgcred.Status.LastRotated = &now
+			pgcred.Status.ActiveUsername = string(existingSecret.Data["PGUSER"])
+			if grace := pgcred.Spec.Rotation.GracePeriod; grace != nil {
+				expires := metav1.NewTime(now.Add(grace.Duration))
+				pgcred.Status.PreviousUsername = previous
+				pgcred.Status.GraceExpiresAt = &expires
+			}
+		}
 	}
 
 	pgcred.Status.SecretName = pgcred.Spec.SecretName
-	return r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseReady,
-		"CredentialReady", "Postgres user and credential Secret are ready"), nil
+	result := r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseReady,
+		"CredentialReady", "Postgres user and credential Secret are ready")
+	result.RequeueAfter = privilegeResyncInterval
+	if next := nextRotationEvent(pgcred, existingSecret.CreationTimestamp.Time); next > 0 && next < result.RequeueAfter {
+		result.RequeueAfter = next
+	}
+	return result, nil
+}
+
+// correctPrivilegeDrift syncs the role's group memberships with memberOf,
+// revokes the privileges it holds beyond what pgcred declares, and records
+// the outcome in the PrivilegesInSync condition.
+func (r *PostgresCredentialReconciler) correctPrivilegeDrift(ctx context.Context, pgcred *v1alpha1.PostgresCredential, conn PostgresConn, memberOf []string) error {
+	revoked, err := r.pgDB.SetMemberships(conn, pgcred.Spec.Username, memberOf)
+	if err == nil {
+		var undeclared []string
+		undeclared, err = r.pgDB.RevokeUndeclared(conn, pgcred.Spec.Username, declaredPermissions(pgcred))
+		revoked = append(revoked, undeclared...)
+	}
+	recordPrivilegeDrift(ctx, &pgcred.Status.Conditions, pgcred.Generation, pgcred.Spec.Username, revoked, err)
+	return err
+}
+
+// resolveMemberOf returns the group role names of the PostgresRoles listed in
+// pgcred's spec.memberOf. When one cannot be used yet it mutates pgcred status
+// and returns ok=false along with the result the caller should return.
+func (r *PostgresCredentialReconciler) resolveMemberOf(ctx context.Context, pgcred *v1alpha1.PostgresCredential) ([]string, bool, ctrl.Result, error) {
+	roles := make([]string, 0, len(pgcred.Spec.MemberOf))
+	for _, name := range pgcred.Spec.MemberOf {
+		var role v1alpha1.PostgresRole
+		found, err := r.client.get(ctx, types.NamespacedName{Name: name, Namespace: pgcred.Namespace}, &role)
+		if err != nil {
+			return nil, false, ctrl.Result{}, fmt.Errorf("fetching PostgresRole %q: %w", name, err)
+		}
+		if !found {
+			return nil, false, r.setCredentialPhase(pgcred, v1alpha1.CredentialPhasePending,
+				"RoleNotFound", fmt.Sprintf("PostgresRole %q not found", name)), nil
+		}
+		if role.Spec.DatabaseRef != pgcred.Spec.DatabaseRef {
+			return nil, false, r.setCredentialPhase(pgcred, v1alpha1.CredentialPhaseFailed,
+				"RoleDatabaseMismatch", fmt.Sprintf("PostgresRole %q targets PostgresDatabase %q, not %q",
+					name, role.Spec.DatabaseRef, pgcred.Spec.DatabaseRef)), nil
+		}
+		if role.Status.Phase != v1alpha1.PostgresRolePhaseReady {
+			return nil, false, r.setCredentialPhase(pgcred, v1alpha1.CredentialPhasePending,
+				"RoleNotReady", fmt.Sprintf("waiting for PostgresRole %q to become Ready", name)), nil
+		}
+		roles = append(roles, role.Spec.RoleName)
+	}
+	return roles, true, ctrl.Result{}, nil
+}
+
+// rotatePassword sets a fresh password in Postgres when spec.rotation is due
+// and writes it into secret, reporting whether it did. In dual-credential mode
+// the password goes to whichever of username and its alias the Secret does not
+// currently hold, so the role in the Secret keeps working through the grace
+// period; otherwise the current role's password is replaced in place.
+//
+// As with the admin password, the new role and password are staged in the
+// Secret under pendingPasswordSuffix keys before the role is changed, and a
+// staged rotation is finished on the next reconcile whether or not one is
+// still due, so an interruption between the two steps never loses the
+// password the role now has.
+func (r *PostgresCredentialReconciler) rotatePassword(ctx context.Context, pgcred *v1alpha1.PostgresCredential, conn PostgresConn, secret *corev1.Secret) (bool, error) {
+	userKey, passwordKey := "PGUSER"+pendingPasswordSuffix, "PGPASSWORD"+pendingPasswordSuffix
+	target, password := string(secret.Data[userKey]), string(secret.Data[passwordKey])
+	if target == "" || password == "" {
+		rotation := pgcred.Spec.Rotation
+		if rotation == nil || time.Now().Before(rotationDue(pgcred, secret.CreationTimestamp.Time)) {
+			return false, nil
+		}
+
+		target = string(secret.Data["PGUSER"])
+		if target == "" {
+			target = pgcred.Spec.Username
+		}
+		if rotation.GracePeriod != nil {
+			if target == pgcred.Spec.Username {
+				target = rotationAlias(pgcred)
+				if err := r.pgDB.EnsureAlias(conn, target, pgcred.Spec.Username); err != nil {
+					return false, err
+				}
+				if err := r.pgDB.SetRoleAttributes(conn, target, roleAttributes(pgcred)); err != nil {
+					return false, err
+				}
+			} else {
+				target = pgcred.Spec.Username
+			}
+		}
+
+		var err error
+		if password, err = generatePassword(24); err != nil {
+			return false, err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[userKey] = []byte(target)
+		secret.Data[passwordKey] = []byte(password)
+		if err := r.client.update(ctx, secret); err != nil {
+			return false, fmt.Errorf("staging rotated password: %w", err)
+		}
+	}
+
+	if err := r.pgDB.SetPassword(conn, target, password); err != nil {
+		return false, err
+	}
+
+	secret.Data["PGUSER"] = []byte(target)
+	secret.Data["PGPASSWORD"] = []byte(password)
+	delete(secret.Data, userKey)
+	delete(secret.Data, passwordKey)
+	return true, nil
+}
+
+// expireGracePeriod removes the password of the role replaced by the last
+// dual-credential rotation once its grace period has ended.
+func (r *PostgresCredentialReconciler) expireGracePeriod(pgcred *v1alpha1.PostgresCredential, conn PostgresConn) error {
+	expires := pgcred.Status.GraceExpiresAt
+	if expires == nil || time.Now().Before(expires.Time) {
+		return nil
+	}
+	if previous := pgcred.Status.PreviousUsername; previous != "" {
+		if err := r.pgDB.SetPassword(conn, previous, ""); err != nil {
+			return err
+		}
+	}
+	pgcred.Status.PreviousUsername = ""
+	pgcred.Status.GraceExpiresAt = nil
+	return nil
 }
 
 // reconcileDelete handles cleanup when a PostgresCredential is being deleted.
@@ -221,17 +417,26 @@ func (r *PostgresCredentialReconciler) reconcileDelete(ctx context.Context, pgcr
 		var adminSecret corev1.Secret
 		adminSecretKey := types.NamespacedName{Name: pgdb.Status.SecretName, Namespace: pgdb.Namespace}
 		if adminFound, _ := r.client.get(ctx, adminSecretKey, &adminSecret); adminFound {
-			adminUser := string(adminSecret.Data["PGUSER"])
-			adminPass := string(adminSecret.Data["PGPASSWORD"])
-			host := postgresHost(&pgdb)
-
-			for _, entry := range pgcred.Spec.Permissions {
-				for _, dbName := range entry.Databases {
-					if err := r.pgDB.DropUser(host, adminUser, adminPass, dbName, pgcred.Spec.Username); err != nil {
-						logger.Error(err, "failed to drop Postgres user during cleanup", "username", pgcred.Spec.Username, "database", dbName)
-						// Continue with finalizer removal — the database may be going away too.
+			// A missing CA bundle makes the drop fail, which is logged like an unreachable database.
+			caCert, _ := postgresCACert(ctx, &r.client, &pgdb)
+			conn := PostgresConn{
+				Host:     postgresHost(&pgdb),
+				User:     string(adminSecret.Data["PGUSER"]),
+				Password: string(adminSecret.Data["PGPASSWORD"]),
+				CACert:   caCert,
+			}
+
+			alias := rotationAlias(pgcred)
+			for _, dbName := range roleDatabases(pgcred.Spec.Permissions) {
+				if alias != "" {
+					if err := r.pgDB.DropUser(conn, dbName, alias); err != nil {
+						logger.Error(err, "failed to drop Postgres user during cleanup", "username", alias, "database", dbName)
 					}
 				}
+				if err := r.pgDB.DropUser(conn, dbName, pgcred.Spec.Username); err != nil {
+					logger.Error(err, "failed to drop Postgres user during cleanup", "username", pgcred.Spec.Username, "database", dbName)
+					// Continue with finalizer removal — the database may be going away too.
+				}
 			}
 		}
 	}
@@ -307,10 +512,235 @@ func singleDatabase(entries []v1alpha1.DatabasePermissionEntry) string {
 	return found
 }
 
-// postgresHost returns the in-cluster DNS name for the Postgres instance
-// backed by a headless Service. The StatefulSet pod is <name>-0.<name>.<ns>.svc.cluster.local.
+// recordPrivilegeDrift sets the PrivilegesInSync condition in conditions from
+// the outcome of a drift correction for roleName. A correction stays reported
+// until the next one, so it remains visible after later reconciles find
+// nothing to revoke.
+func recordPrivilegeDrift(ctx context.Context, conditions *[]metav1.Condition, generation int64, roleName string, revoked []string, err error) {
+	if len(revoked) > 0 {
+		log.FromContext(ctx).Info("revoked undeclared privileges", "role", roleName, "revoked", revoked)
+		// Drop the old condition so LastTransitionTime records this correction.
+		meta.RemoveStatusCondition(conditions, privilegesInSyncCondition)
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               privilegesInSyncCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             "DriftCorrected",
+			Message:            "revoked " + summarizeRevoked(revoked),
+			ObservedGeneration: generation,
+		})
+	}
+	if err != nil {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               privilegesInSyncCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             "RevocationFailed",
+			Message:            err.Error(),
+			ObservedGeneration: generation,
+		})
+		return
+	}
+
+	current := meta.FindStatusCondition(*conditions, privilegesInSyncCondition)
+	if current == nil || current.Status != metav1.ConditionTrue {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               privilegesInSyncCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             "InSync",
+			Message:            "role privileges match spec.permissions",
+			ObservedGeneration: generation,
+		})
+	}
+}
+
+// declaredPermissions returns the permission entries the role of pgcred may
+// hold. A database owner also keeps what EnsureOwner grants it: USAGE and
+// CREATE on the public schema and every privilege on the objects in it.
+func declaredPermissions(pgcred *v1alpha1.PostgresCredential) []v1alpha1.DatabasePermissionEntry {
+	if !pgcred.Spec.DatabaseOwner {
+		return pgcred.Spec.Permissions
+	}
+	entries := slices.Clone(pgcred.Spec.Permissions)
+	for _, entry := range pgcred.Spec.Permissions {
+		entries = append(entries, v1alpha1.DatabasePermissionEntry{
+			Databases: entry.Databases,
+			Permissions: []v1alpha1.DatabasePermission{
+				v1alpha1.PermissionUsage, v1alpha1.PermissionCreate, v1alpha1.PermissionAll,
+			},
+		})
+	}
+	return entries
+}
+
+// summarizeRevoked joins revoked privilege descriptions for a condition
+// message, listing at most maxRevokedInMessage of them.
+func summarizeRevoked(revoked []string) string {
+	if len(revoked) <= maxRevokedInMessage {
+		return strings.Join(revoked, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(revoked[:maxRevokedInMessage], "; "), len(revoked)-maxRevokedInMessage)
+}
+
+// roleDatabases returns each database named by entries once, in order, or
+// the maintenance database when there are none, so a role without permissions
+// is still dropped.
+func roleDatabases(entries []v1alpha1.DatabasePermissionEntry) []string {
+	var databases []string
+	for _, entry := range entries {
+		for _, db := range entry.Databases {
+			if !slices.Contains(databases, db) {
+				databases = append(databases, db)
+			}
+		}
+	}
+	if len(databases) == 0 {
+		return []string{"postgres"}
+	}
+	return databases
+}
+
+// rotationAlias returns the second login role used by dual-credential rotation,
+// or "" when username is too long for the suffix to fit in a role name.
+func rotationAlias(pgcred *v1alpha1.PostgresCredential) string {
+	alias := pgcred.Spec.Username + "_alt"
+	if len(alias) > 63 {
+		return ""
+	}
+	return alias
+}
+
+// roleAttributes translates pgcred's connection limit, password expiry and
+// session settings into the attributes applied to each of its login roles.
+// Settings the spec omits are reset, so removing a field restores the server
+// default.
+func roleAttributes(pgcred *v1alpha1.PostgresCredential) RoleAttributes {
+	attrs := RoleAttributes{
+		ConnectionLimit: -1,
+		Settings: map[string]string{
+			"statement_timeout":                   durationSetting(pgcred.Spec.StatementTimeout),
+			"idle_in_transaction_session_timeout": durationSetting(pgcred.Spec.IdleInTransactionSessionTimeout),
+		},
+		SearchPath: pgcred.Spec.SearchPath,
+	}
+	if limit := pgcred.Spec.ConnectionLimit; limit != nil {
+		attrs.ConnectionLimit = *limit
+	}
+	if validUntil := pgcred.Spec.ValidUntil; validUntil != nil {
+		t := validUntil.Time
+		attrs.ValidUntil = &t
+	}
+	return attrs
+}
+
+// durationSetting formats d as a Postgres time setting in milliseconds, or ""
+// when d is unset.
+func durationSetting(d *metav1.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// rotationDue returns when the next password rotation is due, counting from
+// the last rotation or, before the first one, from when the Secret was created.
+func rotationDue(pgcred *v1alpha1.PostgresCredential, secretCreated time.Time) time.Time {
+	last := secretCreated
+	if pgcred.Status.LastRotated != nil {
+		last = pgcred.Status.LastRotated.Time
+	}
+	return last.Add(pgcred.Spec.Rotation.Interval.Duration)
+}
+
+// nextRotationEvent returns how long until the next rotation or grace period
+// expiry for pgcred, or zero when neither is scheduled.
+func nextRotationEvent(pgcred *v1alpha1.PostgresCredential, secretCreated time.Time) time.Duration {
+	var next time.Time
+	if pgcred.Spec.Rotation != nil {
+		if secretCreated.IsZero() {
+			secretCreated = time.Now()
+		}
+		next = rotationDue(pgcred, secretCreated)
+	}
+	if expires := pgcred.Status.GraceExpiresAt; expires != nil && (next.IsZero() || expires.Time.Before(next)) {
+		next = expires.Time
+	}
+	if next.IsZero() {
+		return 0
+	}
+	return max(time.Until(next), time.Second)
+}
+
+// postgresHost returns the in-cluster DNS name of the primary Service, which
+// always routes to the current primary pod of the Postgres instance.
 func postgresHost(pgdb *v1alpha1.PostgresDatabase) string {
-	return fmt.Sprintf("%s-0.%s.%s.svc.cluster.local", pgdb.Name, pgdb.Name, pgdb.Namespace)
+	return fmt.Sprintf("%s.%s.svc.cluster.local", primaryServiceName(pgdb), pgdb.Namespace)
+}
+
+// postgresPodHost returns the in-cluster DNS name of a single pod, published
+// by the headless Service: <name>-<ordinal>.<name>.<ns>.svc.cluster.local.
+func postgresPodHost(pgdb *v1alpha1.PostgresDatabase, ordinal int32) string {
+	return fmt.Sprintf("%s.%s.%s.svc.cluster.local", podName(pgdb, ordinal), serviceName(pgdb), pgdb.Namespace)
+}
+
+// updateConnectionKeys brings the connection keys of an existing credential
+// Secret in line with the database's current Services, pooler and TLS
+// settings, reporting whether anything changed. Secrets written by older operator
+// versions point at a pod rather than the primary Service, or lack PGHOST_RO
+// entirely. caCert is the database's CA bundle, or "" when TLS is disabled.
+func updateConnectionKeys(secret *corev1.Secret, pgdb *v1alpha1.PostgresDatabase, caCert string) bool {
+	desired := map[string]string{
+		"PGHOST":    postgresHost(pgdb),
+		"PGHOST_RO": postgresReadOnlyHost(pgdb),
+		"PGSSLMODE": postgresSSLMode(caCert),
+	}
+	changed := false
+	if caCert != "" {
+		desired["ca.crt"] = caCert
+	} else if _, ok := secret.Data["ca.crt"]; ok {
+		delete(secret.Data, "ca.crt")
+		changed = true
+	}
+	if pgdb.Spec.Pooler != nil {
+		desired["PGBOUNCER_HOST"] = poolerHost(pgdb)
+		desired["PGBOUNCER_PORT"] = fmt.Sprintf("%d", pgbouncerPort)
+	} else {
+		for _, key := range []string{"PGBOUNCER_HOST", "PGBOUNCER_PORT"} {
+			if _, ok := secret.Data[key]; ok {
+				delete(secret.Data, key)
+				changed = true
+			}
+		}
+	}
+	for key, value := range desired {
+		if string(secret.Data[key]) == value {
+			continue
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[key] = []byte(value)
+		changed = true
+	}
+	return changed
+}
+
+// postgresSSLMode returns the libpq sslmode clients must use: verify-full
+// against caCert when the database has TLS enabled, and disable otherwise.
+func postgresSSLMode(caCert string) string {
+	if caCert != "" {
+		return "verify-full"
+	}
+	return "disable"
+}
+
+// postgresReadOnlyHost returns the in-cluster DNS name of the read-only Service,
+// which balances across the standbys of the Postgres instance.
+func postgresReadOnlyHost(pgdb *v1alpha1.PostgresDatabase) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", readOnlyServiceName(pgdb), pgdb.Namespace)
+}
+
+// poolerHost returns the in-cluster DNS name of the PgBouncer Service.
+func poolerHost(pgdb *v1alpha1.PostgresDatabase) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", poolerName(pgdb), pgdb.Namespace)
 }
 
 // labelsForCredential returns the standard label set for resources owned by a
@@ -332,9 +762,10 @@ func labelsForCredential(pgcred *v1alpha1.PostgresCredential, instanceName strin
 func (r *PostgresCredentialReconciler) checkOwnerConflict(
 	ctx context.Context,
 	pgcred *v1alpha1.PostgresCredential,
-	host, adminUser, adminPass, dbName string,
+	conn PostgresConn,
+	dbName string,
 ) (bool, ctrl.Result, error) {
-	currentOwner, err := r.pgDB.FindOwner(host, adminUser, adminPass, dbName)
+	currentOwner, err := r.pgDB.FindOwner(conn, dbName)
 	if err != nil {
 		return false, ctrl.Result{}, fmt.Errorf("finding owner of database %q: %w", dbName, err)
 	}
@@ -366,12 +797,59 @@ func (r *PostgresCredentialReconciler) checkOwnerConflict(
 	return false, ctrl.Result{}, nil
 }
 
+// credentialsForDatabaseSecret maps a Secret belonging to a PostgresDatabase,
+// such as its admin, TLS or pooler Secret, to every PostgresCredential that
+// targets that database, so none keeps acting on a rotated admin password or
+// CA, and each gains or loses its PgBouncer keys with the pooler.
+func (r *PostgresCredentialReconciler) credentialsForDatabaseSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels["app.kubernetes.io/name"] != "postgres" {
+		return nil
+	}
+
+	var creds v1alpha1.PostgresCredentialList
+	if err := r.client.list(ctx, &creds, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "listing PostgresCredentials for database Secret", "secret", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cred := range creds.Items {
+		if cred.Spec.DatabaseRef == labels["app.kubernetes.io/instance"] {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cred)})
+		}
+	}
+	return requests
+}
+
+// credentialsForRole maps a PostgresRole to the PostgresCredentials in its
+// namespace that list it in spec.memberOf, so they pick up the role once it is
+// Ready and notice when it goes away.
+func (r *PostgresCredentialReconciler) credentialsForRole(ctx context.Context, obj client.Object) []reconcile.Request {
+	var creds v1alpha1.PostgresCredentialList
+	if err := r.client.list(ctx, &creds, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "listing PostgresCredentials for PostgresRole", "role", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cred := range creds.Items {
+		if slices.Contains(cred.Spec.MemberOf, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cred)})
+		}
+	}
+	return requests
+}
+
 // SetupWithManager registers the PostgresCredentialReconciler with the controller manager.
+// Secrets of the target database are watched as well, so credentials are
+// reconciled again as soon as its admin password or CA bundle changes.
 func (r *PostgresCredentialReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.client = postgresCredentialClient{inner: mgr.GetClient(), scheme: mgr.GetScheme()}
-	r.pgDB = postgresManager{}
+	r.pgDB = instrumentedPostgresManager{inner: postgresManager{}}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.PostgresCredential{}).
 		Owns(&corev1.Secret{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.credentialsForDatabaseSecret),
+			builder.WithPredicates(managedByInstance(r.InstanceName))).
+		Watches(&v1alpha1.PostgresRole{}, handler.EnqueueRequestsFromMapFunc(r.credentialsForRole)).
 		Complete(r)
 }
@@ -178,6 +178,64 @@ var _ = Describe("PostgresCredentialReconciler", func() {
 		})
 	})
 
+	// ── Operator metrics ─────────────────────────────────────────────────────
+	Context("when a credential targets a database that does not exist", Ordered, func() {
+		var (
+			ns         *corev1.Namespace
+			credLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "test-pgcred-metrics-",
+				},
+			}
+			Expect(K8sClient.Create(Ctx, ns)).To(Succeed())
+
+			pgcred := &v1alpha1.PostgresCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metrics-cred",
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.PostgresCredentialSpec{
+					DatabaseRef: "missing-db",
+					Username:    "metricsuser",
+					SecretName:  "metrics-cred-secret",
+				},
+			}
+			Expect(K8sClient.Create(Ctx, pgcred)).To(Succeed())
+			credLookup = types.NamespacedName{Name: pgcred.Name, Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhasePending))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should report the credential in the operator's phase and pending gauges", func() {
+			Eventually(func(g Gomega) {
+				body := ScrapeOperatorMetrics()
+				g.Expect(body).To(MatchRegexp(`db_operator_resources\{kind="PostgresCredential",phase="Pending"\} [1-9]`))
+				g.Expect(body).To(MatchRegexp(`db_operator_credentials_pending_database\{kind="PostgresCredential"\} [1-9]`))
+			}, Timeout, 2*time.Second).Should(Succeed())
+		})
+
+		It("should export the EnsureUser and EnsureACLUser latency histograms", func() {
+			body := ScrapeOperatorMetrics()
+			Expect(body).To(ContainSubstring("db_operator_postgres_ensure_user_duration_seconds"))
+			Expect(body).To(ContainSubstring("db_operator_redis_ensure_acl_user_duration_seconds"))
+		})
+	})
+
 	// ── Deletion / cleanup ───────────────────────────────────────────────────
 	Context("when a PostgresCredential is deleted", Ordered, func() {
 		var (
@@ -929,4 +987,427 @@ var _ = Describe("PostgresCredentialReconciler", func() {
 			}, Timeout, Interval).Should(Succeed())
 		})
 	})
+
+	// ── Schema, sequence and function privileges ────────────────────────────
+	Context("when a credential targets a non-public schema", Ordered, func() {
+		var (
+			ns                 *corev1.Namespace
+			pgdb               *v1alpha1.PostgresDatabase
+			dbLookup           types.NamespacedName
+			adminSecretLookup  types.NamespacedName
+			schemaSecretLookup types.NamespacedName
+		)
+
+		newCred := func(name, username string, entry v1alpha1.DatabasePermissionEntry) *v1alpha1.PostgresCredential {
+			return &v1alpha1.PostgresCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.PostgresCredentialSpec{
+					DatabaseRef: pgdb.Name,
+					Username:    username,
+					SecretName:  name + "-secret",
+					Permissions: []v1alpha1.DatabasePermissionEntry{entry},
+				},
+			}
+		}
+
+		BeforeAll(func() {
+			ns, pgdb, dbLookup, adminSecretLookup = NewDatabase("schema-scoped-db")
+			WaitForDatabase(dbLookup)
+
+			// A bootstrap credential makes the operator provision `schemadb`,
+			// then the admin creates the schema objects the test credential uses.
+			Expect(K8sClient.Create(Ctx, newCred("schema-bootstrap", "schemabootstrap", v1alpha1.DatabasePermissionEntry{
+				Databases:   []string{"schemadb"},
+				Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect},
+			}))).To(Succeed())
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: "schema-bootstrap", Namespace: ns.Name}, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, adminSecretLookup, "schemadb")
+			for _, stmt := range []string{
+				"CREATE SCHEMA app",
+				"CREATE TABLE app.items (id SERIAL PRIMARY KEY, name TEXT)",
+				"CREATE FUNCTION app.double(n INT) RETURNS INT LANGUAGE sql AS 'SELECT n * 2'",
+				"REVOKE EXECUTE ON FUNCTION app.double(INT) FROM PUBLIC",
+			} {
+				_, err := db.Exec(stmt)
+				Expect(err).NotTo(HaveOccurred(), stmt)
+			}
+			closeConn()
+
+			Expect(K8sClient.Create(Ctx, newCred("schema-cred", "schemauser", v1alpha1.DatabasePermissionEntry{
+				Databases: []string{"schemadb"},
+				Schemas:   []string{"app"},
+				Permissions: []v1alpha1.DatabasePermission{
+					v1alpha1.PermissionUsage,
+					v1alpha1.PermissionSelect,
+					v1alpha1.PermissionInsert,
+					v1alpha1.PermissionExecute,
+				},
+			}))).To(Succeed())
+			schemaSecretLookup = types.NamespacedName{Name: "schema-cred-secret", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: "schema-cred", Namespace: ns.Name}, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should allow inserts that draw from the table's sequence", func() {
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, schemaSecretLookup, "schemadb")
+			defer closeConn()
+			_, err := db.Exec("INSERT INTO app.items (name) VALUES ('widget')")
+			Expect(err).NotTo(HaveOccurred(), "schemauser should have INSERT on app.items and USAGE on its sequence")
+		})
+
+		It("should allow executing functions in the schema", func() {
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, schemaSecretLookup, "schemadb")
+			defer closeConn()
+			var doubled int
+			Expect(db.QueryRow("SELECT app.double(21)").Scan(&doubled)).To(Succeed())
+			Expect(doubled).To(Equal(42))
+		})
+
+		It("should deny creating objects in the schema without CREATE", func() {
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, schemaSecretLookup, "schemadb")
+			defer closeConn()
+			_, err := db.Exec("CREATE TABLE app.intruder (id INT)")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should grant tables created later in the schema", func() {
+			admin, closeAdmin := ConnectToDatabaseNamed(dbLookup, adminSecretLookup, "schemadb")
+			_, err := admin.Exec("CREATE TABLE app.later (id INT)")
+			closeAdmin()
+			Expect(err).NotTo(HaveOccurred())
+
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, schemaSecretLookup, "schemadb")
+			defer closeConn()
+			_, err = db.Exec("SELECT * FROM app.later")
+			Expect(err).NotTo(HaveOccurred(), "default privileges should cover tables created after the grant")
+		})
+
+		It("should transition a credential naming a missing schema to Failed with reason SchemaNotFound", func() {
+			Expect(K8sClient.Create(Ctx, newCred("missing-schema-cred", "missingschemauser", v1alpha1.DatabasePermissionEntry{
+				Databases:   []string{"schemadb"},
+				Schemas:     []string{"no_such_schema"},
+				Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionUsage},
+			}))).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: "missing-schema-cred", Namespace: ns.Name}, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseFailed))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "Ready")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("SchemaNotFound"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Privilege drift ─────────────────────────────────────────────────────
+	Context("when a role's privileges drift from spec.permissions", Ordered, func() {
+		var (
+			ns                *corev1.Namespace
+			pgdb              *v1alpha1.PostgresDatabase
+			dbLookup          types.NamespacedName
+			adminSecretLookup types.NamespacedName
+			credLookup        types.NamespacedName
+		)
+
+		hasPrivilege := func(g Gomega, privilege string) bool {
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, adminSecretLookup, "driftdb")
+			defer closeConn()
+			var has bool
+			g.Expect(db.QueryRow("SELECT has_table_privilege('driftuser', 'public.orders', $1)", privilege).Scan(&has)).To(Succeed())
+			return has
+		}
+
+		BeforeAll(func() {
+			ns, pgdb, dbLookup, adminSecretLookup = NewDatabase("drift-db")
+			WaitForDatabase(dbLookup)
+
+			CreateNewUser(ns.Name, pgdb.Name, "driftuser", "drift-secret", []v1alpha1.DatabasePermissionEntry{
+				{
+					Databases:   []string{"driftdb"},
+					Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect, v1alpha1.PermissionInsert},
+				},
+			})
+			credLookup = types.NamespacedName{Name: "driftuser", Namespace: ns.Name}
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "PrivilegesInSync")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("InSync"))
+			}, Timeout, Interval).Should(Succeed())
+
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, adminSecretLookup, "driftdb")
+			_, err := db.Exec("CREATE TABLE orders (id INT)")
+			Expect(err).NotTo(HaveOccurred())
+			closeConn()
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should revoke a privilege granted by hand", func() {
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, adminSecretLookup, "driftdb")
+			_, err := db.Exec("GRANT DELETE ON orders TO driftuser")
+			closeConn()
+			Expect(err).NotTo(HaveOccurred())
+
+			// Touch the credential so it is reconciled without waiting for the resync.
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &latest)).To(Succeed())
+				latest.Annotations = map[string]string{"test/touch": "1"}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(hasPrivilege(g, "DELETE")).To(BeFalse())
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "PrivilegesInSync")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("DriftCorrected"))
+				g.Expect(cond.Message).To(ContainSubstring("DELETE on TABLE public.orders"))
+			}, Timeout, Interval).Should(Succeed())
+			Expect(hasPrivilege(Default, "SELECT")).To(BeTrue())
+			Expect(hasPrivilege(Default, "INSERT")).To(BeTrue())
+		})
+
+		It("should revoke a permission removed from the spec", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &latest)).To(Succeed())
+				latest.Spec.Permissions[0].Permissions = []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(hasPrivilege(g, "INSERT")).To(BeFalse())
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "PrivilegesInSync")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Message).To(ContainSubstring("INSERT on TABLE public.orders"))
+			}, Timeout, Interval).Should(Succeed())
+			Expect(hasPrivilege(Default, "SELECT")).To(BeTrue())
+		})
+	})
+
+	// ── Password rotation ───────────────────────────────────────────────────
+	Context("when rotation has a grace period", Ordered, func() {
+		var (
+			ns                *corev1.Namespace
+			pgdb              *v1alpha1.PostgresDatabase
+			dbLookup          types.NamespacedName
+			adminSecretLookup types.NamespacedName
+			credLookup        types.NamespacedName
+			credSecretLookup  types.NamespacedName
+			originalPassword  string
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, dbLookup, adminSecretLookup = NewDatabase("cred-rotation-db")
+			WaitForDatabase(dbLookup)
+
+			pgcred := &v1alpha1.PostgresCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cred-rotation",
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.PostgresCredentialSpec{
+					DatabaseRef: pgdb.Name,
+					Username:    "rotuser",
+					SecretName:  "cred-rotation-secret",
+					Permissions: []v1alpha1.DatabasePermissionEntry{
+						{
+							Databases:   []string{"testdb"},
+							Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect},
+						},
+					},
+					Rotation: &v1alpha1.CredentialRotation{
+						Interval:    metav1.Duration{Duration: time.Minute},
+						GracePeriod: &metav1.Duration{Duration: 20 * time.Second},
+					},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, pgcred)).To(Succeed())
+			credLookup = types.NamespacedName{Name: pgcred.Name, Namespace: ns.Name}
+			credSecretLookup = types.NamespacedName{Name: pgcred.Spec.SecretName, Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, credSecretLookup, &secret)).To(Succeed())
+				originalPassword = string(secret.Data["PGPASSWORD"])
+				g.Expect(originalPassword).NotTo(BeEmpty())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should move the Secret to the alias role with a new password once the interval passes", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.LastRotated).NotTo(BeNil())
+				g.Expect(fetched.Status.ActiveUsername).To(Equal("rotuser_alt"))
+
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, credSecretLookup, &secret)).To(Succeed())
+				g.Expect(string(secret.Data["PGUSER"])).To(Equal("rotuser_alt"))
+				g.Expect(string(secret.Data["PGPASSWORD"])).NotTo(Equal(originalPassword))
+				g.Expect(secret.Data).NotTo(HaveKey("PGUSER_PENDING"))
+				g.Expect(secret.Data).NotTo(HaveKey("PGPASSWORD_PENDING"))
+			}, 2*Timeout, Interval).Should(Succeed())
+		})
+
+		It("should let the rotated credentials act as the original role", func() {
+			db, close := ConnectToDatabase(dbLookup, credSecretLookup)
+			defer close()
+
+			var currentUser string
+			Expect(db.QueryRow("SELECT current_user").Scan(&currentUser)).To(Succeed())
+			Expect(currentUser).To(Equal("rotuser"))
+		})
+
+		It("should remove the previous role's password when the grace period ends", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.GraceExpiresAt).To(BeNil())
+				g.Expect(fetched.Status.PreviousUsername).To(BeEmpty())
+			}, Timeout, Interval).Should(Succeed())
+
+			db, close := ConnectToDatabase(dbLookup, adminSecretLookup)
+			defer close()
+
+			var hasPassword bool
+			err := db.QueryRow(`SELECT rolpassword IS NOT NULL FROM pg_authid WHERE rolname = 'rotuser'`).Scan(&hasPassword)
+			Expect(err).To(Succeed())
+			Expect(hasPassword).To(BeFalse(), "role 'rotuser' should no longer have a password")
+		})
+	})
+
+	// ── Connection limit and role attributes ────────────────────────────────
+	Context("when a credential sets a connection limit and session settings", Ordered, func() {
+		var (
+			ns                *corev1.Namespace
+			dbLookup          types.NamespacedName
+			adminSecretLookup types.NamespacedName
+			credLookup        types.NamespacedName
+			credSecretLookup  types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			var pgdb *v1alpha1.PostgresDatabase
+			ns, pgdb, dbLookup, adminSecretLookup = NewDatabase("cred-attrs-db")
+			WaitForDatabase(dbLookup)
+
+			limit := int32(5)
+			pgcred := &v1alpha1.PostgresCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cred-attrs",
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.PostgresCredentialSpec{
+					DatabaseRef: pgdb.Name,
+					Username:    "attruser",
+					SecretName:  "cred-attrs-secret",
+					Permissions: []v1alpha1.DatabasePermissionEntry{
+						{
+							Databases:   []string{"testdb"},
+							Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect},
+						},
+					},
+					ConnectionLimit:  &limit,
+					StatementTimeout: &metav1.Duration{Duration: 30 * time.Second},
+					SearchPath:       []string{"app", "public"},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, pgcred)).To(Succeed())
+			credLookup = types.NamespacedName{Name: pgcred.Name, Namespace: ns.Name}
+			credSecretLookup = types.NamespacedName{Name: pgcred.Spec.SecretName, Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should set the role's connection limit", func() {
+			db, close := ConnectToDatabase(dbLookup, adminSecretLookup)
+			defer close()
+
+			var connLimit int
+			Expect(db.QueryRow(`SELECT rolconnlimit FROM pg_roles WHERE rolname = 'attruser'`).Scan(&connLimit)).To(Succeed())
+			Expect(connLimit).To(Equal(5))
+		})
+
+		It("should start the role's sessions with the declared settings", func() {
+			db, close := ConnectToDatabase(dbLookup, credSecretLookup)
+			defer close()
+
+			var statementTimeout, searchPath string
+			Expect(db.QueryRow("SHOW statement_timeout").Scan(&statementTimeout)).To(Succeed())
+			Expect(statementTimeout).To(Equal("30s"))
+			Expect(db.QueryRow("SHOW search_path").Scan(&searchPath)).To(Succeed())
+			Expect(searchPath).To(Equal("app, public"))
+		})
+
+		It("should reset the settings and limit when they are removed from the spec", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &latest)).To(Succeed())
+				latest.Spec.ConnectionLimit = nil
+				latest.Spec.StatementTimeout = nil
+				latest.Spec.SearchPath = nil
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				db, close := ConnectToDatabase(dbLookup, adminSecretLookup)
+				defer close()
+
+				var connLimit int
+				var hasConfig bool
+				g.Expect(db.QueryRow(`SELECT rolconnlimit, rolconfig IS NOT NULL FROM pg_roles WHERE rolname = 'attruser'`).Scan(&connLimit, &hasConfig)).To(Succeed())
+				g.Expect(connLimit).To(Equal(-1))
+				g.Expect(hasConfig).To(BeFalse())
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
 })
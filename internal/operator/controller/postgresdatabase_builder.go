@@ -3,19 +3,138 @@ package controller
 import (
 	"crypto/rand"
 	"fmt"
+	"maps"
 	"math/big"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/benjamin-wright/db-operator/internal/pgconfig"
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
 
+const (
+	// backupMountPath is where the backup destination is mounted inside backup pods.
+	backupMountPath = "/backups"
+
+	// awsCLIImage provides the aws CLI used to move artifacts to and from S3-compatible stores.
+	awsCLIImage = "amazon/aws-cli:2.22.0"
+
+	// postgresUpgradeImageRepo publishes images carrying the binaries of two
+	// PostgreSQL major versions, tagged "<old>-to-<new>", as pg_upgrade requires.
+	postgresUpgradeImageRepo = "tianon/postgres-upgrade"
+
+	// upgradeMountPath is where the primary's data volume is mounted inside the
+	// upgrade pod. The data directory itself is the "pgdata" sub-path.
+	upgradeMountPath = "/var/lib/postgresql/volume"
+
+	// backupJobNameLabel is set by the Job controller on every pod it creates.
+	backupJobNameLabel = "batch.kubernetes.io/job-name"
+
+	// postgresConfigMountPath is where the instance ConfigMap is mounted inside postgres pods.
+	postgresConfigMountPath = "/etc/postgresql"
+
+	// postgresHBAKey is the client authentication file inside the instance ConfigMap.
+	postgresHBAKey = "pg_hba.conf"
+
+	// postgresConfKey is the server configuration file inside the instance
+	// ConfigMap. It includes the data directory's own postgresql.conf first, so
+	// the settings initdb chose still apply unless overridden.
+	postgresConfKey = "postgresql.conf"
+
+	// postgresDataPath is where each pod's data directory is mounted.
+	postgresDataPath = "/var/lib/postgresql/data"
+
+	// restartChecksumAnnotation digests the restart-only parameters on the pod
+	// template, so changing one of them rolls the pods.
+	restartChecksumAnnotation = "checksum/restart-parameters"
+
+	// postgresPrimaryOrdinalKey holds the current primary's ordinal inside the
+	// instance ConfigMap, read by every pod at startup to choose its role.
+	postgresPrimaryOrdinalKey = "primary-ordinal"
+
+	// podNameLabel is set by the StatefulSet controller on every pod it creates.
+	podNameLabel = "statefulset.kubernetes.io/pod-name"
+
+	// postgresRoleLabel is set by the reconciler on every postgres pod to mark it
+	// as the primary or a standby. Services select on it to route traffic.
+	postgresRoleLabel = "db-operator.benjamin-wright.github.com/role"
+
+	postgresRolePrimary = "primary"
+	postgresRoleReplica = "replica"
+
+	// postgresTLSMountPath is where the server certificate Secret is mounted
+	// inside postgres pods and the CA bundle inside backup and restore pods.
+	postgresTLSMountPath = "/etc/postgresql-tls"
+
+	// postgresTLSPath holds the copies of the server certificate and key that
+	// the server reads. PostgreSQL refuses a key file readable by other users,
+	// which a Secret volume cannot guarantee, so the start script copies both
+	// here with the right owner and mode.
+	postgresTLSPath = "/var/lib/postgresql/tls"
+
+	// tlsChecksumAnnotation digests the server certificate on the pod template,
+	// so a renewed certificate rolls the pods onto it.
+	tlsChecksumAnnotation = "checksum/tls"
+
+	// pgbouncerImage runs the PgBouncer pooler.
+	pgbouncerImage = "edoburu/pgbouncer:v1.24.1-p1"
+
+	// pgbouncerConfigMountPath is where the pooler Secret is mounted inside
+	// PgBouncer pods.
+	pgbouncerConfigMountPath = "/etc/pgbouncer"
+
+	// pgbouncerConfKey and pgbouncerUserlistKey are the PgBouncer configuration
+	// and auth file inside the pooler Secret.
+	pgbouncerConfKey     = "pgbouncer.ini"
+	pgbouncerUserlistKey = "userlist.txt"
+
+	// poolerChecksumAnnotation digests pgbouncer.ini and the server
+	// certificate on the PgBouncer pod template, so a changed configuration
+	// rolls the pods. The user list is left out: pgbouncerStartScript reloads
+	// it in place.
+	poolerChecksumAnnotation = "checksum/pooler"
+)
+
+// pgbouncerStartScript runs PgBouncer and sends it SIGHUP whenever the
+// kubelet refreshes the auth file from the pooler Secret, so credentials that
+// are added, removed or rotated take effect without dropping the pooled
+// client connections a restart would.
+const pgbouncerStartScript = `set -eu
+users=` + pgbouncerConfigMountPath + `/` + pgbouncerUserlistKey + `
+pgbouncer ` + pgbouncerConfigMountPath + `/` + pgbouncerConfKey + ` &
+pid=$!
+trap 'kill -TERM "${pid}"' TERM INT
+sum="$(md5sum "${users}")"
+while kill -0 "${pid}" 2>/dev/null; do
+  sleep 5 &
+  wait $! || true
+  next="$(md5sum "${users}")"
+  if [ "${next}" != "${sum}" ]; then
+    sum="${next}"
+    kill -HUP "${pid}"
+  fi
+done
+wait "${pid}"
+`
+
+// certificateGVK identifies cert-manager Certificates. They are handled as
+// unstructured objects so the operator does not depend on cert-manager's API
+// module, and only needs its CRDs when spec.tls.issuerRef is used.
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
 // postgresDatabaseBuilder constructs the desired Kubernetes resources for a
 // PostgresDatabase instance. It owns the "how" — the shape of each resource —
 // leaving the reconciler free to own the "when".
@@ -44,6 +163,84 @@ func (b postgresDatabaseBuilder) desiredAdminSecret(pgdb *v1alpha1.PostgresDatab
 	return secret, nil
 }
 
+// desiredTLSCASecret holds the self-signed CA that issues the server
+// certificate when spec.tls names no issuer.
+func (b postgresDatabaseBuilder) desiredTLSCASecret(pgdb *v1alpha1.PostgresDatabase, certPEM, keyPEM []byte) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tlsCASecretName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForDatabase(pgdb, b.instanceName),
+		},
+		Data: map[string][]byte{
+			"ca.crt": certPEM,
+			"ca.key": keyPEM,
+		},
+	}
+	_ = controllerutil.SetControllerReference(pgdb, secret, b.scheme)
+	return secret
+}
+
+// desiredTLSSecret holds a server certificate issued by the self-signed CA,
+// laid out as cert-manager lays out the Secrets it issues.
+func (b postgresDatabaseBuilder) desiredTLSSecret(pgdb *v1alpha1.PostgresDatabase, certPEM, keyPEM, caPEM []byte) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tlsSecretName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForDatabase(pgdb, b.instanceName),
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+			"ca.crt":                caPEM,
+		},
+	}
+	_ = controllerutil.SetControllerReference(pgdb, secret, b.scheme)
+	return secret
+}
+
+// desiredCertificate asks cert-manager to issue the server certificate into
+// the TLS Secret from spec.tls.issuerRef. The Secret carries the instance
+// labels so that renewals are mapped back to the database. Callers must only
+// invoke this when spec.tls.issuerRef is set.
+func (b postgresDatabaseBuilder) desiredCertificate(pgdb *v1alpha1.PostgresDatabase) *unstructured.Unstructured {
+	issuer := pgdb.Spec.TLS.IssuerRef
+	dnsNames := []any{}
+	for _, name := range tlsDNSNames(pgdb) {
+		dnsNames = append(dnsNames, name)
+	}
+	secretLabels := map[string]any{}
+	for k, v := range labelsForDatabase(pgdb, b.instanceName) {
+		secretLabels[k] = v
+	}
+
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	cert.SetName(tlsSecretName(pgdb))
+	cert.SetNamespace(pgdb.Namespace)
+	cert.SetLabels(labelsForDatabase(pgdb, b.instanceName))
+	cert.Object["spec"] = map[string]any{
+		"secretName":     tlsSecretName(pgdb),
+		"secretTemplate": map[string]any{"labels": secretLabels},
+		"commonName":     dnsNames[0],
+		"dnsNames":       dnsNames,
+		"usages":         []any{"server auth", "digital signature", "key encipherment"},
+		"privateKey": map[string]any{
+			"algorithm":      "ECDSA",
+			"rotationPolicy": "Always",
+		},
+		"issuerRef": map[string]any{
+			"name":  issuer.Name,
+			"kind":  issuer.Kind,
+			"group": issuer.Group,
+		},
+	}
+	_ = controllerutil.SetControllerReference(pgdb, cert, b.scheme)
+	return cert
+}
+
 func (b postgresDatabaseBuilder) desiredService(pgdb *v1alpha1.PostgresDatabase) *corev1.Service {
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -54,6 +251,9 @@ func (b postgresDatabaseBuilder) desiredService(pgdb *v1alpha1.PostgresDatabase)
 		Spec: corev1.ServiceSpec{
 			ClusterIP: corev1.ClusterIPNone,
 			Selector:  labelsForDatabase(pgdb, b.instanceName),
+			// Unready pods keep their DNS names, so failover can tell a
+			// primary that is unready from one that is unreachable.
+			PublishNotReadyAddresses: true,
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "postgres",
@@ -64,12 +264,104 @@ func (b postgresDatabaseBuilder) desiredService(pgdb *v1alpha1.PostgresDatabase)
 			},
 		},
 	}
+	if pgdb.Spec.Metrics != nil {
+		svc.Spec.Ports = append(svc.Spec.Ports, metricsServicePort(postgresMetricsPort))
+	}
 	_ = controllerutil.SetControllerReference(pgdb, svc, b.scheme)
 	return svc
 }
 
-func (b postgresDatabaseBuilder) desiredStatefulSet(pgdb *v1alpha1.PostgresDatabase) *appsv1.StatefulSet {
-	replicas := int32(1)
+// desiredServiceMonitor scrapes the exporters through the headless Service,
+// which reaches every pod. The other Services carry the same labels but no
+// metrics port, so they add no targets.
+func (b postgresDatabaseBuilder) desiredServiceMonitor(pgdb *v1alpha1.PostgresDatabase) *unstructured.Unstructured {
+	return desiredServiceMonitor(pgdb, b.scheme, pgdb.Spec.Metrics, labelsForDatabase(pgdb, b.instanceName))
+}
+
+// desiredConfigMap holds configuration files shared by every pod of the instance.
+func (b postgresDatabaseBuilder) desiredConfigMap(pgdb *v1alpha1.PostgresDatabase) *corev1.ConfigMap {
+	config, _ := postgresConf(pgdb)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForDatabase(pgdb, b.instanceName),
+		},
+		Data: map[string]string{
+			postgresHBAKey:            postgresHBAFor(pgdb),
+			postgresConfKey:           config,
+			postgresPrimaryOrdinalKey: strconv.Itoa(int(pgdb.Status.PrimaryOrdinal)),
+		},
+	}
+	_ = controllerutil.SetControllerReference(pgdb, cm, b.scheme)
+	return cm
+}
+
+// desiredPrimaryService routes to the current primary pod only. Failover moves
+// its selector to the promoted pod, so clients keep a stable host name.
+func (b postgresDatabaseBuilder) desiredPrimaryService(pgdb *v1alpha1.PostgresDatabase) *corev1.Service {
+	selector := labelsForDatabase(pgdb, b.instanceName)
+	selector[podNameLabel] = podName(pgdb, pgdb.Status.PrimaryOrdinal)
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      primaryServiceName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForDatabase(pgdb, b.instanceName),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "postgres",
+					Port:       postgresPort,
+					TargetPort: intstr.FromInt32(postgresPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+	_ = controllerutil.SetControllerReference(pgdb, svc, b.scheme)
+	return svc
+}
+
+// desiredReadOnlyService load-balances across the standbys. A single-pod
+// instance has no standbys, so the Service falls back to the primary and
+// PGHOST_RO stays usable whatever the replica count.
+func (b postgresDatabaseBuilder) desiredReadOnlyService(pgdb *v1alpha1.PostgresDatabase) *corev1.Service {
+	selector := labelsForDatabase(pgdb, b.instanceName)
+	selector[postgresRoleLabel] = postgresRoleReplica
+	if postgresReplicas(pgdb) == 1 {
+		selector[postgresRoleLabel] = postgresRolePrimary
+	}
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      readOnlyServiceName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForDatabase(pgdb, b.instanceName),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "postgres",
+					Port:       postgresPort,
+					TargetPort: intstr.FromInt32(postgresPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+	_ = controllerutil.SetControllerReference(pgdb, svc, b.scheme)
+	return svc
+}
+
+// desiredStatefulSet never scales below the current primary, so lowering
+// spec.replicas after a failover cannot remove the primary pod. tlsChecksum
+// digests the current server certificate when TLS is enabled.
+func (b postgresDatabaseBuilder) desiredStatefulSet(pgdb *v1alpha1.PostgresDatabase, tlsChecksum string) *appsv1.StatefulSet {
+	replicas := max(postgresReplicas(pgdb), pgdb.Status.PrimaryOrdinal+1)
 
 	sts := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -80,18 +372,25 @@ func (b postgresDatabaseBuilder) desiredStatefulSet(pgdb *v1alpha1.PostgresDatab
 		Spec: appsv1.StatefulSetSpec{
 			Replicas:    &replicas,
 			ServiceName: serviceName(pgdb),
+			// Standbys wait for the primary before starting, and the primary need
+			// not be ordinal 0, so pods must not wait on their predecessors.
+			PodManagementPolicy: appsv1.ParallelPodManagement,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labelsForDatabase(pgdb, b.instanceName),
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: labelsForDatabase(pgdb, b.instanceName),
+					Annotations: map[string]string{
+						restartChecksumAnnotation: pgconfig.RestartChecksum(pgdb.Spec.Parameters),
+					},
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
 						{
-							Name:  "postgres",
-							Image: fmt.Sprintf("postgres:%s", pgdb.Spec.PostgresVersion),
+							Name:    "postgres",
+							Image:   postgresImage(pgdb),
+							Command: []string{"bash", "-c", postgresStartScript},
 							Ports: []corev1.ContainerPort{
 								{
 									Name:          "postgres",
@@ -115,13 +414,22 @@ func (b postgresDatabaseBuilder) desiredStatefulSet(pgdb *v1alpha1.PostgresDatab
 										},
 									},
 								},
+								{
+									Name:  "PRIMARY_HOST",
+									Value: postgresHost(pgdb),
+								},
 							},
 							VolumeMounts: []corev1.VolumeMount{
 								{
 									Name:      "data",
-									MountPath: "/var/lib/postgresql/data",
+									MountPath: postgresDataPath,
 									SubPath:   "pgdata",
 								},
+								{
+									Name:      "config",
+									MountPath: postgresConfigMountPath,
+									ReadOnly:  true,
+								},
 							},
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
@@ -151,6 +459,18 @@ func (b postgresDatabaseBuilder) desiredStatefulSet(pgdb *v1alpha1.PostgresDatab
 							},
 						},
 					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: configMapName(pgdb),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
@@ -162,6 +482,7 @@ func (b postgresDatabaseBuilder) desiredStatefulSet(pgdb *v1alpha1.PostgresDatab
 						AccessModes: []corev1.PersistentVolumeAccessMode{
 							corev1.ReadWriteOnce,
 						},
+						StorageClassName: storageClassName(pgdb.Spec.StorageClassName),
 						Resources: corev1.VolumeResourceRequirements{
 							Requests: corev1.ResourceList{
 								corev1.ResourceStorage: resource.MustParse(pgdb.Spec.StorageSize.String()),
@@ -172,10 +493,674 @@ func (b postgresDatabaseBuilder) desiredStatefulSet(pgdb *v1alpha1.PostgresDatab
 			},
 		},
 	}
+	if pgdb.Spec.TLS != nil {
+		applyServerTLS(&sts.Spec.Template, pgdb, tlsChecksum)
+	}
+	if pgdb.Spec.Metrics != nil {
+		sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, postgresExporterContainer(pgdb))
+	}
+	applyPodTemplate(&sts.Spec.Template, "postgres", pgdb.Spec.PodTemplate)
 	_ = controllerutil.SetControllerReference(pgdb, sts, b.scheme)
 	return sts
 }
 
+// postgresExporterContainer runs postgres_exporter against the server in the
+// same pod, as the pg_monitor member postgresExporterRole rather than the
+// superuser. pg_hba.conf trusts connections from the pod itself, so the
+// exporter needs no password and is unaffected by admin password rotation.
+func postgresExporterContainer(pgdb *v1alpha1.PostgresDatabase) corev1.Container {
+	container := metricsContainer(pgdb.Spec.Metrics, postgresExporterImage, postgresMetricsPort)
+	container.Env = []corev1.EnvVar{
+		{
+			Name: "DATA_SOURCE_NAME",
+			Value: fmt.Sprintf("postgresql://%s@127.0.0.1:%d/postgres?sslmode=disable",
+				postgresExporterRole, postgresPort),
+		},
+	}
+	return container
+}
+
+// desiredPoolerSecret holds the PgBouncer configuration and its auth file,
+// which lists the password of every user in users. It carries the instance
+// labels so PostgresCredentials are reconciled when the pooler is toggled.
+func (b postgresDatabaseBuilder) desiredPoolerSecret(pgdb *v1alpha1.PostgresDatabase, users map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      poolerName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForDatabase(pgdb, b.instanceName),
+		},
+		Data: map[string][]byte{
+			pgbouncerConfKey:     []byte(pgbouncerConfig(pgdb)),
+			pgbouncerUserlistKey: []byte(pgbouncerUserlist(users)),
+		},
+	}
+	_ = controllerutil.SetControllerReference(pgdb, secret, b.scheme)
+	return secret
+}
+
+// desiredPoolerDeployment runs PgBouncer against the primary Service.
+// checksum digests the pooler Secret and, with TLS, the server certificate.
+func (b postgresDatabaseBuilder) desiredPoolerDeployment(pgdb *v1alpha1.PostgresDatabase, checksum string) *appsv1.Deployment {
+	replicas := int32(1)
+
+	volumes := []corev1.Volume{
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: poolerName(pgdb)},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{Name: "config", MountPath: pgbouncerConfigMountPath, ReadOnly: true},
+	}
+	if pgdb.Spec.TLS != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: "tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: tlsSecretName(pgdb)},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "tls",
+			MountPath: postgresTLSMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      poolerName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForPooler(pgdb, b.instanceName),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labelsForPooler(pgdb, b.instanceName),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelsForPooler(pgdb, b.instanceName),
+					Annotations: map[string]string{
+						poolerChecksumAnnotation: checksum,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "pgbouncer",
+							Image:   pgbouncerImage,
+							Command: []string{"sh", "-c", pgbouncerStartScript},
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "pgbouncer",
+									ContainerPort: pgbouncerPort,
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							VolumeMounts: volumeMounts,
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(pgbouncerPort)},
+								},
+								InitialDelaySeconds: 2,
+								PeriodSeconds:       5,
+							},
+							LivenessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(pgbouncerPort)},
+								},
+								InitialDelaySeconds: 15,
+								PeriodSeconds:       10,
+							},
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	// The pooler takes the database's placement so it can run on the same
+	// tainted or dedicated nodes; resources and affinity are sized and written
+	// for the Postgres pods, so they are left out.
+	applyPodPlacement(&deploy.Spec.Template.Spec, pgdb.Spec.PodTemplate)
+	_ = controllerutil.SetControllerReference(pgdb, deploy, b.scheme)
+	return deploy
+}
+
+// desiredPoolerService load-balances across the PgBouncer pods.
+func (b postgresDatabaseBuilder) desiredPoolerService(pgdb *v1alpha1.PostgresDatabase) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      poolerName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForPooler(pgdb, b.instanceName),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labelsForPooler(pgdb, b.instanceName),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "pgbouncer",
+					Port:       pgbouncerPort,
+					TargetPort: intstr.FromInt32(pgbouncerPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+	_ = controllerutil.SetControllerReference(pgdb, svc, b.scheme)
+	return svc
+}
+
+// pgbouncerConfig renders pgbouncer.ini. Every database is routed to the
+// primary, and the auth file holds plain passwords, which PgBouncer uses both
+// to check clients with SCRAM and to log in to the server as them. With TLS,
+// clients must use TLS and PgBouncer verifies the server against the CA.
+func pgbouncerConfig(pgdb *v1alpha1.PostgresDatabase) string {
+	mode := pgdb.Spec.Pooler.Mode
+	if mode == "" {
+		mode = v1alpha1.PostgresPoolerModeTransaction
+	}
+	poolSize := pgdb.Spec.Pooler.PoolSize
+	if poolSize < 1 {
+		poolSize = 20
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "[databases]\n* = host=%s port=%d\n\n", postgresHost(pgdb), postgresPort)
+	buf.WriteString("[pgbouncer]\n")
+	buf.WriteString("listen_addr = 0.0.0.0\n")
+	fmt.Fprintf(&buf, "listen_port = %d\n", pgbouncerPort)
+	buf.WriteString("auth_type = scram-sha-256\n")
+	fmt.Fprintf(&buf, "auth_file = %s\n", path.Join(pgbouncerConfigMountPath, pgbouncerUserlistKey))
+	fmt.Fprintf(&buf, "pool_mode = %s\n", mode)
+	fmt.Fprintf(&buf, "default_pool_size = %d\n", poolSize)
+	buf.WriteString("ignore_startup_parameters = extra_float_digits\n")
+	if pgdb.Spec.TLS != nil {
+		buf.WriteString("client_tls_sslmode = require\n")
+		fmt.Fprintf(&buf, "client_tls_cert_file = %s\n", path.Join(postgresTLSMountPath, corev1.TLSCertKey))
+		fmt.Fprintf(&buf, "client_tls_key_file = %s\n", path.Join(postgresTLSMountPath, corev1.TLSPrivateKeyKey))
+		buf.WriteString("server_tls_sslmode = verify-full\n")
+		fmt.Fprintf(&buf, "server_tls_ca_file = %s\n", path.Join(postgresTLSMountPath, "ca.crt"))
+	}
+	return buf.String()
+}
+
+// pgbouncerUserlist renders the PgBouncer auth file, one quoted user and
+// password per line in name order. Double quotes are escaped by doubling.
+func pgbouncerUserlist(users map[string]string) string {
+	quote := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	var buf strings.Builder
+	for _, user := range slices.Sorted(maps.Keys(users)) {
+		fmt.Fprintf(&buf, "%s %s\n", quote(user), quote(users[user]))
+	}
+	return buf.String()
+}
+
+// applyServerTLS mounts the server certificate Secret into the postgres
+// container and points libpq at its CA, so pg_basebackup, the streaming
+// replication connection it configures, and pg_isready all verify the primary.
+func applyServerTLS(tmpl *corev1.PodTemplateSpec, pgdb *v1alpha1.PostgresDatabase, tlsChecksum string) {
+	tmpl.Annotations[tlsChecksumAnnotation] = tlsChecksum
+	tmpl.Spec.Volumes = append(tmpl.Spec.Volumes, corev1.Volume{
+		Name: "tls",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: tlsSecretName(pgdb)},
+		},
+	})
+	container := &tmpl.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "tls",
+		MountPath: postgresTLSMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, postgresClientTLSEnv()...)
+}
+
+// applyClientTLS makes every container in spec that connects through PGHOST
+// verify the server certificate against the instance's CA. It is a no-op
+// when TLS is disabled.
+func applyClientTLS(spec *corev1.PodSpec, pgdb *v1alpha1.PostgresDatabase) {
+	if pgdb.Spec.TLS == nil {
+		return
+	}
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: "tls-ca",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: tlsSecretName(pgdb),
+				Items:      []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+			},
+		},
+	})
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			if !hasEnv(containers[i].Env, "PGHOST") {
+				continue
+			}
+			containers[i].Env = append(containers[i].Env, postgresClientTLSEnv()...)
+			containers[i].VolumeMounts = append(containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      "tls-ca",
+				MountPath: postgresTLSMountPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+}
+
+// postgresClientTLSEnv configures libpq to require TLS and verify the server
+// against the CA mounted at postgresTLSMountPath.
+func postgresClientTLSEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "PGSSLMODE", Value: "verify-full"},
+		{Name: "PGSSLROOTCERT", Value: path.Join(postgresTLSMountPath, "ca.crt")},
+	}
+}
+
+func hasEnv(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsDNSNames returns the names the server certificate must cover: the
+// primary, read-only and pooler Services in every form a client may resolve
+// them by, and each pod behind the headless Service.
+func tlsDNSNames(pgdb *v1alpha1.PostgresDatabase) []string {
+	var names []string
+	services := []string{primaryServiceName(pgdb), readOnlyServiceName(pgdb)}
+	if pgdb.Spec.Pooler != nil {
+		services = append(services, poolerName(pgdb))
+	}
+	for _, svc := range services {
+		names = append(names,
+			svc,
+			svc+"."+pgdb.Namespace,
+			svc+"."+pgdb.Namespace+".svc",
+			svc+"."+pgdb.Namespace+".svc.cluster.local",
+		)
+	}
+	headless := serviceName(pgdb) + "." + pgdb.Namespace + ".svc.cluster.local"
+	return append(names, headless, "*."+headless)
+}
+
+// postgresHBA mirrors the postgres image's default client authentication rules
+// and additionally admits password-authenticated replication connections, which
+// standbys use to clone and stream from the primary.
+const postgresHBA = `local all all trust
+host all all 127.0.0.1/32 trust
+host all all ::1/128 trust
+local replication all trust
+host replication all all scram-sha-256
+host all all all scram-sha-256
+`
+
+// postgresHBATLS is postgresHBA with every connection from outside the pod
+// required to use TLS.
+const postgresHBATLS = `local all all trust
+host all all 127.0.0.1/32 trust
+host all all ::1/128 trust
+local replication all trust
+hostssl replication all all scram-sha-256
+hostssl all all all scram-sha-256
+`
+
+func postgresHBAFor(pgdb *v1alpha1.PostgresDatabase) string {
+	if pgdb.Spec.TLS != nil {
+		return postgresHBATLS
+	}
+	return postgresHBA
+}
+
+// postgresStartScript runs in every postgres pod. The pod whose ordinal matches
+// the ConfigMap's primary ordinal starts as the primary. Any other pod first
+// clones the primary with pg_basebackup -R, which writes standby.signal and a
+// primary_conninfo pointing at the primary Service, so the server starts as a
+// streaming hot standby and follows the Service through later failovers.
+//
+// When TLS is enabled the server certificate and key are first copied out of
+// the Secret volume, because the server only accepts a key file it owns.
+//
+// A non-primary pod whose data directory lacks standby.signal was the primary
+// before a failover; its timeline has diverged, so it is wiped and re-cloned.
+// The same happens when the data directory predates a major version upgrade,
+// which only rewrites the primary's data.
+// The TLS options pg_basebackup records in primary_conninfo are stripped on
+// every start, so the standby follows the pod's PGSSLMODE and PGSSLROOTCERT
+// when spec.tls is later enabled or removed. The recorded password is replaced
+// with the admin password the pod started with, so a standby that was down
+// while the password rotated can still stream.
+// The server reads its configuration from the ConfigMap rather than the data
+// directory, so parameter changes reach running pods without a restart.
+const postgresStartScript = `set -euo pipefail
+if [ -d /etc/postgresql-tls ]; then
+  install -d -o postgres -g postgres -m 700 /var/lib/postgresql/tls
+  install -o postgres -g postgres -m 600 /etc/postgresql-tls/tls.crt /etc/postgresql-tls/tls.key /var/lib/postgresql/tls/
+fi
+ordinal="${HOSTNAME##*-}"
+primary="$(cat /etc/postgresql/primary-ordinal)"
+if [ "${ordinal}" != "${primary}" ]; then
+  if [ -s "${PGDATA}/PG_VERSION" ] && { [ ! -f "${PGDATA}/standby.signal" ] || [ "$(cat "${PGDATA}/PG_VERSION")" != "${PG_MAJOR}" ]; }; then
+    find "${PGDATA}" -mindepth 1 -delete
+  fi
+  if [ ! -s "${PGDATA}/PG_VERSION" ]; then
+    until pg_isready -h "${PRIMARY_HOST}" -U postgres; do sleep 2; done
+    mkdir -p "${PGDATA}"
+    chown postgres:postgres "${PGDATA}"
+    chmod 700 "${PGDATA}"
+    PGPASSWORD="${POSTGRES_PASSWORD}" gosu postgres pg_basebackup -h "${PRIMARY_HOST}" -U postgres -D "${PGDATA}" -R -X stream
+  fi
+  sed -i -E -e "s/ (sslmode|sslrootcert)=[^ ']*//g" -e "s/ password=[^ ']*/ password=${POSTGRES_PASSWORD}/" "${PGDATA}/postgresql.auto.conf"
+fi
+exec docker-entrypoint.sh postgres -c config_file=/etc/postgresql/postgresql.conf -c hba_file=/etc/postgresql/pg_hba.conf
+`
+
+// desiredUpgradeJob constructs the Job that upgrades the primary's data
+// directory from status.postgresVersion to spec.postgresVersion. It must only
+// run while the StatefulSet is scaled to zero, since it mounts the primary's
+// ReadWriteOnce volume directly.
+func (b postgresDatabaseBuilder) desiredUpgradeJob(pgdb *v1alpha1.PostgresDatabase) *batchv1.Job {
+	from, to := pgdb.Status.PostgresVersion, pgdb.Spec.PostgresVersion
+	backoffLimit := int32(2)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      upgradeJobName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForUpgrade(pgdb, b.instanceName),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelsForUpgrade(pgdb, b.instanceName),
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "upgrade",
+							Image:   fmt.Sprintf("%s:%s-to-%s", postgresUpgradeImageRepo, from, to),
+							Command: []string{"bash", "-c", upgradeScript},
+							Env: []corev1.EnvVar{
+								{Name: "PG_OLD", Value: from},
+								{Name: "PG_NEW", Value: to},
+								{Name: "VOLUME", Value: upgradeMountPath},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "data", MountPath: upgradeMountPath},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: pvcName(statefulSetName(pgdb), int(pgdb.Status.PrimaryOrdinal)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	applyPodPlacement(&job.Spec.Template.Spec, pgdb.Spec.PodTemplate)
+	_ = controllerutil.SetControllerReference(pgdb, job, b.scheme)
+	return job
+}
+
+// upgradeScript initialises a new cluster beside the old one, upgrades into it
+// with pg_upgrade --link, then swaps it into place. The old cluster is started
+// and stopped first, because pg_upgrade refuses a data directory that was not
+// shut down cleanly, as happens when a pod outlives its termination grace
+// period. Every step is safe to rerun
+// after a failed attempt: an interrupted swap is completed, a data directory
+// already at the new version is left alone, and pg_upgrade's renaming of the
+// old pg_control in link mode is undone before retrying.
+const upgradeScript = `set -euo pipefail
+cd "${VOLUME}"
+if [ ! -d pgdata ] && [ -s pgdata-new/PG_VERSION ]; then
+  mv pgdata-new pgdata
+fi
+if [ "$(cat pgdata/PG_VERSION)" = "${PG_NEW}" ]; then
+  rm -rf pgdata-new pgdata-old
+  exit 0
+fi
+if [ -f pgdata/global/pg_control.old ]; then
+  mv pgdata/global/pg_control.old pgdata/global/pg_control
+fi
+old_ctl="/usr/lib/postgresql/${PG_OLD}/bin/pg_ctl"
+gosu postgres "${old_ctl}" -D "${VOLUME}/pgdata" -o "-c listen_addresses='' -c unix_socket_directories=/tmp" -w start
+gosu postgres "${old_ctl}" -D "${VOLUME}/pgdata" -m fast -w stop
+rm -rf pgdata-new pgdata-old
+install -d -o postgres -g postgres -m 700 pgdata-new
+gosu postgres "/usr/lib/postgresql/${PG_NEW}/bin/initdb" -D "${VOLUME}/pgdata-new" -U postgres
+(cd /tmp && gosu postgres "/usr/lib/postgresql/${PG_NEW}/bin/pg_upgrade" --link \
+  -b "/usr/lib/postgresql/${PG_OLD}/bin" -B "/usr/lib/postgresql/${PG_NEW}/bin" \
+  -d "${VOLUME}/pgdata" -D "${VOLUME}/pgdata-new")
+mv pgdata pgdata-old
+mv pgdata-new pgdata
+rm -rf pgdata-old
+`
+
+// desiredBackupCronJob constructs the CronJob that runs pg_dumpall against the
+// instance using the admin Secret. Callers must only invoke this when
+// pgdb.Spec.Backup is non-nil.
+//
+// PVC destinations dump straight onto the claim. S3 destinations dump into an
+// emptyDir from an init container, then upload and prune with the aws CLI,
+// because the postgres image ships no object-store client.
+func (b postgresDatabaseBuilder) desiredBackupCronJob(pgdb *v1alpha1.PostgresDatabase) *batchv1.CronJob {
+	backup := pgdb.Spec.Backup
+	backoffLimit := int32(2)
+	historyLimit := int32(3)
+
+	dumpEnv := []corev1.EnvVar{
+		{Name: "PGHOST", Value: postgresHost(pgdb)},
+		{Name: "PGPORT", Value: fmt.Sprintf("%d", postgresPort)},
+		adminSecretEnv("PGUSER", pgdb),
+		adminSecretEnv("PGPASSWORD", pgdb),
+		backupJobNameEnv(),
+		{Name: "BACKUP_DIR", Value: backupMountPath},
+	}
+
+	var podSpec corev1.PodSpec
+	if backup.PVC != nil {
+		podSpec = corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "backup",
+					Image:   postgresImage(pgdb),
+					Command: []string{"bash", "-c", pvcBackupScript},
+					Env: append(dumpEnv, corev1.EnvVar{
+						Name: "RETENTION", Value: fmt.Sprintf("%d", backupRetention(pgdb)),
+					}),
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "backups", MountPath: backupMountPath, SubPath: pgdb.Name},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "backups",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: backup.PVC.ClaimName,
+						},
+					},
+				},
+			},
+		}
+	} else {
+		podSpec = corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{
+				{
+					Name:         "dump",
+					Image:        postgresImage(pgdb),
+					Command:      []string{"bash", "-c", s3DumpScript},
+					Env:          dumpEnv,
+					VolumeMounts: []corev1.VolumeMount{{Name: "backups", MountPath: backupMountPath}},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "upload",
+					Image:   awsCLIImage,
+					Command: []string{"bash", "-c", s3UploadScript},
+					Env: []corev1.EnvVar{
+						backupJobNameEnv(),
+						{Name: "BACKUP_DIR", Value: backupMountPath},
+						{Name: "RETENTION", Value: fmt.Sprintf("%d", backupRetention(pgdb))},
+						{Name: "S3_ENDPOINT", Value: backup.S3.Endpoint},
+						{Name: "S3_BUCKET", Value: backup.S3.Bucket},
+						{Name: "S3_PREFIX", Value: backupS3Prefix(pgdb)},
+						{Name: "AWS_DEFAULT_REGION", Value: backup.S3.Region},
+						s3CredentialEnv("AWS_ACCESS_KEY_ID", backup.S3.CredentialsSecret),
+						s3CredentialEnv("AWS_SECRET_ACCESS_KEY", backup.S3.CredentialsSecret),
+					},
+					VolumeMounts: []corev1.VolumeMount{{Name: "backups", MountPath: backupMountPath}},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "backups", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		}
+	}
+
+	cj := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupCronJobName(pgdb),
+			Namespace: pgdb.Namespace,
+			Labels:    labelsForBackup(pgdb, b.instanceName),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   backup.Schedule,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &historyLimit,
+			FailedJobsHistoryLimit:     &historyLimit,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelsForBackup(pgdb, b.instanceName),
+				},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labelsForBackup(pgdb, b.instanceName),
+						},
+						Spec: podSpec,
+					},
+				},
+			},
+		},
+	}
+	applyClientTLS(&cj.Spec.JobTemplate.Spec.Template.Spec, pgdb)
+	applyPodPlacement(&cj.Spec.JobTemplate.Spec.Template.Spec, pgdb.Spec.PodTemplate)
+	_ = controllerutil.SetControllerReference(pgdb, cj, b.scheme)
+	return cj
+}
+
+// pvcBackupScript dumps to a temporary file first so that a failed dump never
+// leaves a truncated artifact that retention pruning would count as valid.
+// Both backup scripts leave role passwords out of the dump: the Secrets hold
+// the current ones, and a restore must not roll them back.
+const pvcBackupScript = `set -euo pipefail
+artifact="${BACKUP_DIR}/${JOB_NAME}.sql.gz"
+pg_dumpall --clean --if-exists --no-role-passwords | gzip > "${artifact}.tmp"
+mv "${artifact}.tmp" "${artifact}"
+ls -1t "${BACKUP_DIR}"/*.sql.gz | tail -n +$((RETENTION + 1)) | xargs -r rm --
+`
+
+const s3DumpScript = `set -euo pipefail
+pg_dumpall --clean --if-exists --no-role-passwords | gzip > "${BACKUP_DIR}/${JOB_NAME}.sql.gz"
+`
+
+// s3UploadScript prunes by listing order: aws s3 ls prefixes each line with the
+// upload timestamp, so a plain sort orders artifacts oldest first.
+const s3UploadScript = `set -euo pipefail
+aws s3 cp "${BACKUP_DIR}/${JOB_NAME}.sql.gz" "s3://${S3_BUCKET}/${S3_PREFIX}/${JOB_NAME}.sql.gz" --endpoint-url "${S3_ENDPOINT}"
+aws s3 ls "s3://${S3_BUCKET}/${S3_PREFIX}/" --endpoint-url "${S3_ENDPOINT}" \
+  | grep '\.sql\.gz$' | sort | awk '{print $4}' | head -n -"${RETENTION}" \
+  | while read -r key; do
+      aws s3 rm "s3://${S3_BUCKET}/${S3_PREFIX}/${key}" --endpoint-url "${S3_ENDPOINT}"
+    done
+`
+
+// adminSecretEnv sources key from the instance's admin Secret.
+func adminSecretEnv(key string, pgdb *v1alpha1.PostgresDatabase) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: key,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: adminSecretName(pgdb)},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// s3CredentialEnv sources key from a user-supplied object store credentials Secret.
+func s3CredentialEnv(key, secretName string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: key,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// backupJobNameEnv exposes the owning Job's name so each run writes a uniquely
+// named artifact that the reconciler can later reference in status.
+func backupJobNameEnv() corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: "JOB_NAME",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: fmt.Sprintf("metadata.labels['%s']", backupJobNameLabel),
+			},
+		},
+	}
+}
+
+// postgresConf renders the instance's postgresql.conf from spec.parameters,
+// along with the revision pods report once they have loaded it. Enabling TLS
+// adds the operator-managed ssl settings.
+func postgresConf(pgdb *v1alpha1.PostgresDatabase) (config, revision string) {
+	params := pgdb.Spec.Parameters
+	if pgdb.Spec.TLS != nil {
+		params = maps.Clone(params)
+		if params == nil {
+			params = map[string]string{}
+		}
+		params["ssl"] = "on"
+		params["ssl_cert_file"] = path.Join(postgresTLSPath, "tls.crt")
+		params["ssl_key_file"] = path.Join(postgresTLSPath, "tls.key")
+	}
+	return pgconfig.Build(path.Join(postgresDataPath, postgresConfKey), params)
+}
+
+func postgresImage(pgdb *v1alpha1.PostgresDatabase) string {
+	return fmt.Sprintf("postgres:%s", pgdb.Spec.PostgresVersion)
+}
+
+func upgradeJobName(pgdb *v1alpha1.PostgresDatabase) string {
+	return pgdb.Name + "-upgrade"
+}
+
 func statefulSetName(pgdb *v1alpha1.PostgresDatabase) string {
 	return pgdb.Name
 }
@@ -184,10 +1169,85 @@ func serviceName(pgdb *v1alpha1.PostgresDatabase) string {
 	return pgdb.Name
 }
 
+func primaryServiceName(pgdb *v1alpha1.PostgresDatabase) string {
+	return pgdb.Name + "-primary"
+}
+
+func podName(pgdb *v1alpha1.PostgresDatabase, ordinal int32) string {
+	return fmt.Sprintf("%s-%d", statefulSetName(pgdb), ordinal)
+}
+
+func readOnlyServiceName(pgdb *v1alpha1.PostgresDatabase) string {
+	return pgdb.Name + "-ro"
+}
+
+func configMapName(pgdb *v1alpha1.PostgresDatabase) string {
+	return pgdb.Name + "-config"
+}
+
+// poolerName names the PgBouncer Deployment, its Service, and the Secret
+// holding its configuration.
+func poolerName(pgdb *v1alpha1.PostgresDatabase) string {
+	return pgdb.Name + "-pooler"
+}
+
+// postgresReplicas returns spec.replicas, falling back to the CRD default for
+// objects created before the field existed.
+func postgresReplicas(pgdb *v1alpha1.PostgresDatabase) int32 {
+	if pgdb.Spec.Replicas < 1 {
+		return 1
+	}
+	return pgdb.Spec.Replicas
+}
+
+// maxPostgresReplicas mirrors the CRD's maximum for spec.replicas.
+const maxPostgresReplicas = 9
+
 func adminSecretName(pgdb *v1alpha1.PostgresDatabase) string {
 	return pgdb.Name + "-admin"
 }
 
+// tlsSecretName names the Secret holding the server certificate, its key, and
+// the CA bundle (tls.crt, tls.key, ca.crt), whether the operator or
+// cert-manager issues it.
+func tlsSecretName(pgdb *v1alpha1.PostgresDatabase) string {
+	return pgdb.Name + "-tls"
+}
+
+// tlsCASecretName names the Secret holding the self-signed CA (ca.crt, ca.key).
+func tlsCASecretName(pgdb *v1alpha1.PostgresDatabase) string {
+	return pgdb.Name + "-ca"
+}
+
+func backupCronJobName(pgdb *v1alpha1.PostgresDatabase) string {
+	return pgdb.Name + "-backup"
+}
+
+// backupRetention returns spec.backup.retention, falling back to the CRD
+// default for objects created before the default was applied.
+func backupRetention(pgdb *v1alpha1.PostgresDatabase) int32 {
+	if pgdb.Spec.Backup.Retention < 1 {
+		return 7
+	}
+	return pgdb.Spec.Backup.Retention
+}
+
+func backupS3Prefix(pgdb *v1alpha1.PostgresDatabase) string {
+	if pgdb.Spec.Backup.S3.Prefix != "" {
+		return pgdb.Spec.Backup.S3.Prefix
+	}
+	return pgdb.Name
+}
+
+// backupArtifact returns the artifact path a backup Job writes, relative to the
+// destination root.
+func backupArtifact(pgdb *v1alpha1.PostgresDatabase, jobName string) string {
+	if pgdb.Spec.Backup != nil && pgdb.Spec.Backup.S3 != nil {
+		return fmt.Sprintf("%s/%s.sql.gz", backupS3Prefix(pgdb), jobName)
+	}
+	return fmt.Sprintf("%s/%s.sql.gz", pgdb.Name, jobName)
+}
+
 func generatePassword(length int) (string, error) {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	result := make([]byte, length)
@@ -209,3 +1269,39 @@ func labelsForDatabase(pgdb *v1alpha1.PostgresDatabase, instanceName string) map
 		"db-operator.benjamin-wright.github.com/operator-instance": instanceName,
 	}
 }
+
+// labelsForBackup returns the label set for backup CronJobs, Jobs, and pods.
+// It deliberately differs from labelsForDatabase in app.kubernetes.io/name so
+// backup pods are never selected by the instance's StatefulSet or Service.
+func labelsForBackup(pgdb *v1alpha1.PostgresDatabase, instanceName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":                                   "postgres-backup",
+		"app.kubernetes.io/instance":                               pgdb.Name,
+		"app.kubernetes.io/managed-by":                             "db-operator",
+		"db-operator.benjamin-wright.github.com/operator-instance": instanceName,
+	}
+}
+
+// labelsForPooler returns the label set for the PgBouncer Deployment, Service
+// and pods. Like labelsForBackup, it differs from labelsForDatabase in
+// app.kubernetes.io/name so pooler pods are never selected as database pods.
+func labelsForPooler(pgdb *v1alpha1.PostgresDatabase, instanceName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":                                   "postgres-pooler",
+		"app.kubernetes.io/instance":                               pgdb.Name,
+		"app.kubernetes.io/managed-by":                             "db-operator",
+		"db-operator.benjamin-wright.github.com/operator-instance": instanceName,
+	}
+}
+
+// labelsForUpgrade returns the label set for the upgrade Job and its pod. Like
+// labelsForBackup, it differs from labelsForDatabase in app.kubernetes.io/name
+// so the upgrade pod is never selected by the instance's StatefulSet or Service.
+func labelsForUpgrade(pgdb *v1alpha1.PostgresDatabase, instanceName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":                                   "postgres-upgrade",
+		"app.kubernetes.io/instance":                               pgdb.Name,
+		"app.kubernetes.io/managed-by":                             "db-operator",
+		"db-operator.benjamin-wright.github.com/operator-instance": instanceName,
+	}
+}
@@ -2,9 +2,10 @@ package controller
 
 import (
 	"context"
-	"fmt"
 
+	batchv1 "k8s.io/api/batch/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -44,20 +45,30 @@ func (c *postgresDatabaseClient) delete(ctx context.Context, obj client.Object)
 	return nil
 }
 
-func (c *postgresDatabaseClient) updateStatus(ctx context.Context, obj client.Object) error {
-	return c.inner.Status().Update(ctx, obj)
+// deleteOrphan removes obj but leaves its dependents, such as a StatefulSet's
+// pods, running. A not-found error is treated as success.
+func (c *postgresDatabaseClient) deleteOrphan(ctx context.Context, obj client.Object) error {
+	err := c.inner.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
 }
 
-// isConflict reports whether err is a Kubernetes API conflict error.
-func isConflict(err error) bool { return apierrors.IsConflict(err) }
-
-// isForbidden reports whether err is a Kubernetes API forbidden error.
-func isForbidden(err error) bool { return apierrors.IsForbidden(err) }
+// deleteJob removes job with background propagation so its pods go with it.
+// A not-found error is treated as success.
+func (c *postgresDatabaseClient) deleteJob(ctx context.Context, job *batchv1.Job) error {
+	err := c.inner.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
 
-// isNotFound reports whether err is a Kubernetes API not-found error.
-func isNotFound(err error) bool { return apierrors.IsNotFound(err) }
+func (c *postgresDatabaseClient) list(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+	return c.inner.List(ctx, obj, opts...)
+}
 
-// pvcName returns the deterministic PVC name for the given StatefulSet ordinal.
-func pvcName(stsName string, ordinal int) string {
-	return fmt.Sprintf("data-%s-%d", stsName, ordinal)
+func (c *postgresDatabaseClient) updateStatus(ctx context.Context, obj client.Object) error {
+	return c.inner.Status().Update(ctx, obj)
 }
@@ -1,20 +1,39 @@
 package controller
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/benjamin-wright/db-operator/internal/certs"
+	"github.com/benjamin-wright/db-operator/internal/pgconfig"
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
 
@@ -25,6 +44,44 @@ const (
 
 	// postgresPort is the default port used by PostgreSQL.
 	postgresPort = 5432
+
+	// pgbouncerPort is the port the PgBouncer pooler listens on.
+	pgbouncerPort = 6432
+
+	// defaultFailoverGracePeriod is how long the primary pod may stay unready
+	// before a standby is promoted when spec.failover does not say. It
+	// comfortably covers an ordinary pod restart.
+	defaultFailoverGracePeriod = 30 * time.Second
+
+	// failoverConditionType records the most recent automatic promotion.
+	failoverConditionType = "Failover"
+
+	// parametersConditionType reports whether every ready pod runs with the
+	// current spec.parameters.
+	parametersConditionType = "ParametersApplied"
+
+	// extensionsConditionType reports whether the extensions declared in
+	// spec.databases are installed.
+	extensionsConditionType = "ExtensionsReady"
+
+	// extensionRetryInterval is how often extensions that could not be
+	// installed are retried.
+	extensionRetryInterval = 30 * time.Second
+
+	// logicalDatabaseResyncInterval is how often status.databases is refreshed
+	// when nothing else triggers a reconcile, so sizes stay current.
+	logicalDatabaseResyncInterval = 5 * time.Minute
+
+	// tlsCAValidity, tlsCertValidity, and tlsRenewBefore bound the lifetime of
+	// the self-signed CA and server certificates. Either is replaced once it
+	// comes within tlsRenewBefore of expiring.
+	tlsCAValidity   = 10 * 365 * 24 * time.Hour
+	tlsCertValidity = 365 * 24 * time.Hour
+	tlsRenewBefore  = 30 * 24 * time.Hour
+
+	// certManagerCertificateAnnotation is set by cert-manager on every Secret it
+	// issues into, naming the Certificate.
+	certManagerCertificateAnnotation = "cert-manager.io/certificate-name"
 )
 
 // errStatefulSetBeingRecreated is returned by reconcileStatefulSet when the
@@ -33,6 +90,24 @@ const (
 // transient Pending condition rather than a failure.
 var errStatefulSetBeingRecreated = errors.New("StatefulSet is being recreated for VolumeClaimTemplate update")
 
+// errCertificateNotReady is returned by reconcileTLS while the TLS Secret
+// lacks a certificate, key, or CA bundle, which happens until cert-manager
+// first issues it. The main reconciler treats this as Pending.
+var errCertificateNotReady = errors.New("server certificate has not been issued yet")
+
+// errUpgradeInProgress is returned by reconcileUpgrade while a major version
+// upgrade is still under way. The main reconciler sets phase=Upgrading and
+// leaves the StatefulSet scaled to zero.
+var errUpgradeInProgress = errors.New("major version upgrade in progress")
+
+// errDowngradeRefused and errUpgradeJobFailed are returned by reconcileUpgrade
+// when the requested version cannot be reached. Both need a spec change to
+// resolve, so the main reconciler marks the database Failed without retrying.
+var (
+	errDowngradeRefused = errors.New("downgrade refused")
+	errUpgradeJobFailed = errors.New("upgrade Job failed")
+)
+
 // PostgresDatabaseReconciler reconciles a PostgresDatabase object.
 // It orchestrates when resources are created, updated, or deleted, delegating
 // resource construction to the builder and cluster interactions to the client.
@@ -40,14 +115,29 @@ type PostgresDatabaseReconciler struct {
 	InstanceName string
 	client       postgresDatabaseClient
 	builder      postgresDatabaseBuilder
+	pgDB         PostgresManager
+	recorder     events.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=games-hub.io,resources=postgresdatabases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=games-hub.io,resources=postgresdatabases/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=games-hub.io,resources=postgresdatabases/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgrescredentials,verbs=get;list;watch
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresroles,verbs=get;list;watch
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresrestores,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile handles create/update/delete events for PostgresDatabase resources.
 func (r *PostgresDatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -85,12 +175,60 @@ func (r *PostgresDatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		reconcileErr = err
 		result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
 			"AdminSecretReconcileFailed", err.Error())
-	} else if err := r.reconcileService(ctx, &pgdb); err != nil {
+	} else if tlsChecksum, err := r.reconcileTLS(ctx, &pgdb); err != nil {
+		if errors.Is(err, errCertificateNotReady) {
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhasePending,
+				"CertificateNotReady", fmt.Sprintf("waiting for the server certificate in Secret %q", tlsSecretName(&pgdb)))
+		} else {
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"TLSReconcileFailed", err.Error())
+		}
+	} else if err := r.reconcileConfigMap(ctx, &pgdb); err != nil {
+		reconcileErr = err
+		result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+			"ConfigMapReconcileFailed", err.Error())
+	} else if err := r.reconcileService(ctx, r.builder.desiredService(&pgdb)); err != nil {
 		reconcileErr = err
 		result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
 			"ServiceReconcileFailed", err.Error())
+	} else if err := r.reconcileService(ctx, r.builder.desiredPrimaryService(&pgdb)); err != nil {
+		reconcileErr = err
+		result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+			"PrimaryServiceReconcileFailed", err.Error())
+	} else if err := r.reconcileService(ctx, r.builder.desiredReadOnlyService(&pgdb)); err != nil {
+		reconcileErr = err
+		result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+			"ReadOnlyServiceReconcileFailed", err.Error())
+	} else if err := r.reconcilePooler(ctx, &pgdb, tlsChecksum); err != nil {
+		reconcileErr = err
+		result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+			"PoolerReconcileFailed", err.Error())
+	} else if err := reconcileServiceMonitor(ctx, &r.client, client.ObjectKeyFromObject(&pgdb),
+		r.builder.desiredServiceMonitor(&pgdb)); err != nil {
+		reconcileErr = err
+		result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+			"ServiceMonitorReconcileFailed", err.Error())
+	} else if err := r.reconcileBackup(ctx, &pgdb); err != nil {
+		reconcileErr = err
+		result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+			"BackupReconcileFailed", err.Error())
+	} else if err := r.reconcileUpgrade(ctx, &pgdb); err != nil {
+		switch {
+		case errors.Is(err, errUpgradeInProgress):
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseUpgrading, "UpgradeInProgress",
+				fmt.Sprintf("upgrading data directory from PostgreSQL %s to %s", pgdb.Status.PostgresVersion, pgdb.Spec.PostgresVersion))
+		case errors.Is(err, errDowngradeRefused):
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed, "DowngradeRefused", err.Error())
+		case errors.Is(err, errUpgradeJobFailed):
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed, "UpgradeFailed", err.Error())
+		default:
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"UpgradeReconcileFailed", err.Error())
+		}
 	} else {
-		sts, err := r.reconcileStatefulSet(ctx, &pgdb)
+		sts, err := r.reconcileStatefulSet(ctx, &pgdb, tlsChecksum)
 		if err != nil {
 			if errors.Is(err, errStatefulSetBeingRecreated) {
 				result = r.setPhase(&pgdb, v1alpha1.DatabasePhasePending,
@@ -100,8 +238,52 @@ func (r *PostgresDatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Req
 				result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
 					"StatefulSetReconcileFailed", err.Error())
 			}
+		} else if fencing, err := r.reconcileFailover(ctx, &pgdb, sts); err != nil {
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"FailoverFailed", err.Error())
+		} else if fencing {
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhasePending, "FencingPrimary",
+				fmt.Sprintf("waiting for ordinal %d to stop before promoting ordinal %d",
+					pgdb.Status.Fencing.Ordinal, pgdb.Status.PrimaryOrdinal))
+		} else if err := r.reconcilePodRoles(ctx, &pgdb); err != nil {
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"PodRoleReconcileFailed", err.Error())
+		} else if applied, err := r.reconcileParameters(ctx, &pgdb); err != nil {
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"ParametersReconcileFailed", err.Error())
+		} else if resizing, err := reconcileStorageResizing(ctx, &r.client, &pgdb.Status.Conditions, pgdb.Generation,
+			postgresClaimKeys(&pgdb, *sts.Spec.Replicas)); err != nil {
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"StorageResizingReconcileFailed", err.Error())
+		} else if err := r.reconcileAdminPassword(ctx, &pgdb, sts); err != nil {
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"AdminPasswordRotationFailed", err.Error())
+		} else if err := r.reconcileExporterRole(ctx, &pgdb, sts); err != nil {
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"ExporterRoleReconcileFailed", err.Error())
+		} else if databasesResync, err := r.reconcileLogicalDatabases(ctx, &pgdb, sts); err != nil {
+			reconcileErr = err
+			result = r.setPhase(&pgdb, v1alpha1.DatabasePhaseFailed,
+				"LogicalDatabasesReconcileFailed", err.Error())
 		} else {
 			result = r.updatePhaseFromStatefulSet(&pgdb, sts)
+			requeueForAdminPasswordRotation(&result, &pgdb, pgdb.Spec.AdminPasswordRotation, pgdb.Status.AdminPasswordRotatedAt)
+			// Mounted ConfigMaps are refreshed by the kubelet some time after
+			// they change, so keep reloading until every pod has the new file.
+			// PVC status changes do not trigger a reconcile either, so poll
+			// until an expansion completes.
+			if (!applied || resizing) && result.RequeueAfter == 0 {
+				result.RequeueAfter = 5 * time.Second
+			}
+			if databasesResync > 0 && (result.RequeueAfter == 0 || result.RequeueAfter > databasesResync) {
+				result.RequeueAfter = databasesResync
+			}
 		}
 	}
 
@@ -148,15 +330,73 @@ func (r *PostgresDatabaseReconciler) reconcileDelete(ctx context.Context, pgdb *
 		return ctrl.Result{}, fmt.Errorf("deleting StatefulSet: %w", err)
 	}
 
-	// Delete the headless Service if it exists.
-	svc := &corev1.Service{
+	// Delete the headless, primary, and read-only Services if they exist.
+	for _, name := range []string{serviceName(pgdb), primaryServiceName(pgdb), readOnlyServiceName(pgdb)} {
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: pgdb.Namespace,
+			},
+		}
+		if err := r.client.delete(ctx, svc); err != nil {
+			return ctrl.Result{}, fmt.Errorf("deleting Service %s: %w", name, err)
+		}
+	}
+
+	// Delete the PgBouncer Deployment, Service and Secret if they exist. The
+	// Secret holds every credential's password.
+	for _, obj := range []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: poolerName(pgdb), Namespace: pgdb.Namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: poolerName(pgdb), Namespace: pgdb.Namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: poolerName(pgdb), Namespace: pgdb.Namespace}},
+	} {
+		if err := r.client.delete(ctx, obj); err != nil {
+			return ctrl.Result{}, fmt.Errorf("deleting pooler %T: %w", obj, err)
+		}
+	}
+
+	// Delete the configuration ConfigMap if it exists.
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName(pgdb),
+			Namespace: pgdb.Namespace,
+		},
+	}
+	if err := r.client.delete(ctx, cm); err != nil {
+		return ctrl.Result{}, fmt.Errorf("deleting ConfigMap: %w", err)
+	}
+
+	// Delete the ServiceMonitor if it exists.
+	if err := reconcileServiceMonitor(ctx, &r.client, client.ObjectKeyFromObject(pgdb), nil); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Delete the backup CronJob if it exists. Jobs it spawned are garbage-collected
+	// through their owner reference to the CronJob.
+	cj := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupCronJobName(pgdb),
+			Namespace: pgdb.Namespace,
+		},
+	}
+	if err := r.client.delete(ctx, cj); err != nil {
+		return ctrl.Result{}, fmt.Errorf("deleting backup CronJob: %w", err)
+	}
+
+	// Delete the upgrade Job if one is left over.
+	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceName(pgdb),
+			Name:      upgradeJobName(pgdb),
 			Namespace: pgdb.Namespace,
 		},
 	}
-	if err := r.client.delete(ctx, svc); err != nil {
-		return ctrl.Result{}, fmt.Errorf("deleting Service: %w", err)
+	if err := r.client.deleteJob(ctx, job); err != nil {
+		return ctrl.Result{}, fmt.Errorf("deleting upgrade Job: %w", err)
+	}
+
+	// Delete the TLS Secrets and any Certificate issuing them.
+	if err := r.deleteTLS(ctx, pgdb); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	// Delete the admin credentials Secret if it exists.
@@ -213,164 +453,1511 @@ func (r *PostgresDatabaseReconciler) reconcileAdminSecret(ctx context.Context, p
 	return nil
 }
 
-// reconcileService ensures the headless Service exists and is up-to-date.
-func (r *PostgresDatabaseReconciler) reconcileService(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) error {
-	desired := r.builder.desiredService(pgdb)
+// reconcileTLS ensures the server certificate Secret exists when spec.tls is
+// set, and removes the TLS objects the operator created when it is not. It
+// returns a digest of the current server certificate for the pod template, or
+// "" when TLS is disabled. errCertificateNotReady means cert-manager has not
+// issued the certificate yet.
+func (r *PostgresDatabaseReconciler) reconcileTLS(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) (string, error) {
+	if pgdb.Spec.TLS == nil {
+		return "", r.deleteTLS(ctx, pgdb)
+	}
+
+	var secret *corev1.Secret
+	var err error
+	if pgdb.Spec.TLS.IssuerRef != nil {
+		secret, err = r.reconcileCertificate(ctx, pgdb)
+	} else {
+		secret, err = r.reconcileSelfSignedTLS(ctx, pgdb)
+	}
+	if err != nil {
+		return "", err
+	}
+	if secret == nil || len(secret.Data[corev1.TLSCertKey]) == 0 ||
+		len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 || len(secret.Data["ca.crt"]) == 0 {
+		return "", errCertificateNotReady
+	}
+	return pgconfig.Checksum(string(secret.Data[corev1.TLSCertKey])), nil
+}
+
+// reconcileSelfSignedTLS issues the server certificate from the instance's
+// own CA, reissuing it when it nears expiry, no longer covers the Service
+// names, or was signed by a different CA. It returns the TLS Secret.
+func (r *PostgresDatabaseReconciler) reconcileSelfSignedTLS(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) (*corev1.Secret, error) {
+	now := time.Now()
+	caCert, caKey, err := r.reconcileTLSCA(ctx, pgdb, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing corev1.Secret
+	found, err := r.client.get(ctx, types.NamespacedName{Name: tlsSecretName(pgdb), Namespace: pgdb.Namespace}, &existing)
+	if err != nil {
+		return nil, fmt.Errorf("fetching TLS Secret: %w", err)
+	}
+	if found && existing.Annotations[certManagerCertificateAnnotation] != "" {
+		// Stop cert-manager from overwriting the Secret after switching away from an issuer.
+		if err := r.deleteCertificate(ctx, pgdb); err != nil {
+			return nil, err
+		}
+	}
+	if found && bytes.Equal(existing.Data["ca.crt"], caCert) &&
+		!certs.NeedsRenewal(existing.Data[corev1.TLSCertKey], caCert, tlsDNSNames(pgdb), now, tlsRenewBefore) {
+		return &existing, nil
+	}
+
+	certPEM, keyPEM, err := certs.IssueServer(caCert, caKey, tlsDNSNames(pgdb), now, tlsCertValidity)
+	if err != nil {
+		return nil, fmt.Errorf("issuing server certificate: %w", err)
+	}
+	desired := r.builder.desiredTLSSecret(pgdb, certPEM, keyPEM, caCert)
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("creating TLS Secret: %w", err)
+		}
+		return desired, nil
+	}
+	existing.Data = desired.Data
+	if err := r.client.update(ctx, &existing); err != nil {
+		return nil, fmt.Errorf("updating TLS Secret: %w", err)
+	}
+	return &existing, nil
+}
+
+// reconcileTLSCA returns the instance's self-signed CA, creating it on first
+// use and replacing it when it nears expiry.
+func (r *PostgresDatabaseReconciler) reconcileTLSCA(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, now time.Time) (certPEM, keyPEM []byte, err error) {
+	var existing corev1.Secret
+	found, err := r.client.get(ctx, types.NamespacedName{Name: tlsCASecretName(pgdb), Namespace: pgdb.Namespace}, &existing)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching CA Secret: %w", err)
+	}
+	if found && len(existing.Data["ca.key"]) > 0 {
+		if ca, err := certs.ParseCertificate(existing.Data["ca.crt"]); err == nil && now.Add(tlsRenewBefore).Before(ca.NotAfter) {
+			return existing.Data["ca.crt"], existing.Data["ca.key"], nil
+		}
+	}
+
+	certPEM, keyPEM, err = certs.NewCA(fmt.Sprintf("%s.%s db-operator CA", pgdb.Name, pgdb.Namespace), now, tlsCAValidity)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating CA: %w", err)
+	}
+	desired := r.builder.desiredTLSCASecret(pgdb, certPEM, keyPEM)
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return nil, nil, fmt.Errorf("creating CA Secret: %w", err)
+		}
+		return certPEM, keyPEM, nil
+	}
+	existing.Data = desired.Data
+	if err := r.client.update(ctx, &existing); err != nil {
+		return nil, nil, fmt.Errorf("updating CA Secret: %w", err)
+	}
+	return certPEM, keyPEM, nil
+}
+
+// reconcileCertificate ensures the cert-manager Certificate matches
+// spec.tls.issuerRef and returns the TLS Secret cert-manager issues into, or
+// nil while it has not been issued yet.
+func (r *PostgresDatabaseReconciler) reconcileCertificate(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) (*corev1.Secret, error) {
+	desired := r.builder.desiredCertificate(pgdb)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(certificateGVK)
+	found, err := r.client.get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("spec.tls.issuerRef requires cert-manager, which is not installed: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching Certificate: %w", err)
+	}
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("creating Certificate: %w", err)
+		}
+	} else if !equality.Semantic.DeepEqual(existing.Object["spec"], desired.Object["spec"]) {
+		existing.Object["spec"] = desired.Object["spec"]
+		if err := r.client.update(ctx, existing); err != nil {
+			return nil, fmt.Errorf("updating Certificate: %w", err)
+		}
+	}
+
+	// The self-signed CA is no longer needed once an issuer takes over.
+	var ca corev1.Secret
+	found, err = r.client.get(ctx, types.NamespacedName{Name: tlsCASecretName(pgdb), Namespace: pgdb.Namespace}, &ca)
+	if err != nil {
+		return nil, fmt.Errorf("fetching CA Secret: %w", err)
+	}
+	if found {
+		if err := r.client.delete(ctx, &ca); err != nil {
+			return nil, fmt.Errorf("deleting CA Secret: %w", err)
+		}
+	}
+
+	var secret corev1.Secret
+	found, err = r.client.get(ctx, types.NamespacedName{Name: tlsSecretName(pgdb), Namespace: pgdb.Namespace}, &secret)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// deleteTLS removes the TLS and CA Secrets, and the Certificate that issued
+// the TLS Secret if there was one, after spec.tls is cleared.
+func (r *PostgresDatabaseReconciler) deleteTLS(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) error {
+	for _, name := range []string{tlsSecretName(pgdb), tlsCASecretName(pgdb)} {
+		var secret corev1.Secret
+		found, err := r.client.get(ctx, types.NamespacedName{Name: name, Namespace: pgdb.Namespace}, &secret)
+		if err != nil {
+			return fmt.Errorf("fetching Secret %s: %w", name, err)
+		}
+		if !found {
+			continue
+		}
+		if secret.Annotations[certManagerCertificateAnnotation] != "" {
+			if err := r.deleteCertificate(ctx, pgdb); err != nil {
+				return err
+			}
+		}
+		if err := r.client.delete(ctx, &secret); err != nil {
+			return fmt.Errorf("deleting Secret %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// deleteCertificate removes the instance's cert-manager Certificate. It is a
+// no-op when the Certificate or cert-manager itself is absent.
+func (r *PostgresDatabaseReconciler) deleteCertificate(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) error {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	cert.SetName(tlsSecretName(pgdb))
+	cert.SetNamespace(pgdb.Namespace)
+	if err := r.client.delete(ctx, cert); err != nil && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("deleting Certificate: %w", err)
+	}
+	return nil
+}
+
+// reconcileConfigMap ensures the configuration ConfigMap exists and is up-to-date.
+func (r *PostgresDatabaseReconciler) reconcileConfigMap(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) error {
+	desired := r.builder.desiredConfigMap(pgdb)
+
+	var existing corev1.ConfigMap
+	found, err := r.client.get(ctx, client.ObjectKeyFromObject(desired), &existing)
+	if err != nil {
+		return fmt.Errorf("fetching ConfigMap: %w", err)
+	}
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return fmt.Errorf("creating ConfigMap: %w", err)
+		}
+		return nil
+	}
+
+	if !equality.Semantic.DeepEqual(existing.Data, desired.Data) {
+		existing.Data = desired.Data
+		if err := r.client.update(ctx, &existing); err != nil {
+			return fmt.Errorf("updating ConfigMap: %w", err)
+		}
+	}
+	return nil
+}
 
+// reconcileService ensures the desired Service (headless or read-only) exists
+// and is up-to-date.
+func (r *PostgresDatabaseReconciler) reconcileService(ctx context.Context, desired *corev1.Service) error {
 	var existing corev1.Service
 	found, err := r.client.get(ctx, client.ObjectKeyFromObject(desired), &existing)
 	if err != nil {
-		return fmt.Errorf("fetching Service: %w", err)
+		return fmt.Errorf("fetching Service %s: %w", desired.Name, err)
 	}
 	if !found {
 		if err := r.client.create(ctx, desired); err != nil {
-			return fmt.Errorf("creating Service: %w", err)
+			return fmt.Errorf("creating Service %s: %w", desired.Name, err)
 		}
 		return nil
 	}
 
 	// Update if spec has drifted.
 	if !equality.Semantic.DeepEqual(existing.Spec.Ports, desired.Spec.Ports) ||
-		!equality.Semantic.DeepEqual(existing.Spec.Selector, desired.Spec.Selector) {
+		!equality.Semantic.DeepEqual(existing.Spec.Selector, desired.Spec.Selector) ||
+		existing.Spec.PublishNotReadyAddresses != desired.Spec.PublishNotReadyAddresses {
 		existing.Spec.Ports = desired.Spec.Ports
 		existing.Spec.Selector = desired.Spec.Selector
+		existing.Spec.PublishNotReadyAddresses = desired.Spec.PublishNotReadyAddresses
 		if err := r.client.update(ctx, &existing); err != nil {
-			return fmt.Errorf("updating Service: %w", err)
+			return fmt.Errorf("updating Service %s: %w", desired.Name, err)
 		}
 	}
 
 	return nil
 }
 
-// reconcileStatefulSet ensures the StatefulSet exists and is up-to-date.
-// It returns the StatefulSet as returned by the API server (from create or
-// update) so callers can inspect the latest state without a redundant cache read.
-//
-// Because StatefulSet.spec.volumeClaimTemplates is immutable, a storage size
-// change requires deleting the StatefulSet and its PVC, then recreating both.
-// WARNING: this destroys all data in the database. During the deletion window
-// the method returns errStatefulSetBeingRecreated so the caller sets
-// phase=Pending rather than phase=Failed.
-func (r *PostgresDatabaseReconciler) reconcileStatefulSet(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) (*appsv1.StatefulSet, error) {
-	logger := log.FromContext(ctx)
-	desired := r.builder.desiredStatefulSet(pgdb)
+// reconcilePooler ensures the PgBouncer Secret, Deployment and Service match
+// spec.pooler, removing them when the pooler is disabled. The Deployment rolls
+// whenever the configuration or the server certificate changes; user list
+// changes are picked up by PgBouncer reloading its auth file.
+func (r *PostgresDatabaseReconciler) reconcilePooler(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, tlsChecksum string) error {
+	key := types.NamespacedName{Name: poolerName(pgdb), Namespace: pgdb.Namespace}
 
-	var existing appsv1.StatefulSet
-	found, err := r.client.get(ctx, client.ObjectKeyFromObject(desired), &existing)
+	var existingSecret corev1.Secret
+	secretFound, err := r.client.get(ctx, key, &existingSecret)
 	if err != nil {
-		return nil, fmt.Errorf("fetching StatefulSet: %w", err)
+		return fmt.Errorf("fetching pooler Secret: %w", err)
+	}
+
+	if pgdb.Spec.Pooler == nil {
+		for _, obj := range []client.Object{
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}},
+		} {
+			if err := r.client.delete(ctx, obj); err != nil {
+				return fmt.Errorf("deleting pooler %T: %w", obj, err)
+			}
+		}
+		if secretFound {
+			if err := r.client.delete(ctx, &existingSecret); err != nil {
+				return fmt.Errorf("deleting pooler Secret: %w", err)
+			}
+		}
+		return nil
+	}
+
+	users, err := r.poolerUsers(ctx, pgdb, parsePgbouncerUserlist(string(existingSecret.Data[pgbouncerUserlistKey])))
+	if err != nil {
+		return err
+	}
+	desiredSecret := r.builder.desiredPoolerSecret(pgdb, users)
+	if !secretFound {
+		if err := r.client.create(ctx, desiredSecret); err != nil {
+			return fmt.Errorf("creating pooler Secret: %w", err)
+		}
+	} else if !equality.Semantic.DeepEqual(existingSecret.Data, desiredSecret.Data) {
+		existingSecret.Data = desiredSecret.Data
+		if err := r.client.update(ctx, &existingSecret); err != nil {
+			return fmt.Errorf("updating pooler Secret: %w", err)
+		}
+	}
+
+	checksum := pgconfig.Checksum(string(desiredSecret.Data[pgbouncerConfKey]) + tlsChecksum)
+	desired := r.builder.desiredPoolerDeployment(pgdb, checksum)
+	var existing appsv1.Deployment
+	found, err := r.client.get(ctx, key, &existing)
+	if err != nil {
+		return fmt.Errorf("fetching pooler Deployment: %w", err)
 	}
 	if !found {
 		if err := r.client.create(ctx, desired); err != nil {
-			return nil, fmt.Errorf("creating StatefulSet: %w", err)
+			return fmt.Errorf("creating pooler Deployment: %w", err)
+		}
+	} else if !equality.Semantic.DeepEqual(existing.Spec.Template, desired.Spec.Template) {
+		existing.Spec.Template = desired.Spec.Template
+		if err := r.client.update(ctx, &existing); err != nil {
+			return fmt.Errorf("updating pooler Deployment: %w", err)
 		}
-		return desired, nil
 	}
 
-	// If the StatefulSet is already being deleted, wait for it to disappear
-	// before recreating.
-	if !existing.DeletionTimestamp.IsZero() {
-		return nil, errStatefulSetBeingRecreated
-	}
+	return r.reconcileService(ctx, r.builder.desiredPoolerService(pgdb))
+}
 
-	// volumeClaimTemplates is immutable. When storage has changed, delete both
-	// the StatefulSet and its PVC — this destroys all data — then requeue so
-	// the next reconcile recreates everything fresh with the new storage size.
-	if volumeClaimStorageChanged(&existing, desired) {
-		currentSize := existing.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
-		desiredSize := desired.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
-		logger.V(1).Info("WARNING: storageSize change requires destroying and recreating the database; all data will be lost",
-			"name", pgdb.Name, "namespace", pgdb.Namespace,
-			"currentSize", currentSize.String(),
-			"desiredSize", desiredSize.String())
+// poolerUsers collects the user and password from the Secret of every
+// PostgresCredential targeting pgdb. During a dual-credential rotation grace
+// period the previous role keeps the entry it has in previous, the current
+// user list, since its password is no longer in any Secret.
+func (r *PostgresDatabaseReconciler) poolerUsers(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, previous map[string]string) (map[string]string, error) {
+	var creds v1alpha1.PostgresCredentialList
+	if err := r.client.list(ctx, &creds, client.InNamespace(pgdb.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing PostgresCredentials: %w", err)
+	}
 
-		name := pvcName(pgdb.Name, 0)
-		pvc := &corev1.PersistentVolumeClaim{
-			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: pgdb.Namespace},
+	users := map[string]string{}
+	for _, cred := range creds.Items {
+		if cred.Spec.DatabaseRef != pgdb.Name {
+			continue
 		}
-		if err := r.client.delete(ctx, pvc); err != nil {
-			return nil, fmt.Errorf("deleting PVC %s for storage resize: %w", name, err)
+		var secret corev1.Secret
+		found, err := r.client.get(ctx, types.NamespacedName{Name: cred.Spec.SecretName, Namespace: cred.Namespace}, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("fetching credential Secret %q: %w", cred.Spec.SecretName, err)
 		}
-
-		if err := r.client.delete(ctx, &existing); err != nil {
-			return nil, fmt.Errorf("deleting StatefulSet for storage resize: %w", err)
+		if user, password := string(secret.Data["PGUSER"]), string(secret.Data["PGPASSWORD"]); found && user != "" && password != "" {
+			users[user] = password
+		}
+		if name := cred.Status.PreviousUsername; name != "" {
+			if password, ok := previous[name]; ok {
+				if _, current := users[name]; !current {
+					users[name] = password
+				}
+			}
 		}
-		return nil, errStatefulSetBeingRecreated
 	}
+	return users, nil
+}
 
-	// Update mutable fields only if the spec template has drifted.
-	if !equality.Semantic.DeepEqual(existing.Spec.Template, desired.Spec.Template) {
-		existing.Spec.Template = desired.Spec.Template
-		if err := r.client.update(ctx, &existing); err != nil {
-			return nil, fmt.Errorf("updating StatefulSet: %w", err)
+// parsePgbouncerUserlist reads back an auth file written by pgbouncerUserlist,
+// whose quoting matches CSV with a space as the separator.
+func parsePgbouncerUserlist(userlist string) map[string]string {
+	reader := csv.NewReader(strings.NewReader(userlist))
+	reader.Comma = ' '
+	reader.FieldsPerRecord = -1
+	records, _ := reader.ReadAll()
+
+	users := map[string]string{}
+	for _, record := range records {
+		if len(record) == 2 {
+			users[record[0]] = record[1]
 		}
 	}
-
-	return &existing, nil
+	return users
 }
 
-// volumeClaimStorageChanged returns true when the storage request in the first
-// VolumeClaimTemplate of the existing StatefulSet differs from the desired one.
-func volumeClaimStorageChanged(existing, desired *appsv1.StatefulSet) bool {
-	if len(existing.Spec.VolumeClaimTemplates) == 0 || len(desired.Spec.VolumeClaimTemplates) == 0 {
-		return false
+// databaseForSecret maps a Secret to the PostgresDatabase it belongs to: an
+// instance Secret to its database, and a PostgresCredential Secret to the
+// database its credential targets, so the pooler user list follows password
+// changes.
+func (r *PostgresDatabaseReconciler) databaseForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	switch labels["app.kubernetes.io/name"] {
+	case "postgres":
+		return mapInstanceLabel("postgres")(ctx, obj)
+	case "postgres-credential":
+	default:
+		return nil
+	}
+	var cred v1alpha1.PostgresCredential
+	found, err := r.client.get(ctx, types.NamespacedName{Name: labels["app.kubernetes.io/instance"], Namespace: obj.GetNamespace()}, &cred)
+	if err != nil || !found {
+		return nil
 	}
-	existingStorage := existing.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
-	desiredStorage := desired.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
-	return existingStorage.Cmp(desiredStorage) != 0
+	return mapDatabaseRef(ctx, &cred)
 }
 
-// updatePhaseFromStatefulSet checks the StatefulSet readiness and sets the
-// PostgresDatabase phase accordingly in memory. The caller passes the StatefulSet
-// returned by the most recent API server write so no redundant cache read is needed.
-func (r *PostgresDatabaseReconciler) updatePhaseFromStatefulSet(pgdb *v1alpha1.PostgresDatabase, sts *appsv1.StatefulSet) ctrl.Result {
-	if sts.Status.ReadyReplicas >= 1 && sts.Status.ReadyReplicas == *sts.Spec.Replicas {
-		return r.setPhase(pgdb, v1alpha1.DatabasePhaseReady,
-			"StatefulSetReady", "StatefulSet has all replicas ready")
+// reconcileBackup ensures the backup CronJob matches spec.backup, removing it
+// when backups are disabled, and records finished backup Jobs in status.
+func (r *PostgresDatabaseReconciler) reconcileBackup(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) error {
+	key := types.NamespacedName{Name: backupCronJobName(pgdb), Namespace: pgdb.Namespace}
+
+	var existing batchv1.CronJob
+	found, err := r.client.get(ctx, key, &existing)
+	if err != nil {
+		return fmt.Errorf("fetching backup CronJob: %w", err)
 	}
 
-	return r.setPhase(pgdb, v1alpha1.DatabasePhasePending,
-		"StatefulSetNotReady", "waiting for StatefulSet replicas to become ready")
+	if pgdb.Spec.Backup == nil {
+		if found {
+			if err := r.client.delete(ctx, &existing); err != nil {
+				return fmt.Errorf("deleting backup CronJob: %w", err)
+			}
+		}
+		return nil
+	}
+
+	desired := r.builder.desiredBackupCronJob(pgdb)
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return fmt.Errorf("creating backup CronJob: %w", err)
+		}
+	} else if existing.Spec.Schedule != desired.Spec.Schedule ||
+		!equality.Semantic.DeepEqual(existing.Spec.JobTemplate, desired.Spec.JobTemplate) {
+		existing.Spec.Schedule = desired.Spec.Schedule
+		existing.Spec.JobTemplate = desired.Spec.JobTemplate
+		if err := r.client.update(ctx, &existing); err != nil {
+			return fmt.Errorf("updating backup CronJob: %w", err)
+		}
+	}
+
+	return r.recordBackupHistory(ctx, pgdb)
 }
 
-// setPhase mutates the PostgresDatabase status phase and condition in memory.
-// The caller is responsible for persisting the status via a single
-// r.Status().Update() call. A requeue result is returned when the phase is Pending.
-func (r *PostgresDatabaseReconciler) setPhase(
-	pgdb *v1alpha1.PostgresDatabase,
-	phase v1alpha1.DatabasePhase,
-	reason, message string,
-) ctrl.Result {
-	pgdb.Status.Phase = phase
+// recordBackupHistory adds every finished backup Job not already present to
+// status.backupHistory. Records outlive the Jobs themselves, which the CronJob
+// prunes according to its history limits, and are trimmed to the retention count.
+func (r *PostgresDatabaseReconciler) recordBackupHistory(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) error {
+	var jobs batchv1.JobList
+	if err := r.client.list(ctx, &jobs,
+		client.InNamespace(pgdb.Namespace),
+		client.MatchingLabels(labelsForBackup(pgdb, r.InstanceName)),
+	); err != nil {
+		return fmt.Errorf("listing backup Jobs: %w", err)
+	}
 
-	conditionStatus := metav1.ConditionFalse
-	if phase == v1alpha1.DatabasePhaseReady {
-		conditionStatus = metav1.ConditionTrue
+	recorded := make(map[string]bool, len(pgdb.Status.BackupHistory))
+	for _, rec := range pgdb.Status.BackupHistory {
+		recorded[rec.JobName] = true
 	}
 
-	meta.SetStatusCondition(&pgdb.Status.Conditions, metav1.Condition{
-		Type:               "Ready",
-		Status:             conditionStatus,
-		Reason:             reason,
-		Message:            message,
-		ObservedGeneration: pgdb.Generation,
+	history := pgdb.Status.BackupHistory
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if recorded[job.Name] {
+			continue
+		}
+		result, finished := backupJobResult(job)
+		if !finished {
+			continue
+		}
+		history = append(history, v1alpha1.PostgresBackupRecord{
+			JobName:        job.Name,
+			Artifact:       backupArtifact(pgdb, job.Name),
+			Result:         result,
+			StartTime:      job.Status.StartTime,
+			CompletionTime: jobFinishTime(job),
+		})
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return backupRecordTime(history[i]).After(backupRecordTime(history[j]).Time)
 	})
+	if limit := int(backupRetention(pgdb)); len(history) > limit {
+		history = history[:limit]
+	}
+	pgdb.Status.BackupHistory = history
+	return nil
+}
 
-	if phase == v1alpha1.DatabasePhasePending {
-		return ctrl.Result{RequeueAfter: 5_000_000_000} // 5 seconds
+// backupJobResult reports whether job has finished and, if so, its outcome.
+func backupJobResult(job *batchv1.Job) (v1alpha1.BackupResult, bool) {
+	succeeded, finished := jobOutcome(job)
+	switch {
+	case !finished:
+		return "", false
+	case succeeded:
+		return v1alpha1.BackupResultSucceeded, true
 	}
+	return v1alpha1.BackupResultFailed, true
+}
 
-	return ctrl.Result{}
+// jobOutcome reports whether job has finished and, if so, whether it succeeded.
+func jobOutcome(job *batchv1.Job) (succeeded, finished bool) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return true, true
+		case batchv1.JobFailed:
+			return false, true
+		}
+	}
+	return false, false
 }
 
-// SetupWithManager registers the PostgresDatabaseReconciler with the controller manager.
-func (r *PostgresDatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// jobFinishTime returns when job finished. Failed Jobs never set
+// status.completionTime, so the Failed condition's transition time is used instead.
+func jobFinishTime(job *batchv1.Job) *metav1.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			t := cond.LastTransitionTime
+			return &t
+		}
+	}
+	return nil
+}
+
+func backupRecordTime(rec v1alpha1.PostgresBackupRecord) metav1.Time {
+	if rec.CompletionTime != nil {
+		return *rec.CompletionTime
+	}
+	if rec.StartTime != nil {
+		return *rec.StartTime
+	}
+	return metav1.Time{}
+}
+
+// reconcileUpgrade brings the data directory up to spec.postgresVersion before
+// the StatefulSet is allowed to run the new binaries, which cannot start
+// against an older major version's data. It scales the StatefulSet to zero,
+// runs pg_upgrade against the primary's volume in a Job, and records the new
+// version in status once the Job succeeds. Standbys are not upgraded; they
+// re-clone from the upgraded primary when they next start.
+//
+// status.postgresVersion is seeded from the running StatefulSet's image, or from
+// the spec for a new instance, so instances created before the field existed
+// are not mistaken for fresh ones.
+func (r *PostgresDatabaseReconciler) reconcileUpgrade(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) error {
+	logger := log.FromContext(ctx)
+
+	var sts appsv1.StatefulSet
+	stsFound, err := r.client.get(ctx, types.NamespacedName{Name: statefulSetName(pgdb), Namespace: pgdb.Namespace}, &sts)
+	if err != nil {
+		return fmt.Errorf("fetching StatefulSet: %w", err)
+	}
+	if pgdb.Status.PostgresVersion == "" {
+		pgdb.Status.PostgresVersion = pgdb.Spec.PostgresVersion
+		if stsFound {
+			if version := deployedPostgresVersion(&sts); version != "" {
+				pgdb.Status.PostgresVersion = version
+			}
+		}
+	}
+
+	from, to := pgdb.Status.PostgresVersion, pgdb.Spec.PostgresVersion
+	cmp, err := compareMajorVersions(to, from)
+	if err != nil {
+		return err
+	}
+	if cmp < 0 {
+		return fmt.Errorf("%w: data directory is PostgreSQL %s and cannot be downgraded to %s", errDowngradeRefused, from, to)
+	}
+
+	var job batchv1.Job
+	jobFound, err := r.client.get(ctx, types.NamespacedName{Name: upgradeJobName(pgdb), Namespace: pgdb.Namespace}, &job)
+	if err != nil {
+		return fmt.Errorf("fetching upgrade Job: %w", err)
+	}
+
+	if cmp == 0 {
+		// A leftover Job belongs to a completed upgrade, or to a failed one the
+		// user has backed out of by restoring the previous version.
+		if jobFound {
+			if err := r.client.deleteJob(ctx, &job); err != nil {
+				return fmt.Errorf("deleting upgrade Job: %w", err)
+			}
+		}
+		return nil
+	}
+
+	// Stop every pod before touching the primary's volume.
+	if stsFound && (sts.Spec.Replicas == nil || *sts.Spec.Replicas != 0) {
+		logger.Info("scaling down for major version upgrade", "from", from, "to", to)
+		zero := int32(0)
+		sts.Spec.Replicas = &zero
+		if err := r.client.update(ctx, &sts); err != nil {
+			return fmt.Errorf("scaling StatefulSet down for upgrade: %w", err)
+		}
+		return errUpgradeInProgress
+	}
+	var pods corev1.PodList
+	if err := r.client.list(ctx, &pods,
+		client.InNamespace(pgdb.Namespace),
+		client.MatchingLabels(labelsForDatabase(pgdb, r.InstanceName)),
+	); err != nil {
+		return fmt.Errorf("listing pods: %w", err)
+	}
+	if len(pods.Items) > 0 {
+		return errUpgradeInProgress
+	}
+
+	if !jobFound {
+		if err := r.client.create(ctx, r.builder.desiredUpgradeJob(pgdb)); err != nil {
+			return fmt.Errorf("creating upgrade Job: %w", err)
+		}
+		return errUpgradeInProgress
+	}
+
+	succeeded, finished := jobOutcome(&job)
+	if !finished {
+		return errUpgradeInProgress
+	}
+	if !succeeded {
+		return fmt.Errorf("%w: upgrade Job %q from PostgreSQL %s to %s did not succeed; restore spec.postgresVersion to %s to roll back",
+			errUpgradeJobFailed, job.Name, from, to, from)
+	}
+
+	logger.Info("major version upgrade complete", "from", from, "to", to)
+	pgdb.Status.PostgresVersion = to
+	if err := r.client.deleteJob(ctx, &job); err != nil {
+		return fmt.Errorf("deleting upgrade Job: %w", err)
+	}
+	return nil
+}
+
+// deployedPostgresVersion returns the major version in the postgres container
+// image of sts, or "" when the image carries no recognisable tag.
+func deployedPostgresVersion(sts *appsv1.StatefulSet) string {
+	for _, c := range sts.Spec.Template.Spec.Containers {
+		if c.Name != "postgres" {
+			continue
+		}
+		if idx := strings.LastIndex(c.Image, ":"); idx >= 0 {
+			return c.Image[idx+1:]
+		}
+	}
+	return ""
+}
+
+// compareMajorVersions compares two PostgreSQL major versions numerically,
+// returning -1, 0, or 1 as a is older than, equal to, or newer than b.
+func compareMajorVersions(a, b string) (int, error) {
+	av, err := strconv.Atoi(a)
+	if err != nil {
+		return 0, fmt.Errorf("parsing PostgreSQL version %q: %w", a, err)
+	}
+	bv, err := strconv.Atoi(b)
+	if err != nil {
+		return 0, fmt.Errorf("parsing PostgreSQL version %q: %w", b, err)
+	}
+	switch {
+	case av < bv:
+		return -1, nil
+	case av > bv:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// reconcileStatefulSet ensures the StatefulSet exists and is up-to-date.
+// It returns the StatefulSet as returned by the API server (from create or
+// update) so callers can inspect the latest state without a redundant cache read.
+//
+// StatefulSet.spec.volumeClaimTemplates is immutable. When storage grows and
+// the claims' StorageClass allows volume expansion, the existing PVCs are
+// expanded in place and the StatefulSet is deleted without its pods, so it is
+// recreated with the new template while postgres keeps running. Any other
+// storage change requires deleting the StatefulSet and its PVCs, then
+// recreating both. WARNING: this destroys all data in the database. During
+// the deletion window the method returns errStatefulSetBeingRecreated so the
+// caller sets phase=Pending rather than phase=Failed.
+func (r *PostgresDatabaseReconciler) reconcileStatefulSet(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, tlsChecksum string) (*appsv1.StatefulSet, error) {
+	logger := log.FromContext(ctx)
+	desired := r.builder.desiredStatefulSet(pgdb, tlsChecksum)
+
+	var existing appsv1.StatefulSet
+	found, err := r.client.get(ctx, client.ObjectKeyFromObject(desired), &existing)
+	if err != nil {
+		return nil, fmt.Errorf("fetching StatefulSet: %w", err)
+	}
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("creating StatefulSet: %w", err)
+		}
+		return desired, nil
+	}
+
+	// If the StatefulSet is already being deleted, wait for it to disappear
+	// before recreating.
+	if !existing.DeletionTimestamp.IsZero() {
+		return nil, errStatefulSetBeingRecreated
+	}
+
+	if volumeClaimStorageChanged(&existing, desired) {
+		currentSize := existing.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+		desiredSize := desired.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+		claims := postgresClaimKeys(pgdb, maxPostgresReplicas)
+
+		// Growth on an expandable StorageClass: expand the live PVCs, then
+		// orphan the pods so the StatefulSet can be recreated with the new
+		// template and adopt them again without a restart.
+		if volumeClaimStorageGrown(&existing, desired) {
+			expandable, err := volumeClaimsExpandable(ctx, &r.client, claims)
+			if err != nil {
+				return nil, err
+			}
+			if expandable {
+				logger.Info("expanding PVCs in place",
+					"currentSize", currentSize.String(), "desiredSize", desiredSize.String())
+				if err := expandVolumeClaims(ctx, &r.client, claims, desiredSize); err != nil {
+					return nil, err
+				}
+				if err := r.client.deleteOrphan(ctx, &existing); err != nil {
+					return nil, fmt.Errorf("deleting StatefulSet for storage resize: %w", err)
+				}
+				return nil, errStatefulSetBeingRecreated
+			}
+			// Recreating the volumes would lose their data for a change that
+			// only asked for more space, so the growth is left undone.
+			setStorageResizeSkipped(&pgdb.Status.Conditions, pgdb.Generation, "ExpansionNotSupported",
+				"the StorageClass does not allow volume expansion; the volumes keep their current size")
+		} else {
+			setStorageResizeSkipped(&pgdb.Status.Conditions, pgdb.Generation, "VolumesRecreated",
+				"storage can only grow in place; the volumes were recreated at the smaller size")
+
+			// Shrinking needs new volumes: delete both the StatefulSet and its
+			// PVCs — this destroys all data — then requeue so the next reconcile
+			// recreates everything fresh with the new storage.
+			logger.V(1).Info("WARNING: storage change requires destroying and recreating the database; all data will be lost",
+				"name", pgdb.Name, "namespace", pgdb.Namespace,
+				"currentSize", currentSize.String(),
+				"desiredSize", desiredSize.String())
+
+			for _, key := range claims {
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				}
+				if err := r.client.delete(ctx, pvc); err != nil {
+					return nil, fmt.Errorf("deleting PVC %s for storage resize: %w", key.Name, err)
+				}
+			}
+
+			if err := r.client.delete(ctx, &existing); err != nil {
+				return nil, fmt.Errorf("deleting StatefulSet for storage resize: %w", err)
+			}
+
+			// The recreated cluster is initialised from scratch by ordinal 0.
+			pgdb.Status.PrimaryOrdinal = 0
+			pgdb.Status.PostgresVersion = pgdb.Spec.PostgresVersion
+			return nil, errStatefulSetBeingRecreated
+		}
+	}
+
+	// Update mutable fields only if the replica count or spec template has drifted.
+	// Scaling down leaves the PVCs of removed standbys in place, so scaling back
+	// up resumes streaming from their existing data.
+	if *existing.Spec.Replicas != *desired.Spec.Replicas ||
+		!equality.Semantic.DeepEqual(existing.Spec.Template, desired.Spec.Template) {
+		existing.Spec.Replicas = desired.Spec.Replicas
+		existing.Spec.Template = desired.Spec.Template
+		if err := r.client.update(ctx, &existing); err != nil {
+			return nil, fmt.Errorf("updating StatefulSet: %w", err)
+		}
+	}
+
+	return &existing, nil
+}
+
+// reconcileFailover promotes the most caught-up standby once the primary pod
+// has been unready for longer than the failover grace period and the operator
+// cannot reach it either. Nothing happens while no standby is ready, since
+// there is nothing to promote, or while the StatefulSet is rolling out a new
+// revision, which restarts the primary on purpose. It reports whether a
+// failover is waiting for the former primary to stop.
+//
+// The former primary is fenced before anything is promoted: the new primary
+// ordinal is persisted and written to the ConfigMap first, so a replacement
+// pod reads it and rejoins as a standby rather than starting a second primary,
+// and the former primary pod is then deleted. The standby is only promoted
+// once that pod is gone or the kubelet reports its containers stopped. A pod
+// on a node that no longer reports status is never seen to stop, so the
+// failover waits until the pod is force-deleted or the node removed.
+func (r *PostgresDatabaseReconciler) reconcileFailover(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, sts *appsv1.StatefulSet) (bool, error) {
+	if postgresReplicas(pgdb) < 2 {
+		return false, nil
+	}
+
+	var pods corev1.PodList
+	if err := r.client.list(ctx, &pods,
+		client.InNamespace(pgdb.Namespace),
+		client.MatchingLabels(labelsForDatabase(pgdb, r.InstanceName)),
+	); err != nil {
+		return false, fmt.Errorf("listing pods: %w", err)
+	}
+
+	if pgdb.Status.Fencing == nil {
+		started, err := r.fencePrimary(ctx, pgdb, sts, pods.Items)
+		if err != nil || !started {
+			return false, err
+		}
+	}
+	return r.promoteFencedStandby(ctx, pgdb, pods.Items)
+}
+
+// fencePrimary starts a failover when the primary has failed: it picks the
+// standby to promote, records it as the primary with the fenced pod in
+// status, repoints the ConfigMap and primary Service at it, and deletes the
+// former primary pod. It reports whether it started one.
+func (r *PostgresDatabaseReconciler) fencePrimary(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, sts *appsv1.StatefulSet, pods []corev1.Pod) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	var primary *corev1.Pod
+	var standbys []*corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if podOrdinal(pod) == int(pgdb.Status.PrimaryOrdinal) {
+			primary = pod
+		} else if podOrdinal(pod) >= 0 && pod.DeletionTimestamp.IsZero() && podReady(pod) {
+			standbys = append(standbys, pod)
+		}
+	}
+
+	// A missing primary pod is being recreated by the StatefulSet controller.
+	if primary == nil || len(standbys) == 0 || statefulSetRolling(sts) {
+		return false, nil
+	}
+	unreadySince, unready := podUnreadySince(primary)
+	if !unready || time.Since(unreadySince) < failoverGracePeriod(pgdb) {
+		return false, nil
+	}
+
+	conn, err := r.adminConn(ctx, pgdb)
+	if err != nil {
+		return false, err
+	}
+
+	// An unready primary that still accepts connections is struggling rather
+	// than gone, and promoting beside it would leave two primaries.
+	previous := pgdb.Status.PrimaryOrdinal
+	if _, err := r.pgDB.WALPosition(conn.at(postgresPodHost(pgdb, previous))); err == nil {
+		logger.Info("primary unready but still reachable; not failing over", "primary", primary.Name)
+		return false, nil
+	}
+
+	var candidate *corev1.Pod
+	var candidatePosition int64 = -1
+	for _, pod := range standbys {
+		position, err := r.pgDB.WALPosition(conn.at(postgresPodHost(pgdb, int32(podOrdinal(pod)))))
+		if err != nil {
+			logger.Info("skipping unreachable standby", "pod", pod.Name, "error", err.Error())
+			continue
+		}
+		if position > candidatePosition {
+			candidate, candidatePosition = pod, position
+		}
+	}
+	if candidate == nil {
+		return false, nil
+	}
+
+	promoted := int32(podOrdinal(candidate))
+	logger.Info("primary unready and unreachable; fencing it before promoting a standby",
+		"primary", primary.Name, "unreadySince", unreadySince, "standby", candidate.Name)
+
+	// The new primary ordinal is persisted before anything is repointed at it:
+	// the ConfigMap and primary Service are derived from status, so a lost
+	// status write would otherwise revert them on the next reconcile.
+	pgdb.Status.PrimaryOrdinal = promoted
+	pgdb.Status.Fencing = &v1alpha1.PostgresFencingStatus{Ordinal: previous, PodUID: string(primary.UID)}
+	meta.RemoveStatusCondition(&pgdb.Status.Conditions, failoverConditionType)
+	meta.SetStatusCondition(&pgdb.Status.Conditions, metav1.Condition{
+		Type:   failoverConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: "FencingPrimary",
+		Message: fmt.Sprintf("waiting for ordinal %d, unready since %s, to stop before promoting ordinal %d",
+			previous, unreadySince.UTC().Format(time.RFC3339), promoted),
+		ObservedGeneration: pgdb.Generation,
+	})
+	if err := r.client.updateStatus(ctx, pgdb); err != nil {
+		return false, fmt.Errorf("recording failover: %w", err)
+	}
+
+	if err := r.reconcileConfigMap(ctx, pgdb); err != nil {
+		return false, err
+	}
+	if err := r.reconcileService(ctx, r.builder.desiredPrimaryService(pgdb)); err != nil {
+		return false, err
+	}
+	if err := r.client.delete(ctx, primary); err != nil {
+		return false, fmt.Errorf("deleting former primary pod %s: %w", primary.Name, err)
+	}
+	return true, nil
+}
+
+// promoteFencedStandby promotes the standby at status.primaryOrdinal once the
+// former primary pod recorded in status.fencing has stopped, and reports
+// whether it is still waiting for that.
+func (r *PostgresDatabaseReconciler) promoteFencedStandby(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, pods []corev1.Pod) (bool, error) {
+	fencing := pgdb.Status.Fencing
+	for i := range pods {
+		pod := &pods[i]
+		if podOrdinal(pod) != int(fencing.Ordinal) || string(pod.UID) != fencing.PodUID {
+			continue
+		}
+		if pod.DeletionTimestamp.IsZero() {
+			if err := r.client.delete(ctx, pod); err != nil {
+				return true, fmt.Errorf("deleting former primary pod %s: %w", pod.Name, err)
+			}
+			return true, nil
+		}
+		if !podContainersStopped(pod) {
+			return true, nil
+		}
+	}
+
+	conn, err := r.adminConn(ctx, pgdb)
+	if err != nil {
+		return true, err
+	}
+	promoted := pgdb.Status.PrimaryOrdinal
+	if err := r.pgDB.Promote(conn.at(postgresPodHost(pgdb, promoted))); err != nil {
+		return true, fmt.Errorf("promoting ordinal %d: %w", promoted, err)
+	}
+	log.FromContext(ctx).Info("promoted standby", "ordinal", promoted, "fenced", fencing.Ordinal)
+
+	pgdb.Status.Fencing = nil
+	// Remove first so LastTransitionTime records this failover, not the first one.
+	meta.RemoveStatusCondition(&pgdb.Status.Conditions, failoverConditionType)
+	meta.SetStatusCondition(&pgdb.Status.Conditions, metav1.Condition{
+		Type:               failoverConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             "PrimaryPromoted",
+		Message:            fmt.Sprintf("promoted ordinal %d to primary after fencing ordinal %d", promoted, fencing.Ordinal),
+		ObservedGeneration: pgdb.Generation,
+	})
+	return false, nil
+}
+
+// failoverGracePeriod returns how long the primary pod of pgdb may stay
+// unready before a standby is promoted.
+func failoverGracePeriod(pgdb *v1alpha1.PostgresDatabase) time.Duration {
+	if pgdb.Spec.Failover != nil && pgdb.Spec.Failover.GracePeriod.Duration > 0 {
+		return pgdb.Spec.Failover.GracePeriod.Duration
+	}
+	return defaultFailoverGracePeriod
+}
+
+// statefulSetRolling reports whether sts is replacing its pods with a new
+// revision, during which each pod, the primary included, restarts in turn.
+func statefulSetRolling(sts *appsv1.StatefulSet) bool {
+	return sts.Status.ObservedGeneration < sts.Generation ||
+		sts.Status.UpdateRevision != sts.Status.CurrentRevision
+}
+
+// podContainersStopped reports whether the kubelet has reported every
+// container of pod as terminated.
+func podContainersStopped(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// reconcileParameters makes every ready pod load the current postgresql.conf.
+// Pods that report an older revision are asked to reload; restart-only
+// parameters are applied by the StatefulSet rolling the pods instead. It
+// reports whether every ready pod has loaded the current revision, and records
+// the outcome, including parameters PostgreSQL rejected, in the
+// ParametersApplied condition.
+func (r *PostgresDatabaseReconciler) reconcileParameters(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	var pods corev1.PodList
+	if err := r.client.list(ctx, &pods,
+		client.InNamespace(pgdb.Namespace),
+		client.MatchingLabels(labelsForDatabase(pgdb, r.InstanceName)),
+	); err != nil {
+		return false, fmt.Errorf("listing pods: %w", err)
+	}
+
+	conn, err := r.adminConn(ctx, pgdb)
+	if err != nil {
+		return false, err
+	}
+
+	_, revision := postgresConf(pgdb)
+	stale := 0
+	invalid := map[string]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if podOrdinal(pod) < 0 || !pod.DeletionTimestamp.IsZero() || !podReady(pod) {
+			continue
+		}
+		podConn := conn.at(postgresPodHost(pgdb, int32(podOrdinal(pod))))
+
+		loaded, err := r.pgDB.ConfigRevision(podConn)
+		if err != nil {
+			logger.Info("could not read config revision", "pod", pod.Name, "error", err.Error())
+			stale++
+			continue
+		}
+		if loaded != revision {
+			stale++
+			if err := r.pgDB.ReloadConfig(podConn); err != nil {
+				logger.Info("could not reload config", "pod", pod.Name, "error", err.Error())
+			}
+			continue
+		}
+
+		names, err := r.pgDB.InvalidParameters(podConn)
+		if err != nil {
+			logger.Info("could not check file settings", "pod", pod.Name, "error", err.Error())
+			stale++
+			continue
+		}
+		for _, name := range names {
+			invalid[name] = true
+		}
+	}
+
+	cond := metav1.Condition{
+		Type:               parametersConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Applied",
+		Message:            "all ready pods have loaded the current parameters",
+		ObservedGeneration: pgdb.Generation,
+	}
+	switch {
+	case stale > 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "ReloadPending"
+		cond.Message = fmt.Sprintf("%d pod(s) have not loaded the current parameters yet", stale)
+	case len(invalid) > 0:
+		names := make([]string, 0, len(invalid))
+		for name := range invalid {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "InvalidParameters"
+		cond.Message = fmt.Sprintf("PostgreSQL could not apply: %s", strings.Join(names, ", "))
+	}
+	meta.SetStatusCondition(&pgdb.Status.Conditions, cond)
+	return stale == 0, nil
+}
+
+// reconcileLogicalDatabases creates each database in spec.databases and
+// installs its extensions through the primary, then records every logical
+// database in status.databases with its owner, size, extensions and the
+// credentials naming it. Unreferenced databases are dropped once their grace
+// period ends when spec.dropUnreferencedDatabases is set. Extension failures
+// go to the ExtensionsReady condition rather than failing the instance. It
+// returns how soon it needs to run again, or 0. Nothing is done until a pod
+// is ready or while a PostgresRestore is replaying a dump.
+func (r *PostgresDatabaseReconciler) reconcileLogicalDatabases(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, sts *appsv1.StatefulSet) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	if sts.Status.ReadyReplicas == 0 {
+		return extensionRetryInterval, nil
+	}
+	restoring, err := activeRestore(ctx, &r.client, pgdb)
+	if err != nil {
+		return 0, err
+	}
+	if restoring != "" {
+		return extensionRetryInterval, nil
+	}
+
+	conn, err := r.adminConn(ctx, pgdb)
+	if err != nil {
+		return 0, err
+	}
+	conn.Host = postgresHost(pgdb)
+
+	referenced, credentials, err := r.databaseReferences(ctx, pgdb)
+	if err != nil {
+		return 0, err
+	}
+
+	previous := map[string]v1alpha1.PostgresLogicalDatabaseStatus{}
+	for _, status := range pgdb.Status.Databases {
+		previous[status.Name] = status
+	}
+
+	next := logicalDatabaseResyncInterval
+	var failures []string
+	extensions := map[string][]v1alpha1.PostgresExtensionStatus{}
+	for _, logical := range pgdb.Spec.Databases {
+		err := r.pgDB.EnsureDatabase(conn, logical.Name)
+		if err == nil {
+			extensions[logical.Name], err = r.pgDB.EnsureExtensions(conn, logical.Name, logical.Extensions)
+		}
+		if err != nil {
+			failures = append(failures, err.Error())
+			extensions[logical.Name] = previous[logical.Name].Extensions
+			next = extensionRetryInterval
+		}
+	}
+
+	databases, err := r.pgDB.ListDatabases(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	now := metav1.Now()
+	statuses := make([]v1alpha1.PostgresLogicalDatabaseStatus, 0, len(databases))
+	for _, name := range slices.Sorted(maps.Keys(databases)) {
+		status := v1alpha1.PostgresLogicalDatabaseStatus{
+			Name:        name,
+			Size:        resource.NewQuantity(databases[name].Size, resource.BinarySI),
+			Credentials: credentials[name],
+		}
+
+		if !referenced[name] {
+			status.UnreferencedSince = previous[name].UnreferencedSince
+			if status.UnreferencedSince == nil {
+				status.UnreferencedSince = &now
+			}
+			// Only databases the operator created are dropped; any other was
+			// made by hand or by a restore and may hold data nothing here knows of.
+			if policy := pgdb.Spec.DropUnreferencedDatabases; policy != nil && databases[name].Created {
+				remaining := status.UnreferencedSince.Add(policy.GracePeriod.Duration).Sub(now.Time)
+				if remaining <= 0 {
+					r.recorder.Eventf(pgdb, nil, corev1.EventTypeWarning, "DroppingUnreferencedDatabase", "DropDatabase",
+						"dropping database %q, unreferenced since %s", name, status.UnreferencedSince.UTC().Format(time.RFC3339))
+					if err := r.pgDB.DropDatabase(conn, name); err != nil {
+						return 0, err
+					}
+					logger.Info("dropped unreferenced database", "database", name)
+					continue
+				}
+				next = min(next, remaining)
+			}
+		}
+
+		if status.Owner, err = r.pgDB.FindOwner(conn, name); err != nil {
+			return 0, err
+		}
+		if installed, ok := extensions[name]; ok {
+			status.Extensions = installed
+		} else if status.Extensions, err = r.pgDB.EnsureExtensions(conn, name, nil); err != nil {
+			logger.Info("could not list extensions", "database", name, "error", err.Error())
+			status.Extensions = previous[name].Extensions
+		}
+		statuses = append(statuses, status)
+	}
+	pgdb.Status.Databases = statuses
+
+	if len(pgdb.Spec.Databases) == 0 {
+		meta.RemoveStatusCondition(&pgdb.Status.Conditions, extensionsConditionType)
+		return next, nil
+	}
+	cond := metav1.Condition{
+		Type:               extensionsConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Installed",
+		Message:            "all declared extensions are installed",
+		ObservedGeneration: pgdb.Generation,
+	}
+	if len(failures) > 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "InstallFailed"
+		cond.Message = strings.Join(failures, "; ")
+	}
+	meta.SetStatusCondition(&pgdb.Status.Conditions, cond)
+	return next, nil
+}
+
+// databaseReferences reports which logical databases of pgdb are named by
+// spec.databases, a PostgresCredential or a PostgresRole, and the sorted
+// names of the credentials naming each one.
+func (r *PostgresDatabaseReconciler) databaseReferences(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) (map[string]bool, map[string][]string, error) {
+	referenced := map[string]bool{}
+	for _, logical := range pgdb.Spec.Databases {
+		referenced[logical.Name] = true
+	}
+
+	var creds v1alpha1.PostgresCredentialList
+	if err := r.client.list(ctx, &creds, client.InNamespace(pgdb.Namespace)); err != nil {
+		return nil, nil, fmt.Errorf("listing PostgresCredentials: %w", err)
+	}
+	credentials := map[string][]string{}
+	for _, cred := range creds.Items {
+		if cred.Spec.DatabaseRef != pgdb.Name {
+			continue
+		}
+		for _, entry := range cred.Spec.Permissions {
+			for _, dbName := range entry.Databases {
+				referenced[dbName] = true
+				if !slices.Contains(credentials[dbName], cred.Name) {
+					credentials[dbName] = append(credentials[dbName], cred.Name)
+				}
+			}
+		}
+	}
+	for _, names := range credentials {
+		slices.Sort(names)
+	}
+
+	var roles v1alpha1.PostgresRoleList
+	if err := r.client.list(ctx, &roles, client.InNamespace(pgdb.Namespace)); err != nil {
+		return nil, nil, fmt.Errorf("listing PostgresRoles: %w", err)
+	}
+	for _, role := range roles.Items {
+		if role.Spec.DatabaseRef != pgdb.Name {
+			continue
+		}
+		for _, entry := range role.Spec.Permissions {
+			for _, dbName := range entry.Databases {
+				referenced[dbName] = true
+			}
+		}
+	}
+	return referenced, credentials, nil
+}
+
+// reconcileAdminPassword rotates the postgres superuser password when the
+// RotateAdminPasswordAnnotation or spec.adminPasswordRotation asks for it,
+// once every pod is ready. The new password is staged in the admin Secret,
+// set on the primary with ALTER ROLE, then moved into PGPASSWORD. Standbys
+// replicate the role change but keep the old password in primary_conninfo,
+// so each one is pointed at the new password; a standby that cannot be
+// reached picks it up from postgresStartScript when it next starts.
+func (r *PostgresDatabaseReconciler) reconcileAdminPassword(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, sts *appsv1.StatefulSet) error {
+	logger := log.FromContext(ctx)
+
+	var secret corev1.Secret
+	found, err := r.client.get(ctx, types.NamespacedName{Name: adminSecretName(pgdb), Namespace: pgdb.Namespace}, &secret)
+	if err != nil {
+		return fmt.Errorf("fetching admin Secret: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("admin Secret %q not found", adminSecretName(pgdb))
+	}
+
+	pendingKey := "PGPASSWORD" + pendingPasswordSuffix
+	due, request := adminPasswordRotationDue(pgdb, pgdb.Spec.AdminPasswordRotation,
+		pgdb.Status.AdminPasswordRotatedAt, pgdb.Status.AdminPasswordRotationRequest)
+	pending := string(secret.Data[pendingKey])
+	if pending == "" {
+		if !due || sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+			return nil
+		}
+		if pending, err = generatePassword(24); err != nil {
+			return fmt.Errorf("generating admin password: %w", err)
+		}
+		secret.Data[pendingKey] = []byte(pending)
+		if err := r.client.update(ctx, &secret); err != nil {
+			return fmt.Errorf("staging admin password: %w", err)
+		}
+	}
+
+	caCert, err := postgresCACert(ctx, &r.client, pgdb)
+	if err != nil {
+		return err
+	}
+	conn := PostgresConn{
+		Host:     postgresHost(pgdb),
+		User:     string(secret.Data["PGUSER"]),
+		Password: string(secret.Data["PGPASSWORD"]),
+		CACert:   caCert,
+	}
+	if err := r.pgDB.SetPassword(conn, conn.User, pending); err != nil {
+		// An interrupted rotation may already have changed the password.
+		if retryErr := r.pgDB.SetPassword(conn.withPassword(pending), conn.User, pending); retryErr != nil {
+			return fmt.Errorf("setting admin password: %w", err)
+		}
+	}
+	conn = conn.withPassword(pending)
+
+	secret.Data["PGPASSWORD"] = []byte(pending)
+	delete(secret.Data, pendingKey)
+	if err := r.client.update(ctx, &secret); err != nil {
+		return fmt.Errorf("updating admin Secret: %w", err)
+	}
+
+	now := metav1.Now()
+	pgdb.Status.AdminPasswordRotatedAt = &now
+	pgdb.Status.AdminPasswordRotationRequest = request
+	logger.Info("rotated admin password")
+
+	for ordinal := int32(0); ordinal < *sts.Spec.Replicas; ordinal++ {
+		if ordinal == pgdb.Status.PrimaryOrdinal {
+			continue
+		}
+		if err := r.pgDB.SetPrimaryConnPassword(conn.at(postgresPodHost(pgdb, ordinal)), pending); err != nil {
+			logger.Info("could not update standby replication password", "ordinal", ordinal, "error", err.Error())
+		}
+	}
+	return nil
+}
+
+// adminConn returns the superuser connection details from the admin Secret,
+// with the CA bundle to verify the server against when TLS is enabled. The
+// host is left for callers to fill in per pod.
+func (r *PostgresDatabaseReconciler) adminConn(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) (PostgresConn, error) {
+	var secret corev1.Secret
+	found, err := r.client.get(ctx, types.NamespacedName{Name: adminSecretName(pgdb), Namespace: pgdb.Namespace}, &secret)
+	if err != nil {
+		return PostgresConn{}, fmt.Errorf("fetching admin Secret: %w", err)
+	}
+	if !found {
+		return PostgresConn{}, fmt.Errorf("admin Secret %q not found", adminSecretName(pgdb))
+	}
+	caCert, err := postgresCACert(ctx, &r.client, pgdb)
+	if err != nil {
+		return PostgresConn{}, err
+	}
+	return PostgresConn{
+		User:     string(secret.Data["PGUSER"]),
+		Password: string(secret.Data["PGPASSWORD"]),
+		CACert:   caCert,
+	}, nil
+}
+
+// reconcileExporterRole creates the role postgres_exporter connects as when
+// metrics are enabled, once the primary is ready. Standbys receive it through
+// replication.
+func (r *PostgresDatabaseReconciler) reconcileExporterRole(ctx context.Context, pgdb *v1alpha1.PostgresDatabase, sts *appsv1.StatefulSet) error {
+	if pgdb.Spec.Metrics == nil || sts.Status.ReadyReplicas == 0 {
+		return nil
+	}
+	restoring, err := activeRestore(ctx, &r.client, pgdb)
+	if err != nil || restoring != "" {
+		return err
+	}
+
+	conn, err := r.adminConn(ctx, pgdb)
+	if err != nil {
+		return err
+	}
+	conn.Host = postgresHost(pgdb)
+	return r.pgDB.EnsureMonitoringRole(conn, postgresExporterRole)
+}
+
+// podReady reports whether the pod's Ready condition is True.
+func podReady(pod *corev1.Pod) bool {
+	_, unready := podUnreadySince(pod)
+	return !unready
+}
+
+// podUnreadySince reports whether pod is unready and, if so, since when. A pod
+// that has never reported readiness is unready since its creation.
+func podUnreadySince(pod *corev1.Pod) (time.Time, bool) {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type != corev1.PodReady {
+			continue
+		}
+		if cond.Status == corev1.ConditionTrue {
+			return time.Time{}, false
+		}
+		return cond.LastTransitionTime.Time, true
+	}
+	return pod.CreationTimestamp.Time, true
+}
+
+// reconcilePodRoles labels each postgres pod with its replication role so the
+// read-only Service can select standbys.
+func (r *PostgresDatabaseReconciler) reconcilePodRoles(ctx context.Context, pgdb *v1alpha1.PostgresDatabase) error {
+	var pods corev1.PodList
+	if err := r.client.list(ctx, &pods,
+		client.InNamespace(pgdb.Namespace),
+		client.MatchingLabels(labelsForDatabase(pgdb, r.InstanceName)),
+	); err != nil {
+		return fmt.Errorf("listing pods: %w", err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		role := postgresRoleReplica
+		if podOrdinal(pod) == int(pgdb.Status.PrimaryOrdinal) {
+			role = postgresRolePrimary
+		}
+		if pod.Labels[postgresRoleLabel] == role {
+			continue
+		}
+		pod.Labels[postgresRoleLabel] = role
+		if err := r.client.update(ctx, pod); err != nil {
+			return fmt.Errorf("labelling pod %s: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// podOrdinal returns the StatefulSet ordinal encoded in the pod name, or -1
+// when the name carries none.
+func podOrdinal(pod *corev1.Pod) int {
+	idx := strings.LastIndex(pod.Name, "-")
+	if idx < 0 {
+		return -1
+	}
+	ordinal, err := strconv.Atoi(pod.Name[idx+1:])
+	if err != nil {
+		return -1
+	}
+	return ordinal
+}
+
+// updatePhaseFromStatefulSet checks the StatefulSet readiness and sets the
+// PostgresDatabase phase accordingly in memory. The caller passes the StatefulSet
+// returned by the most recent API server write so no redundant cache read is needed.
+func (r *PostgresDatabaseReconciler) updatePhaseFromStatefulSet(pgdb *v1alpha1.PostgresDatabase, sts *appsv1.StatefulSet) ctrl.Result {
+	if sts.Status.ReadyReplicas >= 1 && sts.Status.ReadyReplicas == *sts.Spec.Replicas {
+		return r.setPhase(pgdb, v1alpha1.DatabasePhaseReady,
+			"StatefulSetReady", "StatefulSet has all replicas ready")
+	}
+
+	return r.setPhase(pgdb, v1alpha1.DatabasePhasePending,
+		"StatefulSetNotReady", "waiting for StatefulSet replicas to become ready")
+}
+
+// setPhase mutates the PostgresDatabase status phase and condition in memory.
+// The caller is responsible for persisting the status via a single
+// r.Status().Update() call. A requeue result is returned when the phase is
+// Pending or Upgrading.
+func (r *PostgresDatabaseReconciler) setPhase(
+	pgdb *v1alpha1.PostgresDatabase,
+	phase v1alpha1.DatabasePhase,
+	reason, message string,
+) ctrl.Result {
+	pgdb.Status.Phase = phase
+
+	conditionStatus := metav1.ConditionFalse
+	if phase == v1alpha1.DatabasePhaseReady {
+		conditionStatus = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(&pgdb.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: pgdb.Generation,
+	})
+
+	if phase == v1alpha1.DatabasePhasePending || phase == v1alpha1.DatabasePhaseUpgrading {
+		return ctrl.Result{RequeueAfter: 5_000_000_000} // 5 seconds
+	}
+
+	return ctrl.Result{}
+}
+
+// SetupWithManager registers the PostgresDatabaseReconciler with the controller manager.
+// Backup Jobs and postgres pods are owned by a CronJob or StatefulSet rather than
+// the PostgresDatabase, so they are mapped back to their database via the
+// instance label to record completions and label new pods promptly. TLS
+// Secrets issued by cert-manager are not owned by the database either, and
+// are mapped back the same way so issuance and renewal take effect at once.
+func (r *PostgresDatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.client = postgresDatabaseClient{inner: mgr.GetClient()}
 	r.builder = postgresDatabaseBuilder{instanceName: r.InstanceName, scheme: mgr.GetScheme()}
+	r.pgDB = instrumentedPostgresManager{inner: postgresManager{}}
+	r.recorder = mgr.GetEventRecorder("postgresdatabase-controller")
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.PostgresDatabase{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&batchv1.CronJob{}).
+		Owns(&batchv1.Job{}).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(mapInstanceLabel("postgres-backup"))).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(mapInstanceLabel("postgres")),
+			builder.WithPredicates(managedByInstance(r.InstanceName))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.databaseForSecret),
+			builder.WithPredicates(managedByInstance(r.InstanceName))).
+		Watches(&v1alpha1.PostgresCredential{}, handler.EnqueueRequestsFromMapFunc(mapDatabaseRef)).
+		Watches(&v1alpha1.PostgresRole{}, handler.EnqueueRequestsFromMapFunc(mapDatabaseRef)).
 		Complete(r)
 }
+
+// mapDatabaseRef enqueues the PostgresDatabase a PostgresCredential or
+// PostgresRole targets, so status.databases follows their references.
+func mapDatabaseRef(_ context.Context, obj client.Object) []reconcile.Request {
+	var dbRef string
+	switch o := obj.(type) {
+	case *v1alpha1.PostgresCredential:
+		dbRef = o.Spec.DatabaseRef
+	case *v1alpha1.PostgresRole:
+		dbRef = o.Spec.DatabaseRef
+	default:
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: dbRef, Namespace: obj.GetNamespace()}},
+	}
+}
+
+// managedByInstance passes only objects this operator instance created, so
+// events for unrelated Pods and Secrets in the cluster are dropped before
+// they are mapped.
+func managedByInstance(instanceName string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		labels := obj.GetLabels()
+		return labels["app.kubernetes.io/managed-by"] == "db-operator" &&
+			labels["db-operator.benjamin-wright.github.com/operator-instance"] == instanceName
+	})
+}
+
+// mapInstanceLabel returns a map function that enqueues the PostgresDatabase
+// named by the app.kubernetes.io/instance label of objects whose
+// app.kubernetes.io/name label equals name.
+func mapInstanceLabel(name string) handler.MapFunc {
+	return func(_ context.Context, obj client.Object) []reconcile.Request {
+		labels := obj.GetLabels()
+		if labels["app.kubernetes.io/name"] != name {
+			return nil
+		}
+		return []reconcile.Request{
+			{NamespacedName: types.NamespacedName{
+				Name:      labels["app.kubernetes.io/instance"],
+				Namespace: obj.GetNamespace(),
+			}},
+		}
+	}
+}
@@ -6,16 +6,23 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/benjamin-wright/db-operator/internal/certs"
 	. "github.com/benjamin-wright/db-operator/internal/test_utils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
@@ -50,6 +57,75 @@ func newTestResources(name string) (ns *corev1.Namespace, pgdb *v1alpha1.Postgre
 	return
 }
 
+// newPodTemplateOverrides returns overrides every test cluster can satisfy:
+// the node selector matches any Linux node and the toleration matches a taint
+// nothing carries.
+func newPodTemplateOverrides() *v1alpha1.PodTemplateOverrides {
+	return &v1alpha1.PodTemplateOverrides{
+		Labels:      map[string]string{"team": "games", "app.kubernetes.io/name": "ignored"},
+		Annotations: map[string]string{"example.com/owner": "games"},
+		Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+		},
+		NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+		Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "databases", Effect: corev1.TaintEffectNoSchedule},
+		},
+	}
+}
+
+// expectPodTemplateApplied asserts that pod carries newPodTemplateOverrides,
+// with resources on the named container and operator labels left intact.
+func expectPodTemplateApplied(g Gomega, pod *corev1.Pod, container, appName string) {
+	g.Expect(pod.Labels).To(HaveKeyWithValue("team", "games"))
+	g.Expect(pod.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", appName))
+	g.Expect(pod.Annotations).To(HaveKeyWithValue("example.com/owner", "games"))
+	g.Expect(pod.Spec.NodeSelector).To(HaveKeyWithValue("kubernetes.io/os", "linux"))
+	g.Expect(pod.Spec.Tolerations).To(ContainElement(HaveField("Key", "dedicated")))
+	g.Expect(pod.Spec.Containers).To(ContainElement(And(
+		HaveField("Name", container),
+		HaveField("Resources.Limits", HaveKeyWithValue(corev1.ResourceMemory, resource.MustParse("512Mi"))),
+	)))
+}
+
+// newExpandableStorageClass creates a copy of the cluster's default
+// StorageClass that allows volume expansion. Callers delete it when done.
+// The provisioner need not support resizing: the API server accepts the larger
+// request either way, which is all the operator is responsible for.
+func newExpandableStorageClass() *storagev1.StorageClass {
+	var classes storagev1.StorageClassList
+	Expect(K8sClient.List(Ctx, &classes)).To(Succeed())
+
+	var base *storagev1.StorageClass
+	for i := range classes.Items {
+		if classes.Items[i].Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+			base = &classes.Items[i]
+		}
+	}
+	Expect(base).NotTo(BeNil(), "the test cluster needs a default StorageClass")
+
+	allow := true
+	sc := &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{GenerateName: "test-expandable-"},
+		Provisioner:          base.Provisioner,
+		Parameters:           base.Parameters,
+		ReclaimPolicy:        base.ReclaimPolicy,
+		VolumeBindingMode:    base.VolumeBindingMode,
+		AllowVolumeExpansion: &allow,
+	}
+	Expect(K8sClient.Create(Ctx, sc)).To(Succeed())
+	return sc
+}
+
+// expectStorageResizing asserts that the StorageResizing condition reports an
+// expansion that is under way or finished.
+func expectStorageResizing(g Gomega, conditions []metav1.Condition) {
+	cond := meta.FindStatusCondition(conditions, "StorageResizing")
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Reason).To(BeElementOf("Expanding", "FileSystemResizePending", "ResizeComplete"))
+}
+
 var _ = Describe("PostgresDatabaseReconciler", func() {
 
 	// ── Phase lifecycle ──────────────────────────────────────────────────────
@@ -215,18 +291,21 @@ var _ = Describe("PostgresDatabaseReconciler", func() {
 	})
 
 	// ── Storage resize ───────────────────────────────────────────────────────
-	// Verify that increasing StorageSize destroys and recreates the database
-	// with the new storage size, and returns to Ready.
-	Context("when the StorageSize of a ready PostgresDatabase is increased", Ordered, func() {
+	// Growing storage on a StorageClass without volume expansion leaves the
+	// volumes, and the data on them, as they are.
+	Context("when the StorageSize of a ready PostgresDatabase grows on a StorageClass without expansion", Ordered, func() {
 		var (
 			ns     *corev1.Namespace
 			lookup types.NamespacedName
+			pvcKey types.NamespacedName
+			pvcUID types.UID
 		)
 
 		BeforeAll(func() {
 			var pgdb *v1alpha1.PostgresDatabase
 			ns, pgdb, lookup, _ = newTestResources("test-db")
 			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			pvcKey = types.NamespacedName{Name: "data-" + pgdb.Name + "-0", Namespace: ns.Name}
 
 			// Wait for the database to become Ready before attempting a resize.
 			Eventually(func(g Gomega) {
@@ -235,6 +314,10 @@ var _ = Describe("PostgresDatabaseReconciler", func() {
 				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
 			}, Timeout, Interval).Should(Succeed())
 
+			var pvc corev1.PersistentVolumeClaim
+			Expect(K8sClient.Get(Ctx, pvcKey, &pvc)).To(Succeed())
+			pvcUID = pvc.UID
+
 			// Increase the storage size.
 			var latest v1alpha1.PostgresDatabase
 			Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
@@ -246,160 +329,1402 @@ var _ = Describe("PostgresDatabaseReconciler", func() {
 			_ = K8sClient.Delete(Ctx, ns)
 		})
 
-		It("should update the StatefulSet VolumeClaimTemplate to the new size", func() {
-			Eventually(func(g Gomega) {
-				var sts appsv1.StatefulSet
-				g.Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
-				g.Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
-				vcStorage := sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
-				g.Expect(vcStorage.Cmp(resource.MustParse("512Mi"))).To(Equal(0))
-			}, Timeout, Interval).Should(Succeed())
-		})
-
-		It("should return to Ready phase after the resize", func() {
+		It("should report that the StorageClass does not allow expansion", func() {
 			Eventually(func(g Gomega) {
 				var fetched v1alpha1.PostgresDatabase
 				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "StorageResizing")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("ExpansionNotSupported"))
 				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
 			}, Timeout, Interval).Should(Succeed())
 		})
+
+		It("should keep the existing PVC at its current size", func() {
+			Consistently(func(g Gomega) {
+				var pvc corev1.PersistentVolumeClaim
+				g.Expect(K8sClient.Get(Ctx, pvcKey, &pvc)).To(Succeed())
+				g.Expect(pvc.UID).To(Equal(pvcUID))
+				storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(storage.Cmp(resource.MustParse("256Mi"))).To(Equal(0))
+			}, 10*time.Second, Interval).Should(Succeed())
+		})
 	})
 
-	// ── Instance label filtering ─────────────────────────────────────────────
-	// Verify that a CR without the operator-instance label is never reconciled.
-	Context("when a PostgresDatabase has no operator-instance label", Ordered, func() {
+	// ── Online storage expansion ─────────────────────────────────────────────
+	// Growing storage on a StorageClass that allows expansion resizes the live
+	// PVCs instead of recreating them, and leaves the pods running.
+	Context("when storageSize grows on an expandable StorageClass", Ordered, func() {
 		var (
 			ns     *corev1.Namespace
-			pgdb   *v1alpha1.PostgresDatabase
+			sc     *storagev1.StorageClass
 			lookup types.NamespacedName
+			pvcKey types.NamespacedName
+			podKey types.NamespacedName
+			pvcUID types.UID
+			podUID types.UID
 		)
 
 		BeforeAll(func() {
-			ns = &corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					GenerateName: "test-pgdb-nolabel-",
-				},
-			}
-			Expect(K8sClient.Create(Ctx, ns)).To(Succeed())
-
-			pgdb = &v1alpha1.PostgresDatabase{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "no-label-db",
-					Namespace: ns.Name,
-					// Deliberately omit the db-operator.benjamin-wright.github.com/operator-instance label.
-				},
-				Spec: v1alpha1.PostgresDatabaseSpec{
-					PostgresVersion: "16",
-					StorageSize:     resource.MustParse("256Mi"),
-				},
-			}
+			var pgdb *v1alpha1.PostgresDatabase
+			sc = newExpandableStorageClass()
+			ns, pgdb, lookup, _ = newTestResources("test-db")
+			pgdb.Spec.StorageClassName = sc.Name
 			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
-			lookup = types.NamespacedName{Name: pgdb.Name, Namespace: ns.Name}
+			pvcKey = types.NamespacedName{Name: "data-" + pgdb.Name + "-0", Namespace: ns.Name}
+			podKey = types.NamespacedName{Name: pgdb.Name + "-0", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+
+			var pvc corev1.PersistentVolumeClaim
+			Expect(K8sClient.Get(Ctx, pvcKey, &pvc)).To(Succeed())
+			Expect(pvc.Spec.StorageClassName).To(HaveValue(Equal(sc.Name)))
+			pvcUID = pvc.UID
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, podKey, &pod)).To(Succeed())
+			podUID = pod.UID
+
+			var latest v1alpha1.PostgresDatabase
+			Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+			latest.Spec.StorageSize = resource.MustParse("512Mi")
+			Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
 		})
 
 		AfterAll(func() {
-			_ = K8sClient.Delete(Ctx, pgdb)
 			_ = K8sClient.Delete(Ctx, ns)
+			_ = K8sClient.Delete(Ctx, sc)
 		})
 
-		It("should never set a status phase on the CR", func() {
-			// The operator's cache excludes CRs without the instance label so the
-			// reconciler is never called. The status phase must remain empty.
-			Consistently(func(g Gomega) {
+		It("should reject a change of storageClassName", func() {
+			var latest v1alpha1.PostgresDatabase
+			Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+			latest.Spec.StorageClassName = ""
+			err := K8sClient.Update(Ctx, &latest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("storageClassName is immutable"))
+		})
+
+		It("should expand the existing PVC rather than recreating it", func() {
+			Eventually(func(g Gomega) {
+				var pvc corev1.PersistentVolumeClaim
+				g.Expect(K8sClient.Get(Ctx, pvcKey, &pvc)).To(Succeed())
+				g.Expect(pvc.UID).To(Equal(pvcUID))
+				storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(storage.Cmp(resource.MustParse("512Mi"))).To(Equal(0))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should recreate the StatefulSet with the new VolumeClaimTemplate", func() {
+			Eventually(func(g Gomega) {
+				var sts appsv1.StatefulSet
+				g.Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
+				g.Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+				g.Expect(sts.Spec.VolumeClaimTemplates[0].Spec.StorageClassName).To(HaveValue(Equal(sc.Name)))
+				vcStorage := sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(vcStorage.Cmp(resource.MustParse("512Mi"))).To(Equal(0))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should report the expansion in the StorageResizing condition", func() {
+			Eventually(func(g Gomega) {
 				var fetched v1alpha1.PostgresDatabase
 				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
-				g.Expect(fetched.Status.Phase).To(BeEmpty())
-			}, 10*time.Second, Interval).Should(Succeed())
+				expectStorageResizing(g, fetched.Status.Conditions)
+			}, Timeout, Interval).Should(Succeed())
 		})
 
-		It("should not create any owned sub-resources", func() {
-			var stsList appsv1.StatefulSetList
-			Expect(K8sClient.List(Ctx, &stsList, client.InNamespace(ns.Name))).To(Succeed())
-			Expect(stsList.Items).To(BeEmpty(), "expected no StatefulSets for unlabelled CR")
+		It("should return to Ready without restarting the pod", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
 
-			var svcList corev1.ServiceList
-			Expect(K8sClient.List(Ctx, &svcList, client.InNamespace(ns.Name))).To(Succeed())
-			Expect(svcList.Items).To(BeEmpty(), "expected no Services for unlabelled CR")
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, podKey, &pod)).To(Succeed())
+			Expect(pod.UID).To(Equal(podUID))
 		})
 	})
 
-	// ── Deletion / cleanup ───────────────────────────────────────────────────
-	// One DB instance: created, waited to ready, then deleted. All cleanup
-	// assertions run against the same post-deletion state.
-	Context("when a PostgresDatabase is deleted", Ordered, func() {
+	// ── Read replicas ────────────────────────────────────────────────────────
+	// Two pods: the primary at ordinal 0 and one streaming standby.
+	Context("when replicas is greater than one", Ordered, func() {
 		var (
 			ns           *corev1.Namespace
 			pgdb         *v1alpha1.PostgresDatabase
 			lookup       types.NamespacedName
 			secretLookup types.NamespacedName
+			roLookup     types.NamespacedName
 		)
 
 		BeforeAll(func() {
 			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			pgdb.Spec.Replicas = 2
 			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			roLookup = types.NamespacedName{Name: pgdb.Name + "-ro", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, 2*Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should run a StatefulSet with two replicas", func() {
+			var sts appsv1.StatefulSet
+			Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
+			Expect(*sts.Spec.Replicas).To(Equal(int32(2)))
+			Expect(sts.Status.ReadyReplicas).To(Equal(int32(2)))
+		})
+
+		It("should label the pods with their replication roles", func() {
+			Eventually(func(g Gomega) {
+				var primary, standby corev1.Pod
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: pgdb.Name + "-0", Namespace: ns.Name}, &primary)).To(Succeed())
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: pgdb.Name + "-1", Namespace: ns.Name}, &standby)).To(Succeed())
+				g.Expect(primary.Labels).To(HaveKeyWithValue("db-operator.benjamin-wright.github.com/role", "primary"))
+				g.Expect(standby.Labels).To(HaveKeyWithValue("db-operator.benjamin-wright.github.com/role", "replica"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should create a read-only Service selecting the standbys", func() {
+			var svc corev1.Service
+			Expect(K8sClient.Get(Ctx, roLookup, &svc)).To(Succeed())
+			Expect(svc.Spec.ClusterIP).NotTo(Equal(corev1.ClusterIPNone))
+			Expect(svc.Spec.Selector).To(HaveKeyWithValue("db-operator.benjamin-wright.github.com/role", "replica"))
+			Expect(svc.OwnerReferences).To(HaveLen(1))
+			Expect(svc.OwnerReferences[0].Name).To(Equal(pgdb.Name))
+		})
+
+		It("should stream writes on the primary to the standby", func() {
+			primary, closePrimary := ConnectToDatabaseOrdinal(lookup, secretLookup, 0)
+			defer closePrimary()
+			_, err := primary.Exec("CREATE TABLE replicated (id int); INSERT INTO replicated VALUES (7)")
+			Expect(err).NotTo(HaveOccurred())
+
+			standby, closeStandby := ConnectToDatabaseOrdinal(lookup, secretLookup, 1)
+			defer closeStandby()
+
+			var inRecovery bool
+			Expect(standby.QueryRow("SELECT pg_is_in_recovery()").Scan(&inRecovery)).To(Succeed())
+			Expect(inRecovery).To(BeTrue())
+
+			Eventually(func(g Gomega) {
+				var id int
+				g.Expect(standby.QueryRow("SELECT id FROM replicated").Scan(&id)).To(Succeed())
+				g.Expect(id).To(Equal(7))
+			}, Timeout, Interval).Should(Succeed())
+
+			_, err = standby.Exec("INSERT INTO replicated VALUES (8)")
+			Expect(err).To(HaveOccurred(), "standbys must reject writes")
+		})
+
+		It("should publish the read-only host in credential Secrets", func() {
+			CreateNewUser(ns.Name, pgdb.Name, "reader", "reader-secret", []v1alpha1.DatabasePermissionEntry{
+				{Databases: []string{"app"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect}},
+			})
+
+			Eventually(func(g Gomega) {
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: "reader-secret", Namespace: ns.Name}, &secret)).To(Succeed())
+				g.Expect(string(secret.Data["PGHOST_RO"])).To(Equal(fmt.Sprintf("%s-ro.%s.svc.cluster.local", pgdb.Name, ns.Name)))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should point the read-only Service at the primary after scaling down to one", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.Replicas = 1
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
 
-			// Wait for all owned resources to exist.
 			Eventually(func(g Gomega) {
 				var sts appsv1.StatefulSet
 				g.Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
+				g.Expect(*sts.Spec.Replicas).To(Equal(int32(1)))
 				var svc corev1.Service
-				g.Expect(K8sClient.Get(Ctx, lookup, &svc)).To(Succeed())
-				var secret corev1.Secret
-				g.Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed())
+				g.Expect(K8sClient.Get(Ctx, roLookup, &svc)).To(Succeed())
+				g.Expect(svc.Spec.Selector).To(HaveKeyWithValue("db-operator.benjamin-wright.github.com/role", "primary"))
 			}, Timeout, Interval).Should(Succeed())
+		})
+	})
 
-			// Delete the CR and wait for it to be fully removed (finalizer handled).
-			Expect(K8sClient.Delete(Ctx, pgdb)).To(Succeed())
+	// ── Pod template overrides ───────────────────────────────────────────────
+	Context("when a podTemplate is set", Ordered, func() {
+		var (
+			ns     *corev1.Namespace
+			pgdb   *v1alpha1.PostgresDatabase
+			lookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, _ = newTestResources("test-db")
+			pgdb.Spec.PodTemplate = newPodTemplateOverrides()
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			WaitForDatabase(lookup)
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should run the postgres pod with the overrides applied", func() {
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: pgdb.Name + "-0", Namespace: ns.Name}, &pod)).To(Succeed())
+			expectPodTemplateApplied(Default, &pod, "postgres", "postgres")
+		})
+	})
+
+	// ── Server parameters ────────────────────────────────────────────────────
+	// One instance: a reloadable parameter must apply without restarting the
+	// pod, a rejected value must be reported, and a restart-only parameter
+	// must roll the pod.
+	Context("when parameters are set", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			pgdb         *v1alpha1.PostgresDatabase
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+			podLookup    types.NamespacedName
+			podUID       types.UID
+		)
+
+		setParameters := func(params map[string]string) {
 			Eventually(func(g Gomega) {
-				var fetched v1alpha1.PostgresDatabase
-				err := K8sClient.Get(Ctx, lookup, &fetched)
-				g.Expect(err).To(HaveOccurred())
-				g.Expect(client.IgnoreNotFound(err)).To(Succeed())
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.Parameters = params
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
 			}, Timeout, Interval).Should(Succeed())
+		}
+
+		// showSetting opens a fresh connection per call, so callers poll slowly.
+		showSetting := func(g Gomega, name string) string {
+			db, closeDB := ConnectToDatabase(lookup, secretLookup)
+			defer closeDB()
+			var value string
+			g.Expect(db.QueryRow("SELECT current_setting($1)", name).Scan(&value)).To(Succeed())
+			return value
+		}
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			podLookup = types.NamespacedName{Name: pgdb.Name + "-0", Namespace: ns.Name}
+			WaitForDatabase(lookup)
+
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, podLookup, &pod)).To(Succeed())
+			podUID = pod.UID
 		})
 
 		AfterAll(func() {
 			_ = K8sClient.Delete(Ctx, ns)
 		})
 
-		It("should cascade-delete the StatefulSet", func() {
-			var sts appsv1.StatefulSet
-			err := K8sClient.Get(Ctx, lookup, &sts)
-			Expect(err).To(HaveOccurred())
-			Expect(client.IgnoreNotFound(err)).To(Succeed())
-		})
+		It("should render the parameters into the ConfigMap", func() {
+			setParameters(map[string]string{"work_mem": "16MB"})
 
-		It("should cascade-delete the Service", func() {
-			var svc corev1.Service
-			err := K8sClient.Get(Ctx, lookup, &svc)
-			Expect(err).To(HaveOccurred())
-			Expect(client.IgnoreNotFound(err)).To(Succeed())
+			Eventually(func(g Gomega) {
+				var cm corev1.ConfigMap
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: pgdb.Name + "-config", Namespace: ns.Name}, &cm)).To(Succeed())
+				g.Expect(cm.Data["postgresql.conf"]).To(ContainSubstring("work_mem = '16MB'"))
+			}, Timeout, Interval).Should(Succeed())
 		})
 
-		It("should cascade-delete the admin Secret", func() {
-			var secret corev1.Secret
-			err := K8sClient.Get(Ctx, secretLookup, &secret)
-			Expect(err).To(HaveOccurred())
-			Expect(client.IgnoreNotFound(err)).To(Succeed())
+		It("should reload a reloadable parameter without restarting the pod", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(showSetting(g, "work_mem")).To(Equal("16MB"))
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(fetched.Status.Conditions, "ParametersApplied")).To(BeTrue())
+			}, 3*Timeout, 2*time.Second).Should(Succeed())
+
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, podLookup, &pod)).To(Succeed())
+			Expect(pod.UID).To(Equal(podUID))
 		})
 
-		It("should leave no orphaned resources with the operator-managed label", func() {
-			labels := client.MatchingLabels{
-				"app.kubernetes.io/managed-by": "db-operator",
-				"app.kubernetes.io/instance":   pgdb.Name,
-			}
+		It("should report a value PostgreSQL rejects", func() {
+			setParameters(map[string]string{"work_mem": "lots"})
 
-			var stsList appsv1.StatefulSetList
-			Expect(K8sClient.List(Ctx, &stsList, client.InNamespace(ns.Name), labels)).To(Succeed())
-			Expect(stsList.Items).To(BeEmpty(), fmt.Sprintf("orphaned StatefulSets: %v", stsList.Items))
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "ParametersApplied")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("InvalidParameters"))
+				g.Expect(cond.Message).To(ContainSubstring("work_mem"))
+			}, 3*Timeout, Interval).Should(Succeed())
+		})
 
-			var svcList corev1.ServiceList
-			Expect(K8sClient.List(Ctx, &svcList, client.InNamespace(ns.Name), labels)).To(Succeed())
-			Expect(svcList.Items).To(BeEmpty(), fmt.Sprintf("orphaned Services: %v", svcList.Items))
+		It("should roll the pod for a restart-only parameter", func() {
+			setParameters(map[string]string{"work_mem": "16MB", "max_connections": "150"})
 
-			var secretList corev1.SecretList
-			Expect(K8sClient.List(Ctx, &secretList, client.InNamespace(ns.Name), labels)).To(Succeed())
-			Expect(secretList.Items).To(BeEmpty(), fmt.Sprintf("orphaned Secrets: %v", secretList.Items))
+			Eventually(func(g Gomega) {
+				var pod corev1.Pod
+				g.Expect(K8sClient.Get(Ctx, podLookup, &pod)).To(Succeed())
+				g.Expect(pod.UID).NotTo(Equal(podUID))
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+				g.Expect(showSetting(g, "max_connections")).To(Equal("150"))
+			}, 3*Timeout, 2*time.Second).Should(Succeed())
 		})
 	})
-})
+
+	// ── Extensions ───────────────────────────────────────────────────────────
+	Context("when databases declare extensions", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			var pgdb *v1alpha1.PostgresDatabase
+			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			pgdb.Spec.Databases = []v1alpha1.PostgresLogicalDatabase{
+				{
+					Name: "appdb",
+					Extensions: []v1alpha1.PostgresExtension{
+						{Name: "pgcrypto"},
+						{Name: "pg_trgm"},
+					},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			WaitForDatabase(lookup)
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should report the installed extension versions", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(fetched.Status.Conditions, "ExtensionsReady")).To(BeTrue())
+				g.Expect(fetched.Status.Databases).To(HaveLen(1))
+				g.Expect(fetched.Status.Databases[0].Name).To(Equal("appdb"))
+
+				versions := map[string]string{}
+				for _, ext := range fetched.Status.Databases[0].Extensions {
+					versions[ext.Name] = ext.Version
+				}
+				g.Expect(versions).To(HaveKeyWithValue("pgcrypto", Not(BeEmpty())))
+				g.Expect(versions).To(HaveKeyWithValue("pg_trgm", Not(BeEmpty())))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should make the extensions usable in the database", func() {
+			db, closeDB := ConnectToDatabaseNamed(lookup, secretLookup, "appdb")
+			defer closeDB()
+			var similarity float64
+			Expect(db.QueryRow("SELECT similarity('postgres', 'postgis')").Scan(&similarity)).To(Succeed())
+			Expect(similarity).To(BeNumerically(">", 0))
+			_, err := db.Exec("SELECT gen_random_bytes(8)")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should report an extension that cannot be installed without failing the instance", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.Databases[0].Extensions = append(latest.Spec.Databases[0].Extensions,
+					v1alpha1.PostgresExtension{Name: "no_such_extension"})
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "ExtensionsReady")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("InstallFailed"))
+				g.Expect(cond.Message).To(ContainSubstring("no_such_extension"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Logical database lifecycle ───────────────────────────────────────────
+	Context("when dropUnreferencedDatabases is set", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+			credLookup   types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			var pgdb *v1alpha1.PostgresDatabase
+			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			pgdb.Spec.DropUnreferencedDatabases = &v1alpha1.DropUnreferencedDatabases{
+				GracePeriod: metav1.Duration{Duration: 5 * time.Second},
+			}
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			WaitForDatabase(lookup)
+
+			CreateNewUser(ns.Name, pgdb.Name, "keeper", "keeper-secret", []v1alpha1.DatabasePermissionEntry{
+				{Databases: []string{"keepdb"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect}},
+			})
+			credLookup = types.NamespacedName{Name: "keeper", Namespace: ns.Name}
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should list the database with its owner, size and credentials", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Databases).To(HaveLen(1))
+				status := fetched.Status.Databases[0]
+				g.Expect(status.Name).To(Equal("keepdb"))
+				g.Expect(status.Owner).To(Equal("postgres"))
+				g.Expect(status.Size).NotTo(BeNil())
+				g.Expect(status.Size.Value()).To(BeNumerically(">", 0))
+				g.Expect(status.Credentials).To(Equal([]string{"keeper"}))
+				g.Expect(status.UnreferencedSince).To(BeNil())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should drop the database once no credential references it", func() {
+			var cred v1alpha1.PostgresCredential
+			Expect(K8sClient.Get(Ctx, credLookup, &cred)).To(Succeed())
+			Expect(K8sClient.Delete(Ctx, &cred)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Databases).To(BeEmpty())
+
+				db, closeDB := ConnectToDatabase(lookup, secretLookup)
+				defer closeDB()
+				var exists bool
+				g.Expect(db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = 'keepdb')").Scan(&exists)).To(Succeed())
+				g.Expect(exists).To(BeFalse())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should record an Event before dropping the database", func() {
+			Eventually(func(g Gomega) {
+				var list eventsv1.EventList
+				g.Expect(K8sClient.List(Ctx, &list, client.InNamespace(ns.Name))).To(Succeed())
+				var notes []string
+				for _, event := range list.Items {
+					if event.Regarding.Name == lookup.Name && event.Reason == "DroppingUnreferencedDatabase" {
+						notes = append(notes, event.Note)
+					}
+				}
+				g.Expect(notes).To(ContainElement(ContainSubstring(`"keepdb"`)))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should leave databases the operator did not create in place", func() {
+			db, closeDB := ConnectToDatabase(lookup, secretLookup)
+			_, err := db.Exec("CREATE DATABASE handmade")
+			closeDB()
+			Expect(err).NotTo(HaveOccurred())
+
+			// status.databases is otherwise only refreshed every few minutes,
+			// so an annotation change prompts each reconcile.
+			touch := func(value string) {
+				Eventually(func(g Gomega) {
+					var latest v1alpha1.PostgresDatabase
+					g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+					latest.Annotations = map[string]string{"test/touch": value}
+					g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+				}, Timeout, Interval).Should(Succeed())
+			}
+
+			touch("1")
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Databases).To(ConsistOf(HaveField("Name", "handmade")))
+				g.Expect(fetched.Status.Databases[0].UnreferencedSince).NotTo(BeNil())
+			}, Timeout, Interval).Should(Succeed())
+
+			// Reconcile again once the five second grace period has passed.
+			time.Sleep(6 * time.Second)
+			touch("2")
+			Consistently(func(g Gomega) {
+				db, closeDB := ConnectToDatabase(lookup, secretLookup)
+				defer closeDB()
+				var exists bool
+				g.Expect(db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = 'handmade')").Scan(&exists)).To(Succeed())
+				g.Expect(exists).To(BeTrue())
+			}, 15*time.Second, time.Second).Should(Succeed())
+		})
+	})
+
+	// ── PgBouncer pooler ─────────────────────────────────────────────────────
+	Context("when a pooler is configured", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			pgdb         *v1alpha1.PostgresDatabase
+			lookup       types.NamespacedName
+			credSecret   types.NamespacedName
+			poolerLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, _ = newTestResources("test-db")
+			pgdb.Spec.Pooler = &v1alpha1.PostgresPoolerSpec{
+				Mode:     v1alpha1.PostgresPoolerModeTransaction,
+				PoolSize: 5,
+			}
+			pgdb.Spec.PodTemplate = newPodTemplateOverrides()
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			WaitForDatabase(lookup)
+			poolerLookup = types.NamespacedName{Name: pgdb.Name + "-pooler", Namespace: ns.Name}
+
+			CreateNewUser(ns.Name, pgdb.Name, "pooled", "pooled-secret", []v1alpha1.DatabasePermissionEntry{
+				{Databases: []string{"pooldb"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionAll}},
+			})
+			credSecret = types.NamespacedName{Name: "pooled-secret", Namespace: ns.Name}
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should add the pooler keys to credential Secrets", func() {
+			Eventually(func(g Gomega) {
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, credSecret, &secret)).To(Succeed())
+				g.Expect(string(secret.Data["PGBOUNCER_HOST"])).To(Equal(pgdb.Name + "-pooler." + ns.Name + ".svc.cluster.local"))
+				g.Expect(string(secret.Data["PGBOUNCER_PORT"])).To(Equal("6432"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should render the pool settings and credential users into the pooler Secret", func() {
+			Eventually(func(g Gomega) {
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, poolerLookup, &secret)).To(Succeed())
+				g.Expect(string(secret.Data["pgbouncer.ini"])).To(ContainSubstring("pool_mode = transaction"))
+				g.Expect(string(secret.Data["pgbouncer.ini"])).To(ContainSubstring("default_pool_size = 5"))
+				g.Expect(string(secret.Data["userlist.txt"])).To(ContainSubstring(`"pooled" `))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should place the pooler pods on the database's nodes", func() {
+			Eventually(func(g Gomega) {
+				var deploy appsv1.Deployment
+				g.Expect(K8sClient.Get(Ctx, poolerLookup, &deploy)).To(Succeed())
+				spec := deploy.Spec.Template.Spec
+				g.Expect(spec.NodeSelector).To(HaveKeyWithValue("kubernetes.io/os", "linux"))
+				g.Expect(spec.Tolerations).To(ContainElement(HaveField("Key", "dedicated")))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should accept connections through PgBouncer", func() {
+			Eventually(func(g Gomega) {
+				var deploy appsv1.Deployment
+				g.Expect(K8sClient.Get(Ctx, poolerLookup, &deploy)).To(Succeed())
+				g.Expect(deploy.Status.UpdatedReplicas).To(Equal(int32(1)))
+				g.Expect(deploy.Status.ReadyReplicas).To(Equal(int32(1)))
+				g.Expect(deploy.Status.Replicas).To(Equal(int32(1)))
+			}, 2*Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				db, closeDB := ConnectToPooler(lookup, credSecret, "pooldb")
+				defer closeDB()
+				var user string
+				g.Expect(db.QueryRow("SELECT current_user").Scan(&user)).To(Succeed())
+				g.Expect(user).To(Equal("pooled"))
+			}, Timeout, 2*time.Second).Should(Succeed())
+		})
+
+		It("should reload a new credential into PgBouncer without restarting it", func() {
+			var pods corev1.PodList
+			Expect(K8sClient.List(Ctx, &pods, client.InNamespace(ns.Name),
+				client.MatchingLabels{"app.kubernetes.io/name": "postgres-pooler"})).To(Succeed())
+			Expect(pods.Items).To(HaveLen(1))
+			podUID := pods.Items[0].UID
+
+			CreateNewUser(ns.Name, pgdb.Name, "pooled-later", "pooled-later-secret", []v1alpha1.DatabasePermissionEntry{
+				{Databases: []string{"pooldb"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionAll}},
+			})
+			Eventually(func(g Gomega) {
+				db, closeDB := ConnectToPooler(lookup, types.NamespacedName{Name: "pooled-later-secret", Namespace: ns.Name}, "pooldb")
+				defer closeDB()
+				var user string
+				g.Expect(db.QueryRow("SELECT current_user").Scan(&user)).To(Succeed())
+				g.Expect(user).To(Equal("pooled-later"))
+			}, 2*Timeout, 2*time.Second).Should(Succeed())
+
+			Expect(K8sClient.List(Ctx, &pods, client.InNamespace(ns.Name),
+				client.MatchingLabels{"app.kubernetes.io/name": "postgres-pooler"})).To(Succeed())
+			Expect(pods.Items).To(HaveLen(1))
+			Expect(pods.Items[0].UID).To(Equal(podUID))
+		})
+
+		It("should remove the pooler and its keys when the pooler is disabled", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.Pooler = nil
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var deploy appsv1.Deployment
+				g.Expect(apierrors.IsNotFound(K8sClient.Get(Ctx, poolerLookup, &deploy))).To(BeTrue())
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, credSecret, &secret)).To(Succeed())
+				g.Expect(secret.Data).NotTo(HaveKey("PGBOUNCER_HOST"))
+				g.Expect(secret.Data).NotTo(HaveKey("PGBOUNCER_PORT"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Metrics ──────────────────────────────────────────────────────────────
+	Context("when metrics are enabled", Ordered, func() {
+		var (
+			ns     *corev1.Namespace
+			pgdb   *v1alpha1.PostgresDatabase
+			lookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, _ = newTestResources("test-db")
+			pgdb.Spec.Metrics = &v1alpha1.MetricsSpec{
+				ServiceMonitor: &v1alpha1.ServiceMonitorSpec{Interval: "30s"},
+			}
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			WaitForDatabase(lookup)
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should expose a metrics port on the headless Service", func() {
+			var svc corev1.Service
+			Expect(K8sClient.Get(Ctx, lookup, &svc)).To(Succeed())
+			Expect(svc.Spec.Ports).To(ContainElement(HaveField("Name", "metrics")))
+		})
+
+		It("should report the server as up from the exporter sidecar", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(ScrapeMetrics(ns.Name, pgdb.Name+"-0", 9187)).To(ContainSubstring("pg_up 1"))
+			}, Timeout, 2*time.Second).Should(Succeed())
+		})
+
+		It("should remove the sidecar and port when metrics are disabled", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.Metrics = nil
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var svc corev1.Service
+				g.Expect(K8sClient.Get(Ctx, lookup, &svc)).To(Succeed())
+				g.Expect(svc.Spec.Ports).NotTo(ContainElement(HaveField("Name", "metrics")))
+				var sts appsv1.StatefulSet
+				g.Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
+				g.Expect(sts.Spec.Template.Spec.Containers).To(HaveLen(1))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── TLS ──────────────────────────────────────────────────────────────────
+	// One two-pod instance with an operator-managed CA: the standby must clone
+	// and stream over TLS, and credentials must carry the CA.
+	Context("when tls is set without an issuer", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			pgdb         *v1alpha1.PostgresDatabase
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+			tlsLookup    types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			pgdb.Spec.Replicas = 2
+			pgdb.Spec.TLS = &v1alpha1.PostgresTLSSpec{}
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			tlsLookup = types.NamespacedName{Name: pgdb.Name + "-tls", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, 2*Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should issue a server certificate for the Services and pods", func() {
+			var secret corev1.Secret
+			Expect(K8sClient.Get(Ctx, tlsLookup, &secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKey("tls.key"))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.OwnerReferences[0].Name).To(Equal(pgdb.Name))
+
+			names := []string{
+				fmt.Sprintf("%s-primary.%s.svc.cluster.local", pgdb.Name, ns.Name),
+				fmt.Sprintf("%s-ro.%s.svc", pgdb.Name, ns.Name),
+				fmt.Sprintf("%s-1.%s.%s.svc.cluster.local", pgdb.Name, pgdb.Name, ns.Name),
+			}
+			Expect(certs.NeedsRenewal(secret.Data["tls.crt"], secret.Data["ca.crt"], names, time.Now(), 0)).To(BeFalse())
+		})
+
+		It("should serve TLS on every pod", func() {
+			for ordinal := range 2 {
+				db, closeDB := ConnectToDatabaseOrdinal(lookup, secretLookup, ordinal)
+				var ssl string
+				Expect(db.QueryRow("SHOW ssl").Scan(&ssl)).To(Succeed())
+				closeDB()
+				Expect(ssl).To(Equal("on"))
+			}
+		})
+
+		It("should replicate to the standby over TLS", func() {
+			db, closeDB := ConnectToDatabaseOrdinal(lookup, secretLookup, 0)
+			defer closeDB()
+			Eventually(func(g Gomega) {
+				var ssl bool
+				g.Expect(db.QueryRow("SELECT s.ssl FROM pg_stat_replication r JOIN pg_stat_ssl s USING (pid)").Scan(&ssl)).To(Succeed())
+				g.Expect(ssl).To(BeTrue())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should publish the CA and sslmode in credential Secrets", func() {
+			CreateNewUser(ns.Name, pgdb.Name, "tls-user", "tls-user-secret", []v1alpha1.DatabasePermissionEntry{
+				{Databases: []string{"app"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect}},
+			})
+
+			var tlsSecret corev1.Secret
+			Expect(K8sClient.Get(Ctx, tlsLookup, &tlsSecret)).To(Succeed())
+			Eventually(func(g Gomega) {
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: "tls-user-secret", Namespace: ns.Name}, &secret)).To(Succeed())
+				g.Expect(string(secret.Data["PGSSLMODE"])).To(Equal("verify-full"))
+				g.Expect(secret.Data["ca.crt"]).To(Equal(tlsSecret.Data["ca.crt"]))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should remove the TLS Secrets when tls is cleared", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.TLS = nil
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var secret corev1.Secret
+				err := K8sClient.Get(Ctx, tlsLookup, &secret)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, 2*Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Major version upgrade ────────────────────────────────────────────────
+	// One instance upgraded from 15 to 16 in place, then asked to go back.
+	Context("when postgresVersion is changed", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			pgdb         *v1alpha1.PostgresDatabase
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+		)
+
+		setVersion := func(version string) {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.PostgresVersion = version
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+		}
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			pgdb.Spec.PostgresVersion = "15"
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+				g.Expect(fetched.Status.PostgresVersion).To(Equal("15"))
+			}, 2*Timeout, Interval).Should(Succeed())
+
+			db, closeDB := ConnectToDatabase(lookup, secretLookup)
+			_, err := db.Exec("CREATE TABLE upgrade_marker (id int); INSERT INTO upgrade_marker VALUES (15)")
+			Expect(err).NotTo(HaveOccurred())
+			closeDB()
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should enter the Upgrading phase with the StatefulSet scaled down", func() {
+			setVersion("16")
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseUpgrading))
+				var sts appsv1.StatefulSet
+				g.Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
+				g.Expect(*sts.Spec.Replicas).To(Equal(int32(0)))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should return to Ready on the new version with the data intact", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+				g.Expect(fetched.Status.PostgresVersion).To(Equal("16"))
+			}, 5*Timeout, Interval).Should(Succeed())
+
+			db, closeDB := ConnectToDatabase(lookup, secretLookup)
+			defer closeDB()
+
+			var versionNum int
+			Expect(db.QueryRow("SELECT current_setting('server_version_num')::int").Scan(&versionNum)).To(Succeed())
+			Expect(versionNum / 10000).To(Equal(16))
+			var id int
+			Expect(db.QueryRow("SELECT id FROM upgrade_marker").Scan(&id)).To(Succeed())
+			Expect(id).To(Equal(15))
+		})
+
+		It("should remove the upgrade Job once the upgrade is recorded", func() {
+			Eventually(func(g Gomega) {
+				var job batchv1.Job
+				err := K8sClient.Get(Ctx, types.NamespacedName{Name: pgdb.Name + "-upgrade", Namespace: ns.Name}, &job)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should refuse a downgrade without touching the StatefulSet", func() {
+			setVersion("15")
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseFailed))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "Ready")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("DowngradeRefused"))
+			}, Timeout, Interval).Should(Succeed())
+
+			var sts appsv1.StatefulSet
+			Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
+			Expect(sts.Spec.Template.Spec.Containers[0].Image).To(Equal("postgres:16"))
+			Expect(*sts.Spec.Replicas).To(Equal(int32(1)))
+		})
+
+		It("should recover once the version is restored", func() {
+			setVersion("16")
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Failover ─────────────────────────────────────────────────────────────
+	// The primary pod is held in Terminating by a test finalizer, which keeps it
+	// unready without disturbing the cluster's nodes. Its containers stop, which
+	// counts as fenced. Releasing it lets the StatefulSet recreate it as a
+	// standby of the promoted pod.
+	Context("when the primary pod fails", Ordered, func() {
+		const holdFinalizer = "db-operator.benjamin-wright.github.com/test-hold"
+
+		var (
+			ns           *corev1.Namespace
+			pgdb         *v1alpha1.PostgresDatabase
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+			primaryPod   types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			pgdb.Spec.Replicas = 2
+			pgdb.Spec.Failover = &v1alpha1.PostgresFailoverSpec{
+				GracePeriod: metav1.Duration{Duration: 10 * time.Second},
+			}
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			primaryPod = types.NamespacedName{Name: pgdb.Name + "-0", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, 2*Timeout, Interval).Should(Succeed())
+
+			primary, closePrimary := ConnectToDatabaseOrdinal(lookup, secretLookup, 0)
+			_, err := primary.Exec("CREATE TABLE failover_marker (id int); INSERT INTO failover_marker VALUES (1)")
+			Expect(err).NotTo(HaveOccurred())
+			closePrimary()
+
+			Eventually(func(g Gomega) {
+				var pod corev1.Pod
+				g.Expect(K8sClient.Get(Ctx, primaryPod, &pod)).To(Succeed())
+				pod.Finalizers = append(pod.Finalizers, holdFinalizer)
+				g.Expect(K8sClient.Update(Ctx, &pod)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, primaryPod, &pod)).To(Succeed())
+			Expect(K8sClient.Delete(Ctx, &pod)).To(Succeed())
+		})
+
+		AfterAll(func() {
+			releasePod(primaryPod, holdFinalizer)
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should promote the standby and record the failover", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.PrimaryOrdinal).To(Equal(int32(1)))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "Failover")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("PrimaryPromoted"))
+				g.Expect(cond.Message).To(ContainSubstring("promoted ordinal 1"))
+				g.Expect(fetched.Status.Fencing).To(BeNil())
+			}, 3*Timeout, Interval).Should(Succeed())
+		})
+
+		It("should publish the unready primary's DNS name so it can be probed", func() {
+			var svc corev1.Service
+			Expect(K8sClient.Get(Ctx, lookup, &svc)).To(Succeed())
+			Expect(svc.Spec.PublishNotReadyAddresses).To(BeTrue())
+		})
+
+		It("should move the primary Service to the promoted pod", func() {
+			var svc corev1.Service
+			Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: pgdb.Name + "-primary", Namespace: ns.Name}, &svc)).To(Succeed())
+			Expect(svc.Spec.Selector).To(HaveKeyWithValue("statefulset.kubernetes.io/pod-name", pgdb.Name+"-1"))
+		})
+
+		It("should accept writes on the promoted pod", func() {
+			db, closeDB := ConnectToDatabaseOrdinal(lookup, secretLookup, 1)
+			defer closeDB()
+
+			var inRecovery bool
+			Expect(db.QueryRow("SELECT pg_is_in_recovery()").Scan(&inRecovery)).To(Succeed())
+			Expect(inRecovery).To(BeFalse())
+			_, err := db.Exec("INSERT INTO failover_marker VALUES (2)")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should bring the former primary back as a standby", func() {
+			releasePod(primaryPod, holdFinalizer)
+
+			Eventually(func(g Gomega) {
+				var pod corev1.Pod
+				g.Expect(K8sClient.Get(Ctx, primaryPod, &pod)).To(Succeed())
+				g.Expect(pod.DeletionTimestamp).To(BeNil())
+				g.Expect(pod.Labels).To(HaveKeyWithValue("db-operator.benjamin-wright.github.com/role", "replica"))
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, 3*Timeout, Interval).Should(Succeed())
+
+			db, closeDB := ConnectToDatabaseOrdinal(lookup, secretLookup, 0)
+			defer closeDB()
+
+			var inRecovery bool
+			Expect(db.QueryRow("SELECT pg_is_in_recovery()").Scan(&inRecovery)).To(Succeed())
+			Expect(inRecovery).To(BeTrue())
+			Eventually(func(g Gomega) {
+				var count int
+				g.Expect(db.QueryRow("SELECT count(*) FROM failover_marker").Scan(&count)).To(Succeed())
+				g.Expect(count).To(Equal(2))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Scheduled backups ────────────────────────────────────────────────────
+	// One DB instance backed up to a PVC. A Job is created from the CronJob's
+	// template so the test does not have to wait for the schedule to fire.
+	Context("when backups are enabled with a PVC destination", Ordered, func() {
+		var (
+			ns        *corev1.Namespace
+			pgdb      *v1alpha1.PostgresDatabase
+			lookup    types.NamespacedName
+			cronKey   types.NamespacedName
+			manualJob = "manual-backup"
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, _ = newTestResources("test-db")
+
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "backups", Namespace: ns.Name},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("256Mi")},
+					},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, pvc)).To(Succeed())
+
+			pgdb.Spec.Backup = &v1alpha1.PostgresBackupSpec{
+				Schedule:  "0 3 * * *",
+				Retention: 2,
+				PVC:       &v1alpha1.PostgresBackupPVC{ClaimName: pvc.Name},
+			}
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			cronKey = types.NamespacedName{Name: pgdb.Name + "-backup", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+				var cj batchv1.CronJob
+				g.Expect(K8sClient.Get(Ctx, cronKey, &cj)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should create a CronJob on the configured schedule owned by the database", func() {
+			var cj batchv1.CronJob
+			Expect(K8sClient.Get(Ctx, cronKey, &cj)).To(Succeed())
+			Expect(cj.Spec.Schedule).To(Equal("0 3 * * *"))
+			Expect(cj.OwnerReferences).To(HaveLen(1))
+			Expect(cj.OwnerReferences[0].Name).To(Equal(pgdb.Name))
+
+			container := cj.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal("postgres:16"))
+			var passwordEnv *corev1.EnvVar
+			for i := range container.Env {
+				if container.Env[i].Name == "PGPASSWORD" {
+					passwordEnv = &container.Env[i]
+				}
+			}
+			Expect(passwordEnv).NotTo(BeNil())
+			Expect(passwordEnv.ValueFrom.SecretKeyRef.Name).To(Equal(pgdb.Name + "-admin"))
+		})
+
+		It("should record a completed backup run in status.backupHistory", func() {
+			var cj batchv1.CronJob
+			Expect(K8sClient.Get(Ctx, cronKey, &cj)).To(Succeed())
+			job := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      manualJob,
+					Namespace: ns.Name,
+					Labels:    cj.Spec.JobTemplate.Labels,
+				},
+				Spec: cj.Spec.JobTemplate.Spec,
+			}
+			Expect(K8sClient.Create(Ctx, job)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.BackupHistory).To(ContainElement(SatisfyAll(
+					HaveField("JobName", manualJob),
+					HaveField("Result", v1alpha1.BackupResultSucceeded),
+					HaveField("Artifact", pgdb.Name+"/"+manualJob+".sql.gz"),
+				)))
+			}, 2*Timeout, Interval).Should(Succeed())
+		})
+
+		It("should delete the CronJob when backups are disabled", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.Backup = nil
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var cj batchv1.CronJob
+				err := K8sClient.Get(Ctx, cronKey, &cj)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(client.IgnoreNotFound(err)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Admin password rotation ──────────────────────────────────────────────
+	Context("when the rotate-admin-password annotation is set", Ordered, func() {
+		var (
+			ns               *corev1.Namespace
+			pgdb             *v1alpha1.PostgresDatabase
+			lookup           types.NamespacedName
+			secretLookup     types.NamespacedName
+			originalPassword string
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			pgdb.Spec.Replicas = 2
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+			}, 2*Timeout, Interval).Should(Succeed())
+
+			CreateNewUser(ns.Name, pgdb.Name, "rotator", "rotator-secret", []v1alpha1.DatabasePermissionEntry{
+				{Databases: []string{"app"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect}},
+			})
+
+			var secret corev1.Secret
+			Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed())
+			originalPassword = string(secret.Data["PGPASSWORD"])
+
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Annotations = map[string]string{v1alpha1.RotateAdminPasswordAnnotation: "1"}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should write a new password to the admin Secret and record the request", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.AdminPasswordRotationRequest).To(Equal("1"))
+				g.Expect(fetched.Status.AdminPasswordRotatedAt).NotTo(BeNil())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.DatabasePhaseReady))
+
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed())
+				g.Expect(string(secret.Data["PGPASSWORD"])).NotTo(Equal(originalPassword))
+				g.Expect(secret.Data).NotTo(HaveKey("PGPASSWORD_PENDING"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should accept the new password on the primary", func() {
+			db, close := ConnectToDatabaseOrdinal(lookup, secretLookup, 0)
+			defer close()
+			Expect(db.Ping()).To(Succeed())
+		})
+
+		It("should point the standby's replication connection at the new password", func() {
+			var secret corev1.Secret
+			Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed())
+
+			standby, closeStandby := ConnectToDatabaseOrdinal(lookup, secretLookup, 1)
+			defer closeStandby()
+
+			var conninfo string
+			Expect(standby.QueryRow("SELECT current_setting('primary_conninfo')").Scan(&conninfo)).To(Succeed())
+			Expect(conninfo).To(ContainSubstring("password=" + string(secret.Data["PGPASSWORD"])))
+		})
+
+		It("should keep dependent credentials Ready", func() {
+			Consistently(func(g Gomega) {
+				var cred v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: "rotator", Namespace: ns.Name}, &cred)).To(Succeed())
+				g.Expect(cred.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+			}, 10*time.Second, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Instance label filtering ─────────────────────────────────────────────
+	// Verify that a CR without the operator-instance label is never reconciled.
+	Context("when a PostgresDatabase has no operator-instance label", Ordered, func() {
+		var (
+			ns     *corev1.Namespace
+			pgdb   *v1alpha1.PostgresDatabase
+			lookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "test-pgdb-nolabel-",
+				},
+			}
+			Expect(K8sClient.Create(Ctx, ns)).To(Succeed())
+
+			pgdb = &v1alpha1.PostgresDatabase{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "no-label-db",
+					Namespace: ns.Name,
+					// Deliberately omit the db-operator.benjamin-wright.github.com/operator-instance label.
+				},
+				Spec: v1alpha1.PostgresDatabaseSpec{
+					PostgresVersion: "16",
+					StorageSize:     resource.MustParse("256Mi"),
+				},
+			}
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+			lookup = types.NamespacedName{Name: pgdb.Name, Namespace: ns.Name}
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, pgdb)
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should never set a status phase on the CR", func() {
+			// The operator's cache excludes CRs without the instance label so the
+			// reconciler is never called. The status phase must remain empty.
+			Consistently(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(BeEmpty())
+			}, 10*time.Second, Interval).Should(Succeed())
+		})
+
+		It("should not create any owned sub-resources", func() {
+			var stsList appsv1.StatefulSetList
+			Expect(K8sClient.List(Ctx, &stsList, client.InNamespace(ns.Name))).To(Succeed())
+			Expect(stsList.Items).To(BeEmpty(), "expected no StatefulSets for unlabelled CR")
+
+			var svcList corev1.ServiceList
+			Expect(K8sClient.List(Ctx, &svcList, client.InNamespace(ns.Name))).To(Succeed())
+			Expect(svcList.Items).To(BeEmpty(), "expected no Services for unlabelled CR")
+		})
+	})
+
+	// ── Deletion / cleanup ───────────────────────────────────────────────────
+	// One DB instance: created, waited to ready, then deleted. All cleanup
+	// assertions run against the same post-deletion state.
+	Context("when a PostgresDatabase is deleted", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			pgdb         *v1alpha1.PostgresDatabase
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, lookup, secretLookup = newTestResources("test-db")
+			pgdb.Spec.Pooler = &v1alpha1.PostgresPoolerSpec{}
+			Expect(K8sClient.Create(Ctx, pgdb)).To(Succeed())
+
+			// Wait for all owned resources to exist.
+			poolerLookup := types.NamespacedName{Name: pgdb.Name + "-pooler", Namespace: ns.Name}
+			Eventually(func(g Gomega) {
+				var sts appsv1.StatefulSet
+				g.Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
+				var svc corev1.Service
+				g.Expect(K8sClient.Get(Ctx, lookup, &svc)).To(Succeed())
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed())
+				var deploy appsv1.Deployment
+				g.Expect(K8sClient.Get(Ctx, poolerLookup, &deploy)).To(Succeed())
+				g.Expect(K8sClient.Get(Ctx, poolerLookup, &svc)).To(Succeed())
+				g.Expect(K8sClient.Get(Ctx, poolerLookup, &secret)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			// Delete the CR and wait for it to be fully removed (finalizer handled).
+			Expect(K8sClient.Delete(Ctx, pgdb)).To(Succeed())
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresDatabase
+				err := K8sClient.Get(Ctx, lookup, &fetched)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(client.IgnoreNotFound(err)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should cascade-delete the StatefulSet", func() {
+			var sts appsv1.StatefulSet
+			err := K8sClient.Get(Ctx, lookup, &sts)
+			Expect(err).To(HaveOccurred())
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+		})
+
+		It("should cascade-delete the Service", func() {
+			var svc corev1.Service
+			err := K8sClient.Get(Ctx, lookup, &svc)
+			Expect(err).To(HaveOccurred())
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+		})
+
+		It("should cascade-delete the admin Secret", func() {
+			var secret corev1.Secret
+			err := K8sClient.Get(Ctx, secretLookup, &secret)
+			Expect(err).To(HaveOccurred())
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+		})
+
+		It("should leave no orphaned resources with the operator-managed label", func() {
+			labels := client.MatchingLabels{
+				"app.kubernetes.io/managed-by": "db-operator",
+				"app.kubernetes.io/instance":   pgdb.Name,
+			}
+
+			var stsList appsv1.StatefulSetList
+			Expect(K8sClient.List(Ctx, &stsList, client.InNamespace(ns.Name), labels)).To(Succeed())
+			Expect(stsList.Items).To(BeEmpty(), fmt.Sprintf("orphaned StatefulSets: %v", stsList.Items))
+
+			var svcList corev1.ServiceList
+			Expect(K8sClient.List(Ctx, &svcList, client.InNamespace(ns.Name), labels)).To(Succeed())
+			Expect(svcList.Items).To(BeEmpty(), fmt.Sprintf("orphaned Services: %v", svcList.Items))
+
+			var secretList corev1.SecretList
+			Expect(K8sClient.List(Ctx, &secretList, client.InNamespace(ns.Name), labels)).To(Succeed())
+			Expect(secretList.Items).To(BeEmpty(), fmt.Sprintf("orphaned Secrets: %v", secretList.Items))
+
+			var cmList corev1.ConfigMapList
+			Expect(K8sClient.List(Ctx, &cmList, client.InNamespace(ns.Name), labels)).To(Succeed())
+			Expect(cmList.Items).To(BeEmpty(), fmt.Sprintf("orphaned ConfigMaps: %v", cmList.Items))
+
+			var deployList appsv1.DeploymentList
+			Expect(K8sClient.List(Ctx, &deployList, client.InNamespace(ns.Name), labels)).To(Succeed())
+			Expect(deployList.Items).To(BeEmpty(), fmt.Sprintf("orphaned Deployments: %v", deployList.Items))
+		})
+	})
+})
+
+// releasePod removes finalizer from the named pod, ignoring a pod that is
+// already gone.
+func releasePod(key types.NamespacedName, finalizer string) {
+	Eventually(func(g Gomega) {
+		var pod corev1.Pod
+		err := K8sClient.Get(Ctx, key, &pod)
+		if apierrors.IsNotFound(err) {
+			return
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		if !controllerutil.RemoveFinalizer(&pod, finalizer) {
+			return
+		}
+		g.Expect(K8sClient.Update(Ctx, &pod)).To(Succeed())
+	}, Timeout, Interval).Should(Succeed())
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+// postgresRestoreBuilder constructs the restore Job for a PostgresRestore.
+type postgresRestoreBuilder struct {
+	instanceName string
+	scheme       *runtime.Scheme
+}
+
+// desiredRestoreJob constructs the Job that replays the backup artifact into
+// pgdb using its admin Secret.
+//
+// PVC sources are mounted read-only and streamed straight into psql. S3 sources
+// are first downloaded into an emptyDir by an init container running the aws CLI.
+func (b postgresRestoreBuilder) desiredRestoreJob(restore *v1alpha1.PostgresRestore, pgdb *v1alpha1.PostgresDatabase) *batchv1.Job {
+	source := restore.Spec.Source
+	backoffLimit := int32(2)
+
+	restoreContainer := corev1.Container{
+		Name:    "restore",
+		Image:   postgresImage(pgdb),
+		Command: []string{"bash", "-c", restoreScript},
+		Env: []corev1.EnvVar{
+			{Name: "PGHOST", Value: postgresHost(pgdb)},
+			{Name: "PGPORT", Value: fmt.Sprintf("%d", postgresPort)},
+			adminSecretEnv("PGUSER", pgdb),
+			adminSecretEnv("PGPASSWORD", pgdb),
+			{Name: "RESTORE_FILE", Value: restoreFile(restore)},
+		},
+	}
+
+	var podSpec corev1.PodSpec
+	if source.PVC != nil {
+		restoreContainer.VolumeMounts = []corev1.VolumeMount{
+			{Name: "backups", MountPath: backupMountPath, ReadOnly: true},
+		}
+		podSpec = corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers:    []corev1.Container{restoreContainer},
+			Volumes: []corev1.Volume{
+				{
+					Name: "backups",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: source.PVC.ClaimName,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+		}
+	} else {
+		restoreContainer.VolumeMounts = []corev1.VolumeMount{
+			{Name: "backups", MountPath: backupMountPath},
+		}
+		podSpec = corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{
+				{
+					Name:    "download",
+					Image:   awsCLIImage,
+					Command: []string{"bash", "-c", s3DownloadScript},
+					Env: []corev1.EnvVar{
+						{Name: "RESTORE_FILE", Value: restoreFile(restore)},
+						{Name: "S3_ENDPOINT", Value: source.S3.Endpoint},
+						{Name: "S3_BUCKET", Value: source.S3.Bucket},
+						{Name: "S3_KEY", Value: source.S3.Key},
+						{Name: "AWS_DEFAULT_REGION", Value: source.S3.Region},
+						s3CredentialEnv("AWS_ACCESS_KEY_ID", source.S3.CredentialsSecret),
+						s3CredentialEnv("AWS_SECRET_ACCESS_KEY", source.S3.CredentialsSecret),
+					},
+					VolumeMounts: []corev1.VolumeMount{{Name: "backups", MountPath: backupMountPath}},
+				},
+			},
+			Containers: []corev1.Container{restoreContainer},
+			Volumes: []corev1.Volume{
+				{Name: "backups", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		}
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      restoreJobName(restore),
+			Namespace: restore.Namespace,
+			Labels:    labelsForRestore(restore, b.instanceName),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelsForRestore(restore, b.instanceName),
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+	applyClientTLS(&job.Spec.Template.Spec, pgdb)
+	applyPodPlacement(&job.Spec.Template.Spec, pgdb.Spec.PodTemplate)
+	_ = controllerutil.SetControllerReference(restore, job, b.scheme)
+	return job
+}
+
+// restoreScript disconnects other clients before replaying the dump, because
+// the DROP DATABASE statements emitted by pg_dumpall --clean fail while a
+// database has open connections. The replay itself runs without ON_ERROR_STOP:
+// a pg_dumpall script always contains statements that fail harmlessly against a
+// live instance, such as dropping and recreating the connected admin role.
+// Password clauses are stripped from ALTER ROLE statements, since artifacts
+// taken before backups used --no-role-passwords carry the hashes of that time;
+// replaying the admin one would lock this script out at its next \connect.
+const restoreScript = `set -euo pipefail
+psql -d postgres -v ON_ERROR_STOP=1 -c "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE pid <> pg_backend_pid() AND backend_type = 'client backend'"
+gunzip -c "${RESTORE_FILE}" | sed -E "/^ALTER ROLE /s/ PASSWORD '[^']*'//" | psql -d postgres -q -o /dev/null
+`
+
+const s3DownloadScript = `set -euo pipefail
+aws s3 cp "s3://${S3_BUCKET}/${S3_KEY}" "${RESTORE_FILE}" --endpoint-url "${S3_ENDPOINT}"
+`
+
+func restoreJobName(restore *v1alpha1.PostgresRestore) string {
+	return restore.Name + "-restore"
+}
+
+// restoreFile returns the path of the artifact inside the restore pod.
+func restoreFile(restore *v1alpha1.PostgresRestore) string {
+	if restore.Spec.Source.PVC != nil {
+		return path.Join(backupMountPath, restore.Spec.Source.PVC.Path)
+	}
+	return path.Join(backupMountPath, "restore.sql.gz")
+}
+
+// labelsForRestore returns the label set for restore Jobs and pods. Like
+// labelsForBackup, it differs from labelsForDatabase in app.kubernetes.io/name
+// so restore pods are never selected by the instance's StatefulSet or Service.
+func labelsForRestore(restore *v1alpha1.PostgresRestore, instanceName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":                                   "postgres-restore",
+		"app.kubernetes.io/instance":                               restore.Name,
+		"app.kubernetes.io/managed-by":                             "db-operator",
+		"db-operator.benjamin-wright.github.com/operator-instance": instanceName,
+	}
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// postgresRestoreClient encapsulates all cluster interactions for the
+// PostgresRestoreReconciler.
+type postgresRestoreClient struct {
+	inner client.Client
+}
+
+func (c *postgresRestoreClient) get(ctx context.Context, key client.ObjectKey, obj client.Object) (bool, error) {
+	if err := c.inner.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *postgresRestoreClient) create(ctx context.Context, obj client.Object) error {
+	return c.inner.Create(ctx, obj)
+}
+
+func (c *postgresRestoreClient) update(ctx context.Context, obj client.Object) error {
+	return c.inner.Update(ctx, obj)
+}
+
+// delete removes obj from the cluster with background propagation, so that
+// deleting a Job also removes its pods. A not-found error is treated as success.
+func (c *postgresRestoreClient) delete(ctx context.Context, obj client.Object) error {
+	err := c.inner.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *postgresRestoreClient) list(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+	return c.inner.List(ctx, obj, opts...)
+}
+
+func (c *postgresRestoreClient) updateStatus(ctx context.Context, obj client.Object) error {
+	return c.inner.Status().Update(ctx, obj)
+}
@@ -0,0 +1,311 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+const (
+	// restoreFinalizerName is added to PostgresRestore resources to ensure the
+	// restore Job and its pods are cleaned up before deletion completes.
+	restoreFinalizerName = "games-hub.io/postgres-restore"
+)
+
+// PostgresRestoreReconciler reconciles a PostgresRestore object.
+// It runs a single restore Job against the target PostgresDatabase and mirrors
+// the Job's progress into the PostgresRestore phase.
+type PostgresRestoreReconciler struct {
+	InstanceName string
+	client       postgresRestoreClient
+	builder      postgresRestoreBuilder
+	pgDB         PostgresManager
+}
+
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresrestores/finalizers,verbs=update
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresdatabases,verbs=get;list;watch
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgrescredentials,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+
+// Reconcile handles create/update/delete events for PostgresRestore resources.
+func (r *PostgresRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var restore v1alpha1.PostgresRestore
+	found, err := r.client.get(ctx, req.NamespacedName, &restore)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("fetching PostgresRestore: %w", err)
+	}
+	if !found {
+		logger.Info("PostgresRestore resource not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	// Handle deletion via finalizer.
+	if !restore.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &restore)
+	}
+
+	// Ensure the finalizer is present.
+	if !controllerutil.ContainsFinalizer(&restore, restoreFinalizerName) {
+		controllerutil.AddFinalizer(&restore, restoreFinalizerName)
+		if err := r.client.update(ctx, &restore); err != nil {
+			return ctrl.Result{}, fmt.Errorf("adding finalizer: %w", err)
+		}
+	}
+
+	// A restore runs exactly once; finished restores are left untouched so the
+	// outcome remains visible until the resource is deleted.
+	if restoreFinished(&restore) {
+		return ctrl.Result{}, nil
+	}
+
+	result, reconcileErr := r.reconcileRestore(ctx, &restore)
+
+	if isConflict(reconcileErr) {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	if isForbidden(reconcileErr) {
+		logger.V(1).Info("reconcile blocked by Forbidden error; namespace may be terminating", "error", reconcileErr)
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.client.updateStatus(ctx, &restore); err != nil {
+		if isConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+	}
+
+	return result, reconcileErr
+}
+
+// reconcileRestore creates the restore Job once the target database is Ready,
+// then tracks the Job to completion. It mutates restore status in memory; the
+// caller persists it.
+func (r *PostgresRestoreReconciler) reconcileRestore(ctx context.Context, restore *v1alpha1.PostgresRestore) (ctrl.Result, error) {
+	var job batchv1.Job
+	jobKey := types.NamespacedName{Name: restoreJobName(restore), Namespace: restore.Namespace}
+	jobFound, err := r.client.get(ctx, jobKey, &job)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("fetching restore Job: %w", err)
+	}
+	if jobFound {
+		if jobSucceeded(&job) {
+			if err := r.reapplyPasswords(ctx, restore); err != nil {
+				r.setRestorePhase(restore, v1alpha1.RestorePhaseRunning, "PasswordReapplyFailed", err.Error())
+				return ctrl.Result{}, err
+			}
+		}
+		return r.updatePhaseFromJob(restore, &job), nil
+	}
+
+	var pgdb v1alpha1.PostgresDatabase
+	dbKey := types.NamespacedName{Name: restore.Spec.DatabaseRef, Namespace: restore.Namespace}
+	pgdbFound, err := r.client.get(ctx, dbKey, &pgdb)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("fetching target PostgresDatabase: %w", err)
+	}
+	if !pgdbFound {
+		return r.setRestorePhase(restore, v1alpha1.RestorePhasePending,
+			"DatabaseNotFound", fmt.Sprintf("target PostgresDatabase %q not found", restore.Spec.DatabaseRef)), nil
+	}
+	if pgdb.Status.Phase != v1alpha1.DatabasePhaseReady {
+		return r.setRestorePhase(restore, v1alpha1.RestorePhasePending,
+			"DatabaseNotReady", fmt.Sprintf("waiting for PostgresDatabase %q to become Ready", restore.Spec.DatabaseRef)), nil
+	}
+
+	desired := r.builder.desiredRestoreJob(restore, &pgdb)
+	// Failing to create the Job is retried rather than reported as a terminal
+	// Failed phase, because a failed restore is never attempted again.
+	if err := r.client.create(ctx, desired); err != nil {
+		r.setRestorePhase(restore, v1alpha1.RestorePhasePending, "JobCreationFailed", err.Error())
+		return ctrl.Result{}, fmt.Errorf("creating restore Job: %w", err)
+	}
+
+	restore.Status.JobName = desired.Name
+	return r.setRestorePhase(restore, v1alpha1.RestorePhasePending,
+		"JobCreated", fmt.Sprintf("restore Job %q created", desired.Name)), nil
+}
+
+// updatePhaseFromJob maps the restore Job's state onto the PostgresRestore phase.
+func (r *PostgresRestoreReconciler) updatePhaseFromJob(restore *v1alpha1.PostgresRestore, job *batchv1.Job) ctrl.Result {
+	restore.Status.JobName = job.Name
+	restore.Status.StartTime = job.Status.StartTime
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			restore.Status.CompletionTime = jobFinishTime(job)
+			return r.setRestorePhase(restore, v1alpha1.RestorePhaseSucceeded,
+				"RestoreSucceeded", "backup artifact was replayed into the database")
+		case batchv1.JobFailed:
+			restore.Status.CompletionTime = jobFinishTime(job)
+			return r.setRestorePhase(restore, v1alpha1.RestorePhaseFailed,
+				"RestoreFailed", fmt.Sprintf("restore Job %q failed: %s", job.Name, cond.Message))
+		}
+	}
+
+	if job.Status.Ready != nil && *job.Status.Ready > 0 {
+		return r.setRestorePhase(restore, v1alpha1.RestorePhaseRunning,
+			"RestoreRunning", fmt.Sprintf("restore Job %q is running", job.Name))
+	}
+
+	return r.setRestorePhase(restore, v1alpha1.RestorePhasePending,
+		"JobPending", fmt.Sprintf("waiting for restore Job %q to start", job.Name))
+}
+
+// reapplyPasswords sets the role of every PostgresCredential targeting the
+// restored database back to the password in its Secret. pg_dumpall --clean
+// drops and recreates roles that own nothing, which brings them back without a
+// password, so the restore is only reported as Succeeded once they log in again.
+func (r *PostgresRestoreReconciler) reapplyPasswords(ctx context.Context, restore *v1alpha1.PostgresRestore) error {
+	var pgdb v1alpha1.PostgresDatabase
+	found, err := r.client.get(ctx, types.NamespacedName{Name: restore.Spec.DatabaseRef, Namespace: restore.Namespace}, &pgdb)
+	if err != nil {
+		return fmt.Errorf("fetching target PostgresDatabase: %w", err)
+	}
+	if !found {
+		return nil
+	}
+	conn, wait, err := postgresPrimaryConn(ctx, &r.client, &pgdb)
+	if err != nil {
+		return err
+	}
+	if wait != nil {
+		return errors.New(wait.message)
+	}
+
+	var creds v1alpha1.PostgresCredentialList
+	if err := r.client.list(ctx, &creds, client.InNamespace(restore.Namespace)); err != nil {
+		return fmt.Errorf("listing PostgresCredentials: %w", err)
+	}
+	for _, cred := range creds.Items {
+		if cred.Spec.DatabaseRef != pgdb.Name {
+			continue
+		}
+		var secret corev1.Secret
+		found, err := r.client.get(ctx, types.NamespacedName{Name: cred.Spec.SecretName, Namespace: restore.Namespace}, &secret)
+		if err != nil {
+			return fmt.Errorf("fetching credential Secret %q: %w", cred.Spec.SecretName, err)
+		}
+		username := string(secret.Data["PGUSER"])
+		if !found || username == "" {
+			continue
+		}
+		if err := r.pgDB.SetPassword(conn, username, string(secret.Data["PGPASSWORD"])); err != nil {
+			return fmt.Errorf("resetting the password of %q: %w", username, err)
+		}
+	}
+	return nil
+}
+
+// jobSucceeded reports whether job has completed successfully.
+func jobSucceeded(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileDelete deletes the restore Job and removes the finalizer. Deleting a
+// PostgresRestore whose Job is still running aborts the restore.
+func (r *PostgresRestoreReconciler) reconcileDelete(ctx context.Context, restore *v1alpha1.PostgresRestore) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(restore, restoreFinalizerName) {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("running restore finalizer cleanup")
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      restoreJobName(restore),
+			Namespace: restore.Namespace,
+		},
+	}
+	if err := r.client.delete(ctx, job); err != nil {
+		return ctrl.Result{}, fmt.Errorf("deleting restore Job: %w", err)
+	}
+
+	controllerutil.RemoveFinalizer(restore, restoreFinalizerName)
+	if err := r.client.update(ctx, restore); err != nil {
+		if isConflict(err) || isNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("removing finalizer: %w", err)
+	}
+
+	logger.Info("restore finalizer cleanup complete")
+	return ctrl.Result{}, nil
+}
+
+// setRestorePhase mutates the PostgresRestore status phase and condition in
+// memory. The Complete condition is True only once the restore has succeeded.
+// A requeue result is returned when the phase is Pending, since readiness of
+// the target database is polled rather than watched.
+func (r *PostgresRestoreReconciler) setRestorePhase(
+	restore *v1alpha1.PostgresRestore,
+	phase v1alpha1.RestorePhase,
+	reason, message string,
+) ctrl.Result {
+	restore.Status.Phase = phase
+
+	conditionStatus := metav1.ConditionFalse
+	if phase == v1alpha1.RestorePhaseSucceeded {
+		conditionStatus = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(&restore.Status.Conditions, metav1.Condition{
+		Type:               "Complete",
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: restore.Generation,
+	})
+
+	if phase == v1alpha1.RestorePhasePending {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}
+	}
+
+	return ctrl.Result{}
+}
+
+// restoreFinished reports whether restore has reached a terminal phase.
+func restoreFinished(restore *v1alpha1.PostgresRestore) bool {
+	return restore.Status.Phase == v1alpha1.RestorePhaseSucceeded ||
+		restore.Status.Phase == v1alpha1.RestorePhaseFailed
+}
+
+// SetupWithManager registers the PostgresRestoreReconciler with the controller manager.
+func (r *PostgresRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.client = postgresRestoreClient{inner: mgr.GetClient()}
+	r.builder = postgresRestoreBuilder{instanceName: r.InstanceName, scheme: mgr.GetScheme()}
+	r.pgDB = instrumentedPostgresManager{inner: postgresManager{}}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.PostgresRestore{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,365 @@
+//go:build integration
+
+package controller_test
+
+import (
+	"time"
+
+	. "github.com/benjamin-wright/db-operator/internal/test_utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+// newRestore returns a labelled PostgresRestore reading path from claimName.
+// It does NOT create the CR.
+func newRestore(namespace, name, databaseRef, claimName, path string) *v1alpha1.PostgresRestore {
+	return &v1alpha1.PostgresRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"db-operator.benjamin-wright.github.com/operator-instance": "test",
+			},
+		},
+		Spec: v1alpha1.PostgresRestoreSpec{
+			DatabaseRef: databaseRef,
+			Source: v1alpha1.PostgresRestoreSource{
+				PVC: &v1alpha1.PostgresRestorePVCSource{ClaimName: claimName, Path: path},
+			},
+		},
+	}
+}
+
+// enablePVCBackups creates a claim for backups and points the backup schedule
+// of the database at dbLookup to it, returning the claim name.
+func enablePVCBackups(dbLookup types.NamespacedName) string {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "backups", Namespace: dbLookup.Namespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("256Mi")},
+			},
+		},
+	}
+	Expect(K8sClient.Create(Ctx, pvc)).To(Succeed())
+
+	Eventually(func(g Gomega) {
+		var latest v1alpha1.PostgresDatabase
+		g.Expect(K8sClient.Get(Ctx, dbLookup, &latest)).To(Succeed())
+		latest.Spec.Backup = &v1alpha1.PostgresBackupSpec{
+			Schedule: "0 3 * * *",
+			PVC:      &v1alpha1.PostgresBackupPVC{ClaimName: pvc.Name},
+		}
+		g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+	}, Timeout, Interval).Should(Succeed())
+	WaitForDatabase(dbLookup)
+	return pvc.Name
+}
+
+// takeBackup runs a backup immediately from the CronJob's template and
+// returns the artifact it recorded.
+func takeBackup(dbLookup types.NamespacedName) string {
+	var cj batchv1.CronJob
+	cronKey := types.NamespacedName{Name: dbLookup.Name + "-backup", Namespace: dbLookup.Namespace}
+	Eventually(func() error { return K8sClient.Get(Ctx, cronKey, &cj) }, Timeout, Interval).Should(Succeed())
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "manual-backup",
+			Namespace: dbLookup.Namespace,
+			Labels:    cj.Spec.JobTemplate.Labels,
+		},
+		Spec: cj.Spec.JobTemplate.Spec,
+	}
+	Expect(K8sClient.Create(Ctx, job)).To(Succeed())
+
+	var artifact string
+	Eventually(func(g Gomega) {
+		var fetched v1alpha1.PostgresDatabase
+		g.Expect(K8sClient.Get(Ctx, dbLookup, &fetched)).To(Succeed())
+		g.Expect(fetched.Status.BackupHistory).NotTo(BeEmpty())
+		g.Expect(fetched.Status.BackupHistory[0].Result).To(Equal(v1alpha1.BackupResultSucceeded))
+		artifact = fetched.Status.BackupHistory[0].Artifact
+	}, 2*Timeout, Interval).Should(Succeed())
+	return artifact
+}
+
+var _ = Describe("PostgresRestoreReconciler", func() {
+
+	// ── Round trip ───────────────────────────────────────────────────────────
+	// Back up a table, drop it, then restore the artifact and expect it back.
+	Context("when restoring a PVC backup artifact", Ordered, func() {
+		var (
+			ns                *corev1.Namespace
+			pgdb              *v1alpha1.PostgresDatabase
+			dbLookup          types.NamespacedName
+			adminSecretLookup types.NamespacedName
+			artifact          string
+			restoreLookup     types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, dbLookup, adminSecretLookup = NewDatabase("restore-db")
+			claimName := enablePVCBackups(dbLookup)
+
+			db, closeDB := ConnectToDatabase(dbLookup, adminSecretLookup)
+			_, err := db.Exec("CREATE TABLE restore_marker (id int); INSERT INTO restore_marker VALUES (42)")
+			Expect(err).NotTo(HaveOccurred())
+			closeDB()
+
+			artifact = takeBackup(dbLookup)
+
+			db, closeDB = ConnectToDatabase(dbLookup, adminSecretLookup)
+			_, err = db.Exec("DROP TABLE restore_marker")
+			Expect(err).NotTo(HaveOccurred())
+			closeDB()
+
+			restore := newRestore(ns.Name, "restore", pgdb.Name, claimName, artifact)
+			Expect(K8sClient.Create(Ctx, restore)).To(Succeed())
+			restoreLookup = types.NamespacedName{Name: restore.Name, Namespace: ns.Name}
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should reach Succeeded and record the Job", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresRestore
+				g.Expect(K8sClient.Get(Ctx, restoreLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RestorePhaseSucceeded))
+				g.Expect(fetched.Status.JobName).To(Equal("restore-restore"))
+				g.Expect(fetched.Status.CompletionTime).NotTo(BeNil())
+				g.Expect(meta.IsStatusConditionTrue(fetched.Status.Conditions, "Complete")).To(BeTrue())
+			}, 2*Timeout, Interval).Should(Succeed())
+		})
+
+		It("should bring back the data captured in the backup", func() {
+			db, closeDB := ConnectToDatabase(dbLookup, adminSecretLookup)
+			defer closeDB()
+
+			var id int
+			Expect(db.QueryRow("SELECT id FROM restore_marker").Scan(&id)).To(Succeed())
+			Expect(id).To(Equal(42))
+		})
+	})
+
+	// ── Passwords rotated since the backup ──────────────────────────────────
+	// Rotate the admin and a credential password after the backup, then
+	// restore it and expect both current passwords to keep working.
+	Context("when passwords were rotated after the backup", Ordered, func() {
+		var (
+			ns                *corev1.Namespace
+			pgdb              *v1alpha1.PostgresDatabase
+			dbLookup          types.NamespacedName
+			adminSecretLookup types.NamespacedName
+			credLookup        types.NamespacedName
+			credSecretLookup  types.NamespacedName
+			restoreLookup     types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, dbLookup, adminSecretLookup = NewDatabase("restore-db")
+			claimName := enablePVCBackups(dbLookup)
+
+			CreateNewUser(ns.Name, pgdb.Name, "appuser", "appuser-secret", []v1alpha1.DatabasePermissionEntry{
+				{Databases: []string{"app"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect}},
+			})
+			credLookup = types.NamespacedName{Name: "appuser", Namespace: ns.Name}
+			credSecretLookup = types.NamespacedName{Name: "appuser-secret", Namespace: ns.Name}
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+
+			artifact := takeBackup(dbLookup)
+
+			var adminSecret, credSecret corev1.Secret
+			Expect(K8sClient.Get(Ctx, adminSecretLookup, &adminSecret)).To(Succeed())
+			Expect(K8sClient.Get(Ctx, credSecretLookup, &credSecret)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresDatabase
+				g.Expect(K8sClient.Get(Ctx, dbLookup, &latest)).To(Succeed())
+				latest.Annotations = map[string]string{v1alpha1.RotateAdminPasswordAnnotation: "1"}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &latest)).To(Succeed())
+				latest.Spec.Rotation = &v1alpha1.CredentialRotation{Interval: metav1.Duration{Duration: time.Minute}}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var rotated corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, adminSecretLookup, &rotated)).To(Succeed())
+				g.Expect(rotated.Data["PGPASSWORD"]).NotTo(Equal(adminSecret.Data["PGPASSWORD"]))
+				g.Expect(K8sClient.Get(Ctx, credSecretLookup, &rotated)).To(Succeed())
+				g.Expect(rotated.Data["PGPASSWORD"]).NotTo(Equal(credSecret.Data["PGPASSWORD"]))
+			}, 2*Timeout, Interval).Should(Succeed())
+
+			// Stop rotating so the Secret stays put for the rest of the test.
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &latest)).To(Succeed())
+				latest.Spec.Rotation = nil
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			restore := newRestore(ns.Name, "restore", pgdb.Name, claimName, artifact)
+			Expect(K8sClient.Create(Ctx, restore)).To(Succeed())
+			restoreLookup = types.NamespacedName{Name: restore.Name, Namespace: ns.Name}
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should reach Succeeded", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresRestore
+				g.Expect(K8sClient.Get(Ctx, restoreLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RestorePhaseSucceeded))
+			}, 2*Timeout, Interval).Should(Succeed())
+		})
+
+		It("should keep the current admin password", func() {
+			db, closeDB := ConnectToDatabase(dbLookup, adminSecretLookup)
+			defer closeDB()
+			Expect(db.Ping()).To(Succeed())
+		})
+
+		It("should keep the current credential password", func() {
+			db, closeDB := ConnectToDatabase(dbLookup, credSecretLookup)
+			defer closeDB()
+			Expect(db.Ping()).To(Succeed())
+		})
+	})
+
+	// ── Failure ──────────────────────────────────────────────────────────────
+	Context("when the artifact does not exist", Ordered, func() {
+		var (
+			ns            *corev1.Namespace
+			restoreLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			var pgdb *v1alpha1.PostgresDatabase
+			var dbLookup types.NamespacedName
+			ns, pgdb, dbLookup, _ = NewDatabase("restore-missing-db")
+
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "backups", Namespace: ns.Name},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("256Mi")},
+					},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, pvc)).To(Succeed())
+			WaitForDatabase(dbLookup)
+
+			restore := newRestore(ns.Name, "restore", pgdb.Name, pvc.Name, "missing.sql.gz")
+			Expect(K8sClient.Create(Ctx, restore)).To(Succeed())
+			restoreLookup = types.NamespacedName{Name: restore.Name, Namespace: ns.Name}
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should reach Failed once the Job exhausts its retries", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresRestore
+				g.Expect(K8sClient.Get(Ctx, restoreLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RestorePhaseFailed))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "Complete")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("RestoreFailed"))
+			}, 3*Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Credential gating ────────────────────────────────────────────────────
+	// A restore reading from a claim that does not exist never starts, which
+	// holds it in Pending long enough to observe the credential being blocked.
+	Context("when a restore is in progress", Ordered, func() {
+		var (
+			ns            *corev1.Namespace
+			pgdb          *v1alpha1.PostgresDatabase
+			credLookup    types.NamespacedName
+			restoreLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			var dbLookup types.NamespacedName
+			ns, pgdb, dbLookup, _ = NewDatabase("restore-gate-db")
+			WaitForDatabase(dbLookup)
+
+			restore := newRestore(ns.Name, "stuck-restore", pgdb.Name, "no-such-claim", "x.sql.gz")
+			Expect(K8sClient.Create(Ctx, restore)).To(Succeed())
+			restoreLookup = types.NamespacedName{Name: restore.Name, Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresRestore
+				g.Expect(K8sClient.Get(Ctx, restoreLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.JobName).NotTo(BeEmpty())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RestorePhasePending))
+			}, Timeout, Interval).Should(Succeed())
+
+			CreateNewUser(ns.Name, pgdb.Name, "gated", "gated-secret", []v1alpha1.DatabasePermissionEntry{
+				{Databases: []string{"app"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect}},
+			})
+			credLookup = types.NamespacedName{Name: "gated", Namespace: ns.Name}
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should hold the credential in Pending with reason RestoreInProgress", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhasePending))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "Ready")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("RestoreInProgress"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should delete the restore Job when the restore is deleted", func() {
+			var restore v1alpha1.PostgresRestore
+			Expect(K8sClient.Get(Ctx, restoreLookup, &restore)).To(Succeed())
+			Expect(K8sClient.Delete(Ctx, &restore)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var job batchv1.Job
+				err := K8sClient.Get(Ctx, types.NamespacedName{Name: "stuck-restore-restore", Namespace: ns.Name}, &job)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(client.IgnoreNotFound(err)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should let the credential become Ready once no restore is running", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// postgresRoleClient encapsulates all Kubernetes API interactions for the
+// PostgresRoleReconciler.
+type postgresRoleClient struct {
+	inner client.Client
+}
+
+func (c *postgresRoleClient) get(ctx context.Context, key client.ObjectKey, obj client.Object) (bool, error) {
+	if err := c.inner.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *postgresRoleClient) update(ctx context.Context, obj client.Object) error {
+	return c.inner.Update(ctx, obj)
+}
+
+func (c *postgresRoleClient) updateStatus(ctx context.Context, obj client.Object) error {
+	return c.inner.Status().Update(ctx, obj)
+}
+
+func (c *postgresRoleClient) list(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+	return c.inner.List(ctx, obj, opts...)
+}
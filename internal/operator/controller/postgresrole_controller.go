@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+const (
+	// roleFinalizerName is added to PostgresRole resources to ensure the
+	// group role is dropped before deletion completes.
+	roleFinalizerName = "games-hub.io/postgres-role"
+)
+
+// PostgresRoleReconciler reconciles a PostgresRole object.
+// It creates a group role that cannot log in inside the target database and
+// grants it the declared permissions; PostgresCredentials inherit them by
+// membership.
+type PostgresRoleReconciler struct {
+	InstanceName string
+	client       postgresRoleClient
+	pgDB         PostgresManager
+}
+
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresroles,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresroles/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresroles/finalizers,verbs=update
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresdatabases,verbs=get;list;watch
+// +kubebuilder:rbac:groups=games-hub.io,resources=postgresrestores,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile handles create/update/delete events for PostgresRole resources.
+func (r *PostgresRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var role v1alpha1.PostgresRole
+	found, err := r.client.get(ctx, req.NamespacedName, &role)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("fetching PostgresRole: %w", err)
+	}
+	if !found {
+		logger.Info("PostgresRole resource not found; ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	// Handle deletion via finalizer.
+	if !role.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &role)
+	}
+
+	// Ensure the finalizer is present.
+	if !controllerutil.ContainsFinalizer(&role, roleFinalizerName) {
+		controllerutil.AddFinalizer(&role, roleFinalizerName)
+		if err := r.client.update(ctx, &role); err != nil {
+			return ctrl.Result{}, fmt.Errorf("adding finalizer: %w", err)
+		}
+	}
+
+	result, reconcileErr := r.reconcileRole(ctx, &role)
+
+	if isConflict(reconcileErr) {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	if isForbidden(reconcileErr) {
+		logger.V(1).Info("reconcile blocked by Forbidden error; namespace may be terminating", "error", reconcileErr)
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.client.updateStatus(ctx, &role); err != nil {
+		if isConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+	}
+
+	return result, reconcileErr
+}
+
+// reconcileRole creates the group role, grants its permissions and revokes
+// anything undeclared, mutating role status in memory. The caller persists it.
+func (r *PostgresRoleReconciler) reconcileRole(ctx context.Context, role *v1alpha1.PostgresRole) (ctrl.Result, error) {
+	_, conn, wait, err := postgresAdminConn(ctx, &r.client, role.Namespace, role.Spec.DatabaseRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if wait != nil {
+		return r.setRolePhase(role, v1alpha1.PostgresRolePhasePending, wait.reason, wait.message), nil
+	}
+
+	if len(role.Spec.Permissions) == 0 {
+		if err := r.pgDB.EnsureGroupRole(conn, "postgres", role.Spec.RoleName, v1alpha1.DatabasePermissionEntry{}); err != nil {
+			return r.setRolePhase(role, v1alpha1.PostgresRolePhaseFailed, "RoleCreationFailed", err.Error()), err
+		}
+	}
+	for _, entry := range role.Spec.Permissions {
+		for _, dbName := range entry.Databases {
+			if err := r.pgDB.EnsureDatabase(conn, dbName); err != nil {
+				return r.setRolePhase(role, v1alpha1.PostgresRolePhaseFailed, "DatabaseCreationFailed", err.Error()), err
+			}
+			if err := r.pgDB.EnsureGroupRole(conn, dbName, role.Spec.RoleName, entry); err != nil {
+				reason := "RoleCreationFailed"
+				if notFound := objectNotFoundReason(err); notFound != "" {
+					reason = notFound
+				}
+				return r.setRolePhase(role, v1alpha1.PostgresRolePhaseFailed, reason, err.Error()), err
+			}
+		}
+	}
+
+	revoked, err := r.pgDB.RevokeUndeclared(conn, role.Spec.RoleName, role.Spec.Permissions)
+	recordPrivilegeDrift(ctx, &role.Status.Conditions, role.Generation, role.Spec.RoleName, revoked, err)
+	if err != nil {
+		return r.setRolePhase(role, v1alpha1.PostgresRolePhaseFailed, "PrivilegeRevocationFailed", err.Error()), err
+	}
+
+	result := r.setRolePhase(role, v1alpha1.PostgresRolePhaseReady, "RoleReady", "Postgres group role is ready")
+	result.RequeueAfter = privilegeResyncInterval
+	return result, nil
+}
+
+// reconcileDelete drops the group role, which also removes it from every
+// member, then removes the finalizer.
+func (r *PostgresRoleReconciler) reconcileDelete(ctx context.Context, role *v1alpha1.PostgresRole) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(role, roleFinalizerName) {
+		return ctrl.Result{}, nil
+	}
+
+	// If the database cannot be reached, the role goes away with it.
+	if _, conn, wait, err := postgresAdminConn(ctx, &r.client, role.Namespace, role.Spec.DatabaseRef); err == nil && wait == nil {
+		for _, dbName := range roleDatabases(role.Spec.Permissions) {
+			if err := r.pgDB.DropUser(conn, dbName, role.Spec.RoleName); err != nil {
+				logger.Error(err, "failed to drop Postgres role during cleanup", "role", role.Spec.RoleName, "database", dbName)
+			}
+		}
+	}
+
+	// Remove the finalizer so the CR can be garbage-collected.
+	controllerutil.RemoveFinalizer(role, roleFinalizerName)
+	if err := r.client.update(ctx, role); err != nil {
+		if isConflict(err) || isNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("removing finalizer: %w", err)
+	}
+
+	logger.Info("role finalizer cleanup complete")
+	return ctrl.Result{}, nil
+}
+
+// setRolePhase mutates the PostgresRole status phase and condition in memory.
+// The caller is responsible for persisting via r.Status().Update().
+func (r *PostgresRoleReconciler) setRolePhase(
+	role *v1alpha1.PostgresRole,
+	phase v1alpha1.PostgresRolePhase,
+	reason, message string,
+) ctrl.Result {
+	role.Status.Phase = phase
+
+	conditionStatus := metav1.ConditionFalse
+	if phase == v1alpha1.PostgresRolePhaseReady {
+		conditionStatus = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(&role.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: role.Generation,
+	})
+
+	if phase == v1alpha1.PostgresRolePhasePending {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}
+	}
+
+	return ctrl.Result{}
+}
+
+// rolesForDatabaseSecret maps a Secret belonging to a PostgresDatabase to every
+// PostgresRole that targets that database, so none keeps acting on a rotated
+// admin password or CA.
+func (r *PostgresRoleReconciler) rolesForDatabaseSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels["app.kubernetes.io/name"] != "postgres" {
+		return nil
+	}
+
+	var roles v1alpha1.PostgresRoleList
+	if err := r.client.list(ctx, &roles, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "listing PostgresRoles for database Secret", "secret", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, role := range roles.Items {
+		if role.Spec.DatabaseRef == labels["app.kubernetes.io/instance"] {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&role)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager registers the controller with the manager. Roles are
+// re-reconciled when their database's admin or TLS Secret changes.
+func (r *PostgresRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.client = postgresRoleClient{inner: mgr.GetClient()}
+	r.pgDB = instrumentedPostgresManager{inner: postgresManager{}}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.PostgresRole{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.rolesForDatabaseSecret),
+			builder.WithPredicates(managedByInstance(r.InstanceName))).
+		Complete(r)
+}
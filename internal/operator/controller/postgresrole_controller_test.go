@@ -0,0 +1,203 @@
+//go:build integration
+
+package controller_test
+
+import (
+	. "github.com/benjamin-wright/db-operator/internal/test_utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("PostgresRoleReconciler", func() {
+	// ── Group role shared through memberOf ──────────────────────────────────
+	Context("when a credential is a member of a PostgresRole", Ordered, func() {
+		var (
+			ns                *corev1.Namespace
+			pgdb              *v1alpha1.PostgresDatabase
+			dbLookup          types.NamespacedName
+			adminSecretLookup types.NamespacedName
+			roleLookup        types.NamespacedName
+			credLookup        types.NamespacedName
+			memberSecret      types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, pgdb, dbLookup, adminSecretLookup = NewDatabase("role-db")
+			WaitForDatabase(dbLookup)
+
+			role := &v1alpha1.PostgresRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "readers",
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.PostgresRoleSpec{
+					DatabaseRef: pgdb.Name,
+					RoleName:    "readers",
+					Permissions: []v1alpha1.DatabasePermissionEntry{
+						{Databases: []string{"roledb"}, Permissions: []v1alpha1.DatabasePermission{v1alpha1.PermissionSelect}},
+					},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, role)).To(Succeed())
+			roleLookup = types.NamespacedName{Name: "readers", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresRole
+				g.Expect(K8sClient.Get(Ctx, roleLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.PostgresRolePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+
+			// The role created roledb; add a table for the member to read.
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, adminSecretLookup, "roledb")
+			_, err := db.Exec("CREATE TABLE items (id INT)")
+			closeConn()
+			Expect(err).NotTo(HaveOccurred())
+
+			cred := &v1alpha1.PostgresCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "member",
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.PostgresCredentialSpec{
+					DatabaseRef: pgdb.Name,
+					Username:    "member",
+					SecretName:  "member-secret",
+					MemberOf:    []string{"readers"},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, cred)).To(Succeed())
+			credLookup = types.NamespacedName{Name: "member", Namespace: ns.Name}
+			memberSecret = types.NamespacedName{Name: "member-secret", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should add the finalizer to the PostgresRole", func() {
+			var fetched v1alpha1.PostgresRole
+			Expect(K8sClient.Get(Ctx, roleLookup, &fetched)).To(Succeed())
+			Expect(fetched.Finalizers).To(ContainElement("games-hub.io/postgres-role"))
+		})
+
+		It("should let the member use the group role's privileges", func() {
+			db, closeConn := ConnectToDatabaseNamed(dbLookup, memberSecret, "roledb")
+			defer closeConn()
+			_, err := db.Exec("SELECT * FROM items")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = db.Exec("INSERT INTO items VALUES (1)")
+			Expect(err).To(HaveOccurred(), "the group role does not have INSERT yet")
+		})
+
+		It("should pass permissions added to the group role on to the member", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresRole
+				g.Expect(K8sClient.Get(Ctx, roleLookup, &latest)).To(Succeed())
+				latest.Spec.Permissions[0].Permissions = []v1alpha1.DatabasePermission{
+					v1alpha1.PermissionSelect, v1alpha1.PermissionInsert,
+				}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				db, closeConn := ConnectToDatabaseNamed(dbLookup, memberSecret, "roledb")
+				defer closeConn()
+				_, err := db.Exec("INSERT INTO items VALUES (1)")
+				g.Expect(err).NotTo(HaveOccurred())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should revoke the membership when memberOf no longer lists the role", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &latest)).To(Succeed())
+				latest.Spec.MemberOf = nil
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "PrivilegesInSync")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Message).To(ContainSubstring("membership in role readers"))
+
+				db, closeConn := ConnectToDatabaseNamed(dbLookup, memberSecret, "roledb")
+				defer closeConn()
+				_, err := db.Exec("SELECT * FROM items")
+				g.Expect(err).To(HaveOccurred())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should drop the group role when the PostgresRole is deleted", func() {
+			var role v1alpha1.PostgresRole
+			Expect(K8sClient.Get(Ctx, roleLookup, &role)).To(Succeed())
+			Expect(K8sClient.Delete(Ctx, &role)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				db, closeConn := ConnectToDatabaseNamed(dbLookup, adminSecretLookup, "postgres")
+				defer closeConn()
+				var exists bool
+				g.Expect(db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname = 'readers')").Scan(&exists)).To(Succeed())
+				g.Expect(exists).To(BeFalse())
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Missing group role ──────────────────────────────────────────────────
+	Context("when memberOf names a PostgresRole that does not exist", Ordered, func() {
+		var (
+			ns         *corev1.Namespace
+			credLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			var pgdb *v1alpha1.PostgresDatabase
+			var dbLookup types.NamespacedName
+			ns, pgdb, dbLookup, _ = NewDatabase("role-missing-db")
+			WaitForDatabase(dbLookup)
+
+			CreateNewUser(ns.Name, pgdb.Name, "orphan", "orphan-secret", nil)
+			credLookup = types.NamespacedName{Name: "orphan", Namespace: ns.Name}
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &latest)).To(Succeed())
+				latest.Spec.MemberOf = []string{"no-such-role"}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should stay Pending with reason RoleNotFound", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.PostgresCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.CredentialPhasePending))
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "Ready")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("RoleNotFound"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+})
@@ -2,7 +2,10 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -12,6 +15,8 @@ import (
 
 	goredis "github.com/redis/go-redis/v9"
 
+	"github.com/benjamin-wright/db-operator/internal/redisacl"
+	"github.com/benjamin-wright/db-operator/internal/rediscluster"
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
 
@@ -55,6 +60,10 @@ func (c *redisCredentialClient) updateStatus(ctx context.Context, obj client.Obj
 	return c.inner.Status().Update(ctx, obj)
 }
 
+func (c *redisCredentialClient) list(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+	return c.inner.List(ctx, obj, opts...)
+}
+
 // ────────────────────────────────────────────────────────────────────────────
 // RedisManager — external Redis dependency interface
 // ────────────────────────────────────────────────────────────────────────────
@@ -63,38 +72,128 @@ func (c *redisCredentialClient) updateStatus(ctx context.Context, obj client.Obj
 // tested without a live Redis instance.
 type RedisManager interface {
 	EnsureACLUser(ctx context.Context, host, adminPass, username, password string, keyPatterns []string, aclCategories []v1alpha1.RedisACLCategory, commands []string) error
+	// GetACLUser returns the ACL user as the node at host reports it, or nil
+	// when the node has no such user.
+	GetACLUser(ctx context.Context, host, adminPass, username string) (*redisacl.User, error)
 	DropACLUser(ctx context.Context, host, adminPass, username string) error
+	// AddDefaultPassword makes the default user accept password in addition
+	// to the passwords it already has.
+	AddDefaultPassword(ctx context.Context, host, adminPass, password string) error
+	// ResetDefaultPasswords makes password the only one the default user
+	// accepts.
+	ResetDefaultPasswords(ctx context.Context, host, password string) error
+	// ConfigureMemory sets maxmemory, in bytes, and maxmemory-policy on the
+	// running server.
+	ConfigureMemory(ctx context.Context, host, adminPass string, maxMemory int64, policy v1alpha1.RedisEvictionPolicy) error
+	// SetMasterAuth sets the password a replica authenticates to its master
+	// with.
+	SetMasterAuth(ctx context.Context, host, adminPass, masterAuth string) error
+	// SentinelMaster returns the host the Sentinel at sentinelHost reports as
+	// the master named masterName.
+	SentinelMaster(ctx context.Context, sentinelHost, adminPass, masterName string) (string, error)
+	// AddSentinelPassword makes the Sentinel at sentinelHost accept password
+	// in addition to the passwords it already has.
+	AddSentinelPassword(ctx context.Context, sentinelHost, adminPass, password string) error
+	// ResetSentinelPasswords makes password the only one the Sentinel at
+	// sentinelHost accepts.
+	ResetSentinelPasswords(ctx context.Context, sentinelHost, password string) error
+	// SetSentinelAuth sets the password the Sentinel at sentinelHost
+	// authenticates to the master named masterName and to the other
+	// Sentinels with.
+	SetSentinelAuth(ctx context.Context, sentinelHost, adminPass, masterName, password string) error
+	// ClusterNodes returns the CLUSTER NODES output of the node at host.
+	ClusterNodes(ctx context.Context, host, adminPass string) (string, error)
+	// ClusterState returns the cluster_state the node at host reports: "ok"
+	// once every slot is served.
+	ClusterState(ctx context.Context, host, adminPass string) (string, error)
+	// ClusterMeet makes the node at host join the node at ip into its
+	// cluster.
+	ClusterMeet(ctx context.Context, host, adminPass, ip string) error
+	// ClusterAddSlots assigns a range of hash slots to the node at host.
+	ClusterAddSlots(ctx context.Context, host, adminPass string, slots rediscluster.SlotRange) error
+	// ClusterReplicate makes the node at host a replica of masterID.
+	ClusterReplicate(ctx context.Context, host, adminPass, masterID string) error
+	// ClusterForget removes nodeID from the node table of the node at host.
+	ClusterForget(ctx context.Context, host, adminPass, nodeID string) error
+	// ClusterMigrateSlot moves a hash slot and its keys from one master to
+	// another.
+	ClusterMigrateSlot(ctx context.Context, adminPass string, slot int, from, to RedisClusterPeer) error
+}
+
+// RedisClusterPeer addresses a Redis Cluster node: by DNS name for the
+// operator, and by node ID and IP for its peers.
+type RedisClusterPeer struct {
+	Host string
+	IP   string
+	ID   string
 }
 
 // redisManager is the production implementation of RedisManager.
 type redisManager struct{}
 
-// EnsureACLUser connects to Redis and creates (or updates) an ACL user with the
-// given password, key patterns, ACL categories, and individual commands.
+// EnsureACLUser connects to Redis and creates (or replaces) an ACL user with
+// the given password, key patterns, ACL categories, and individual commands,
+// then saves the ACL file so the user survives a restart.
 func (r redisManager) EnsureACLUser(ctx context.Context, host, adminPass, username, password string, keyPatterns []string, aclCategories []v1alpha1.RedisACLCategory, commands []string) error {
 	rdb := openRedis(host, adminPass)
 	defer rdb.Close()
 
-	args := []interface{}{"ACL", "SETUSER", username, "on", ">" + password, "resetkeys", "nocommands"}
-
-	for _, p := range keyPatterns {
-		args = append(args, "~"+p)
-	}
-	for _, cat := range aclCategories {
-		args = append(args, "+@"+string(cat))
-	}
-	for _, cmd := range commands {
-		args = append(args, "+"+cmd)
+	args := []interface{}{"ACL", "SETUSER", username}
+	for _, rule := range redisacl.Rules(redisacl.Spec{
+		Password:    password,
+		KeyPatterns: keyPatterns,
+		Categories:  aclCategories,
+		Commands:    commands,
+	}) {
+		args = append(args, rule)
 	}
 
 	if err := rdb.Do(ctx, args...).Err(); err != nil {
 		return fmt.Errorf("creating Redis ACL user %q: %w", username, err)
 	}
 
-	return nil
+	return saveACL(ctx, rdb)
 }
 
-// DropACLUser connects to Redis and removes the ACL user.
+// GetACLUser connects to Redis and returns the ACL user as ACL GETUSER
+// reports it, or nil when there is no such user.
+func (r redisManager) GetACLUser(ctx context.Context, host, adminPass, username string) (*redisacl.User, error) {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	reply, err := rdb.Do(ctx, "ACL", "GETUSER", username).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading Redis ACL user %q: %w", username, err)
+	}
+
+	fields, err := redisReplyMap(reply)
+	if err != nil {
+		return nil, fmt.Errorf("reading Redis ACL user %q: %w", username, err)
+	}
+	user := &redisacl.User{
+		Flags:     redisReplyStrings(fields["flags"]),
+		Passwords: redisReplyStrings(fields["passwords"]),
+		Commands:  fmt.Sprint(fields["commands"]),
+		Keys:      fmt.Sprint(fields["keys"]),
+		Channels:  fmt.Sprint(fields["channels"]),
+	}
+	if selectors, ok := fields["selectors"].([]interface{}); ok {
+		for _, selector := range selectors {
+			rules, err := redisReplyMap(selector)
+			if err != nil {
+				return nil, fmt.Errorf("reading selector of Redis ACL user %q: %w", username, err)
+			}
+			user.Selectors = append(user.Selectors, strings.TrimSpace(fmt.Sprintf("%v %v %v", rules["commands"], rules["keys"], rules["channels"])))
+		}
+	}
+	return user, nil
+}
+
+// DropACLUser connects to Redis and removes the ACL user, then saves the ACL
+// file.
 func (r redisManager) DropACLUser(ctx context.Context, host, adminPass, username string) error {
 	rdb := openRedis(host, adminPass)
 	defer rdb.Close()
@@ -103,9 +202,275 @@ func (r redisManager) DropACLUser(ctx context.Context, host, adminPass, username
 		return fmt.Errorf("dropping Redis ACL user %q: %w", username, err)
 	}
 
+	return saveACL(ctx, rdb)
+}
+
+// AddDefaultPassword connects to Redis and adds password to the default user.
+func (r redisManager) AddDefaultPassword(ctx context.Context, host, adminPass, password string) error {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	if err := rdb.Do(ctx, "ACL", "SETUSER", "default", ">"+password).Err(); err != nil {
+		return fmt.Errorf("adding admin password: %w", err)
+	}
+
+	return saveACL(ctx, rdb)
+}
+
+// ResetDefaultPasswords connects to Redis with password and removes every
+// other password of the default user.
+func (r redisManager) ResetDefaultPasswords(ctx context.Context, host, password string) error {
+	rdb := openRedis(host, password)
+	defer rdb.Close()
+
+	if err := rdb.Do(ctx, "ACL", "SETUSER", "default", "resetpass", ">"+password).Err(); err != nil {
+		return fmt.Errorf("resetting admin passwords: %w", err)
+	}
+
+	return saveACL(ctx, rdb)
+}
+
+// ConfigureMemory connects to Redis and applies the memory settings with
+// CONFIG SET, which takes effect without a restart.
+func (r redisManager) ConfigureMemory(ctx context.Context, host, adminPass string, maxMemory int64, policy v1alpha1.RedisEvictionPolicy) error {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	if err := rdb.Do(ctx, "CONFIG", "SET", "maxmemory", maxMemory, "maxmemory-policy", string(policy)).Err(); err != nil {
+		return fmt.Errorf("setting maxmemory: %w", err)
+	}
+
+	return nil
+}
+
+// SetMasterAuth connects to Redis and sets masterauth, which takes effect the
+// next time the node connects to a master.
+func (r redisManager) SetMasterAuth(ctx context.Context, host, adminPass, masterAuth string) error {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	if err := rdb.Do(ctx, "CONFIG", "SET", "masterauth", masterAuth).Err(); err != nil {
+		return fmt.Errorf("setting masterauth: %w", err)
+	}
+
+	return nil
+}
+
+// SentinelMaster connects to a Sentinel and looks up the current master.
+func (r redisManager) SentinelMaster(ctx context.Context, sentinelHost, adminPass, masterName string) (string, error) {
+	sentinel := openSentinel(sentinelHost, adminPass)
+	defer sentinel.Close()
+
+	addr, err := sentinel.GetMasterAddrByName(ctx, masterName).Result()
+	if err != nil {
+		return "", fmt.Errorf("looking up master %q: %w", masterName, err)
+	}
+	return addr[0], nil
+}
+
+// AddSentinelPassword connects to a Sentinel and adds password to its
+// default user. Sentinels keep no ACL file; they are started with the admin
+// password of the moment.
+func (r redisManager) AddSentinelPassword(ctx context.Context, sentinelHost, adminPass, password string) error {
+	sentinel := openSentinel(sentinelHost, adminPass)
+	defer sentinel.Close()
+
+	if err := sentinel.Process(ctx, goredis.NewStatusCmd(ctx, "ACL", "SETUSER", "default", ">"+password)); err != nil {
+		return fmt.Errorf("adding Sentinel password: %w", err)
+	}
+
+	return nil
+}
+
+// ResetSentinelPasswords connects to a Sentinel with password and removes
+// every other password of its default user.
+func (r redisManager) ResetSentinelPasswords(ctx context.Context, sentinelHost, password string) error {
+	sentinel := openSentinel(sentinelHost, password)
+	defer sentinel.Close()
+
+	if err := sentinel.Process(ctx, goredis.NewStatusCmd(ctx, "ACL", "SETUSER", "default", "resetpass", ">"+password)); err != nil {
+		return fmt.Errorf("resetting Sentinel passwords: %w", err)
+	}
+
+	return nil
+}
+
+// SetSentinelAuth connects to a Sentinel and replaces the password it uses
+// for the master, its replicas and the other Sentinels.
+func (r redisManager) SetSentinelAuth(ctx context.Context, sentinelHost, adminPass, masterName, password string) error {
+	sentinel := openSentinel(sentinelHost, adminPass)
+	defer sentinel.Close()
+
+	if err := sentinel.Set(ctx, masterName, "auth-pass", password).Err(); err != nil {
+		return fmt.Errorf("setting Sentinel auth-pass: %w", err)
+	}
+	if err := sentinel.Process(ctx, goredis.NewStatusCmd(ctx, "SENTINEL", "CONFIG", "SET", "sentinel-pass", password)); err != nil {
+		return fmt.Errorf("setting Sentinel sentinel-pass: %w", err)
+	}
+
+	return nil
+}
+
+// ClusterNodes connects to Redis and reads its view of the cluster.
+func (r redisManager) ClusterNodes(ctx context.Context, host, adminPass string) (string, error) {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	nodes, err := rdb.ClusterNodes(ctx).Result()
+	if err != nil {
+		return "", fmt.Errorf("reading cluster nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+// ClusterState connects to Redis and reads cluster_state from CLUSTER INFO.
+func (r redisManager) ClusterState(ctx context.Context, host, adminPass string) (string, error) {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	info, err := rdb.ClusterInfo(ctx).Result()
+	if err != nil {
+		return "", fmt.Errorf("reading cluster info: %w", err)
+	}
+	for line := range strings.SplitSeq(info, "\n") {
+		if state, ok := strings.CutPrefix(strings.TrimSpace(line), "cluster_state:"); ok {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("cluster info has no cluster_state")
+}
+
+// ClusterMeet connects to Redis and introduces it to the node at ip.
+func (r redisManager) ClusterMeet(ctx context.Context, host, adminPass, ip string) error {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	if err := rdb.ClusterMeet(ctx, ip, strconv.Itoa(redisPort)).Err(); err != nil {
+		return fmt.Errorf("meeting cluster node %s: %w", ip, err)
+	}
+	return nil
+}
+
+// ClusterAddSlots connects to Redis and assigns it a range of slots.
+func (r redisManager) ClusterAddSlots(ctx context.Context, host, adminPass string, slots rediscluster.SlotRange) error {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	if err := rdb.ClusterAddSlotsRange(ctx, slots.Start, slots.End).Err(); err != nil {
+		return fmt.Errorf("assigning slots %d-%d: %w", slots.Start, slots.End, err)
+	}
+	return nil
+}
+
+// ClusterReplicate connects to Redis and makes it a replica of masterID,
+// discarding any data it holds.
+func (r redisManager) ClusterReplicate(ctx context.Context, host, adminPass, masterID string) error {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	if err := rdb.ClusterReplicate(ctx, masterID).Err(); err != nil {
+		return fmt.Errorf("replicating cluster node %s: %w", masterID, err)
+	}
+	return nil
+}
+
+// ClusterForget connects to Redis and drops nodeID from its node table.
+func (r redisManager) ClusterForget(ctx context.Context, host, adminPass, nodeID string) error {
+	rdb := openRedis(host, adminPass)
+	defer rdb.Close()
+
+	if err := rdb.ClusterForget(ctx, nodeID).Err(); err != nil {
+		return fmt.Errorf("forgetting cluster node %s: %w", nodeID, err)
+	}
+	return nil
+}
+
+// redisMigrateBatch is how many keys one MIGRATE moves.
+const redisMigrateBatch = 100
+
+// ClusterMigrateSlot moves a slot the way redis-cli --cluster reshard does:
+// the destination imports it and the source migrates it, so clients are
+// redirected key by key while MIGRATE copies the keys across, and then both
+// nodes record the new owner. Keys are migrated with REPLACE, so a migration
+// interrupted part-way can simply be run again.
+func (r redisManager) ClusterMigrateSlot(ctx context.Context, adminPass string, slot int, from, to RedisClusterPeer) error {
+	src := openRedis(from.Host, adminPass)
+	defer src.Close()
+	dst := openRedis(to.Host, adminPass)
+	defer dst.Close()
+
+	if err := dst.Do(ctx, "CLUSTER", "SETSLOT", slot, "IMPORTING", from.ID).Err(); err != nil {
+		return fmt.Errorf("importing slot %d: %w", slot, err)
+	}
+	if err := src.Do(ctx, "CLUSTER", "SETSLOT", slot, "MIGRATING", to.ID).Err(); err != nil {
+		return fmt.Errorf("migrating slot %d: %w", slot, err)
+	}
+	for {
+		keys, err := src.ClusterGetKeysInSlot(ctx, slot, redisMigrateBatch).Result()
+		if err != nil {
+			return fmt.Errorf("listing keys in slot %d: %w", slot, err)
+		}
+		if len(keys) == 0 {
+			break
+		}
+		args := []interface{}{"MIGRATE", to.IP, redisPort, "", 0, 5000, "REPLACE", "AUTH2", "default", adminPass, "KEYS"}
+		for _, key := range keys {
+			args = append(args, key)
+		}
+		if err := src.Do(ctx, args...).Err(); err != nil {
+			return fmt.Errorf("migrating keys in slot %d: %w", slot, err)
+		}
+	}
+	for _, node := range []*goredis.Client{dst, src} {
+		if err := node.Do(ctx, "CLUSTER", "SETSLOT", slot, "NODE", to.ID).Err(); err != nil {
+			return fmt.Errorf("assigning slot %d: %w", slot, err)
+		}
+	}
 	return nil
 }
 
+// saveACL writes the server's ACL users to its ACL file, which is otherwise
+// only read at startup.
+func saveACL(ctx context.Context, rdb *goredis.Client) error {
+	if err := rdb.Do(ctx, "ACL", "SAVE").Err(); err != nil {
+		return fmt.Errorf("saving ACL file: %w", err)
+	}
+	return nil
+}
+
+// redisReplyMap converts a map reply to a map keyed by field name. Replies
+// come as a map over RESP3 and as a flat list of fields and values over
+// RESP2.
+func redisReplyMap(reply interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range reply {
+			fields[fmt.Sprint(k)] = v
+		}
+	case []interface{}:
+		if len(reply)%2 != 0 {
+			return nil, fmt.Errorf("odd number of elements in reply")
+		}
+		for i := 0; i < len(reply); i += 2 {
+			fields[fmt.Sprint(reply[i])] = reply[i+1]
+		}
+	default:
+		return nil, fmt.Errorf("unexpected reply type %T", reply)
+	}
+	return fields, nil
+}
+
+// redisReplyStrings converts a list reply to strings.
+func redisReplyStrings(reply interface{}) []string {
+	items, _ := reply.([]interface{})
+	strs := make([]string, 0, len(items))
+	for _, item := range items {
+		strs = append(strs, fmt.Sprint(item))
+	}
+	return strs
+}
+
 // openRedis opens a Redis client authenticated as the default admin user.
 func openRedis(host, adminPass string) *goredis.Client {
 	return goredis.NewClient(&goredis.Options{
@@ -117,3 +482,16 @@ func openRedis(host, adminPass string) *goredis.Client {
 		WriteTimeout: 5 * time.Second,
 	})
 }
+
+// openSentinel opens a client to a Sentinel, which requires the admin
+// password like the servers it monitors.
+func openSentinel(host, adminPass string) *goredis.SentinelClient {
+	return goredis.NewSentinelClient(&goredis.Options{
+		Addr:         fmt.Sprintf("%s:%d", host, redisSentinelPort),
+		Username:     "default",
+		Password:     adminPass,
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+	})
+}
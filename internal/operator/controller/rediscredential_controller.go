@@ -3,16 +3,23 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/benjamin-wright/db-operator/internal/redisacl"
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
 
@@ -20,15 +27,26 @@ const (
 	// redisCredentialFinalizerName is added to RedisCredential resources to ensure
 	// the Redis ACL user and credential Secret are cleaned up before deletion.
 	redisCredentialFinalizerName = "games-hub.io/redis-credential"
+
+	// redisACLInSyncCondition reports whether the ACL user on every node
+	// matches the spec, and where it was last corrected.
+	redisACLInSyncCondition = "ACLInSync"
+
+	// redisACLResyncInterval is how often a Ready credential is reconciled to
+	// catch ACL users changed by hand since the last pass.
+	redisACLResyncInterval = 10 * time.Minute
 )
 
 // RedisCredentialReconciler reconciles a RedisCredential object.
 // It creates a Redis ACL user inside the target RedisDatabase instance and writes
-// the generated credentials into a Kubernetes Secret.
+// the generated credentials into a Kubernetes Secret. ACL users found to
+// differ from the spec are corrected, and each correction is recorded as an
+// Event on the RedisCredential.
 type RedisCredentialReconciler struct {
 	InstanceName string
 	client       redisCredentialClient
 	redisMgr     RedisManager
+	recorder     events.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=games-hub.io,resources=rediscredentials,verbs=get;list;watch;create;update;patch;delete
@@ -36,6 +54,7 @@ type RedisCredentialReconciler struct {
 // +kubebuilder:rbac:groups=games-hub.io,resources=rediscredentials/finalizers,verbs=update
 // +kubebuilder:rbac:groups=games-hub.io,resources=redisdatabases,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile handles create/update/delete events for RedisCredential resources.
 func (r *RedisCredentialReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -119,7 +138,20 @@ func (r *RedisCredentialReconciler) reconcileRedisCredential(ctx context.Context
 	}
 
 	adminPass := string(adminSecret.Data["REDIS_PASSWORD"])
-	host := redisHost(&rdb)
+
+	var sentinelSecret *corev1.Secret
+	if rdb.Spec.Sentinel {
+		sentinelSecret = &corev1.Secret{}
+		sentinelSecretKey := types.NamespacedName{Name: redisSentinelSecretName(&rdb), Namespace: rdb.Namespace}
+		found, err := r.client.get(ctx, sentinelSecretKey, sentinelSecret)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("fetching Sentinel Secret %q: %w", sentinelSecretKey.Name, err)
+		}
+		if !found {
+			return r.setPhase(rcred, v1alpha1.RedisCredentialPhasePending,
+				"SentinelSecretNotFound", fmt.Sprintf("Sentinel Secret %q not yet visible in cache", sentinelSecretKey.Name)), nil
+		}
+	}
 
 	var existingSecret corev1.Secret
 	credSecretKey := types.NamespacedName{Name: rcred.Spec.SecretName, Namespace: rcred.Namespace}
@@ -139,11 +171,34 @@ func (r *RedisCredentialReconciler) reconcileRedisCredential(ctx context.Context
 		}
 	}
 
-	if err := r.redisMgr.EnsureACLUser(ctx, host, adminPass, rcred.Spec.Username, password,
-		rcred.Spec.KeyPatterns, rcred.Spec.ACLCategories, rcred.Spec.Commands); err != nil {
-		return r.setPhase(rcred, v1alpha1.RedisCredentialPhaseFailed,
-			"UserCreationFailed", err.Error()), err
+	// Once the user has been applied for this generation of the spec, any
+	// difference found on a node is drift rather than a pending change.
+	ready := meta.FindStatusCondition(rcred.Status.Conditions, "Ready")
+	applied := secretFound && ready != nil && ready.Status == metav1.ConditionTrue &&
+		ready.ObservedGeneration == rcred.Generation
+
+	// ACL users are not replicated, so every node gets its own copy; a
+	// replica promoted by Sentinel or by a cluster failover then already
+	// knows the user, and so does every shard of a cluster.
+	spec := redisacl.Spec{
+		Password:    password,
+		KeyPatterns: rcred.Spec.KeyPatterns,
+		Categories:  rcred.Spec.ACLCategories,
+		Commands:    rcred.Spec.Commands,
+	}
+	var corrected []string
+	for _, host := range redisPodHosts(&rdb) {
+		fixed, err := r.reconcileACLUser(ctx, rcred, host, adminPass, spec, applied)
+		if fixed {
+			corrected = append(corrected, host)
+		}
+		if err != nil {
+			recordRedisACLDrift(ctx, &rcred.Status.Conditions, rcred.Generation, rcred.Spec.Username, corrected, err)
+			return r.setPhase(rcred, v1alpha1.RedisCredentialPhaseFailed,
+				"UserCreationFailed", err.Error()), err
+		}
 	}
+	recordRedisACLDrift(ctx, &rcred.Status.Conditions, rcred.Generation, rcred.Spec.Username, corrected, nil)
 
 	if !secretFound {
 		secret := &corev1.Secret{
@@ -152,21 +207,61 @@ func (r *RedisCredentialReconciler) reconcileRedisCredential(ctx context.Context
 				Namespace: rcred.Namespace,
 				Labels:    labelsForRedisCredential(rcred, r.InstanceName),
 			},
-			StringData: map[string]string{
-				"REDIS_USERNAME": rcred.Spec.Username,
-				"REDIS_PASSWORD": password,
-				"REDIS_HOST":     host,
-				"REDIS_PORT":     fmt.Sprintf("%d", redisPort),
+			Data: map[string][]byte{
+				"REDIS_USERNAME": []byte(rcred.Spec.Username),
+				"REDIS_PASSWORD": []byte(password),
 			},
 		}
+		updateRedisConnectionKeys(secret, &rdb, sentinelSecret)
 		if err := r.client.createOwned(ctx, rcred, secret); err != nil {
 			return ctrl.Result{}, fmt.Errorf("creating credential Secret: %w", err)
 		}
+	} else if updateRedisConnectionKeys(&existingSecret, &rdb, sentinelSecret) {
+		if err := r.client.update(ctx, &existingSecret); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating credential Secret: %w", err)
+		}
 	}
 
 	rcred.Status.SecretName = rcred.Spec.SecretName
-	return r.setPhase(rcred, v1alpha1.RedisCredentialPhaseReady,
-		"CredentialReady", "Redis ACL user and credential Secret are ready"), nil
+	result := r.setPhase(rcred, v1alpha1.RedisCredentialPhaseReady,
+		"CredentialReady", "Redis ACL user and credential Secret are ready")
+	result.RequeueAfter = redisACLResyncInterval
+	return result, nil
+}
+
+// reconcileACLUser compares the ACL user on the node at host with spec and
+// re-applies it when it is missing or differs, reporting whether a user that
+// had already been applied was corrected. Each correction is recorded as an
+// Event: a missing user, as on a new node, is restored as Normal; rules
+// changed by hand are Warning.
+func (r *RedisCredentialReconciler) reconcileACLUser(ctx context.Context, rcred *v1alpha1.RedisCredential, host, adminPass string, spec redisacl.Spec, applied bool) (bool, error) {
+	user, err := r.redisMgr.GetACLUser(ctx, host, adminPass, rcred.Spec.Username)
+	if err != nil {
+		return false, err
+	}
+	var drift []string
+	if user != nil {
+		if drift = redisacl.Drift(*user, spec); len(drift) == 0 {
+			return false, nil
+		}
+	}
+
+	if err := r.redisMgr.EnsureACLUser(ctx, host, adminPass, rcred.Spec.Username, spec.Password,
+		spec.KeyPatterns, spec.Categories, spec.Commands); err != nil {
+		return false, err
+	}
+	if !applied {
+		return false, nil
+	}
+
+	if user == nil {
+		r.recorder.Eventf(rcred, nil, corev1.EventTypeNormal, "ACLUserRestored", "RestoreACLUser",
+			"restored missing ACL user %q on %s", rcred.Spec.Username, host)
+	} else {
+		r.recorder.Eventf(rcred, nil, corev1.EventTypeWarning, "ACLDriftCorrected", "ReapplyACLUser",
+			"re-applied ACL user %q on %s: %s differed from the spec", rcred.Spec.Username, host, strings.Join(drift, ", "))
+	}
+	return true, nil
 }
 
 // reconcileDelete cleans up the Redis ACL user and credential Secret, then removes the finalizer.
@@ -189,10 +284,11 @@ func (r *RedisCredentialReconciler) reconcileDelete(ctx context.Context, rcred *
 		adminSecretKey := types.NamespacedName{Name: rdb.Status.SecretName, Namespace: rdb.Namespace}
 		if adminFound, _ := r.client.get(ctx, adminSecretKey, &adminSecret); adminFound {
 			adminPass := string(adminSecret.Data["REDIS_PASSWORD"])
-			host := redisHost(&rdb)
-			if err := r.redisMgr.DropACLUser(ctx, host, adminPass, rcred.Spec.Username); err != nil {
-				logger.Error(err, "failed to drop Redis ACL user during cleanup", "username", rcred.Spec.Username)
-				// Continue — the database may be going away too.
+			for _, host := range redisPodHosts(&rdb) {
+				if err := r.redisMgr.DropACLUser(ctx, host, adminPass, rcred.Spec.Username); err != nil {
+					logger.Error(err, "failed to drop Redis ACL user during cleanup", "username", rcred.Spec.Username, "host", host)
+					// Continue — the database may be going away too.
+				}
 			}
 		}
 	}
@@ -219,6 +315,46 @@ func (r *RedisCredentialReconciler) reconcileDelete(ctx context.Context, rcred *
 	return ctrl.Result{}, nil
 }
 
+// recordRedisACLDrift sets the ACLInSync condition in conditions from the
+// outcome of comparing username on every node with the spec. A correction
+// stays reported until the next one, so it remains visible after later
+// reconciles find nothing to correct.
+func recordRedisACLDrift(ctx context.Context, conditions *[]metav1.Condition, generation int64, username string, corrected []string, err error) {
+	if len(corrected) > 0 {
+		log.FromContext(ctx).Info("corrected Redis ACL user drift", "username", username, "hosts", corrected)
+		// Drop the old condition so LastTransitionTime records this correction.
+		meta.RemoveStatusCondition(conditions, redisACLInSyncCondition)
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               redisACLInSyncCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             "DriftCorrected",
+			Message:            "re-applied the ACL user on " + strings.Join(corrected, ", "),
+			ObservedGeneration: generation,
+		})
+	}
+	if err != nil {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               redisACLInSyncCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             "CorrectionFailed",
+			Message:            err.Error(),
+			ObservedGeneration: generation,
+		})
+		return
+	}
+
+	current := meta.FindStatusCondition(*conditions, redisACLInSyncCondition)
+	if current == nil || current.Status != metav1.ConditionTrue {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               redisACLInSyncCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             "InSync",
+			Message:            "the ACL user on every node matches the spec",
+			ObservedGeneration: generation,
+		})
+	}
+}
+
 // setPhase mutates the RedisCredential status phase and condition in memory.
 // The caller is responsible for persisting via r.Status().Update().
 func (r *RedisCredentialReconciler) setPhase(
@@ -250,9 +386,105 @@ func (r *RedisCredentialReconciler) setPhase(
 
 // ---------- Helpers ----------
 
-// redisHost returns the in-cluster DNS name for the Redis pod backing the given RedisDatabase.
+// redisHost returns the in-cluster DNS name of the current master pod of the
+// given RedisDatabase.
 func redisHost(rdb *v1alpha1.RedisDatabase) string {
-	return fmt.Sprintf("%s-0.%s.%s.svc.cluster.local", rdb.Name, rdb.Name, rdb.Namespace)
+	return redisPodHost(rdb, rdb.Status.MasterOrdinal)
+}
+
+// redisPodHost returns the stable DNS name of one Redis pod, as published by
+// the headless Service: <name>-<ordinal>.<name>.<ns>.svc.cluster.local.
+func redisPodHost(rdb *v1alpha1.RedisDatabase, ordinal int32) string {
+	return fmt.Sprintf("%s-%d.%s", redisStatefulSetName(rdb), ordinal, redisServiceDomain(redisServiceName(rdb), rdb.Namespace))
+}
+
+// redisPodHosts returns the DNS names of every Redis pod. ACL users and the
+// admin password are per-node state, so each of them is configured directly.
+func redisPodHosts(rdb *v1alpha1.RedisDatabase) []string {
+	hosts := make([]string, 0, redisPodCount(rdb))
+	for i := range redisPodCount(rdb) {
+		hosts = append(hosts, redisPodHost(rdb, i))
+	}
+	return hosts
+}
+
+// redisPodAddrs returns host:port of every Redis pod.
+func redisPodAddrs(rdb *v1alpha1.RedisDatabase) []string {
+	addrs := redisPodHosts(rdb)
+	for i, host := range addrs {
+		addrs[i] = fmt.Sprintf("%s:%d", host, redisPort)
+	}
+	return addrs
+}
+
+// redisSentinelHosts returns the DNS names of the Sentinel pods.
+func redisSentinelHosts(rdb *v1alpha1.RedisDatabase) []string {
+	hosts := make([]string, 0, redisSentinelReplicas)
+	for i := range redisSentinelReplicas {
+		hosts = append(hosts, fmt.Sprintf("%s-%d.%s", redisSentinelName(rdb), i,
+			redisServiceDomain(redisSentinelName(rdb), rdb.Namespace)))
+	}
+	return hosts
+}
+
+// redisSentinelAddrs returns the host:port address of every Sentinel.
+func redisSentinelAddrs(rdb *v1alpha1.RedisDatabase) []string {
+	addrs := redisSentinelHosts(rdb)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("%s:%d", addrs[i], redisSentinelPort)
+	}
+	return addrs
+}
+
+// redisServiceDomain returns the domain a headless Service publishes its pods
+// under.
+func redisServiceDomain(service, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace)
+}
+
+// updateRedisConnectionKeys brings the connection keys of a credential Secret
+// in line with the database's current master, Sentinels and cluster nodes,
+// reporting whether anything changed. REDIS_HOST follows the master after a
+// failover; Sentinel-aware clients should prefer REDIS_SENTINEL_HOSTS and
+// REDIS_MASTER_NAME, which stay the same, and log in to the Sentinels with
+// the read-only login copied from sentinelSecret. Cluster clients use
+// REDIS_CLUSTER_NODES as their seed list and discover the slots from there.
+func updateRedisConnectionKeys(secret *corev1.Secret, rdb *v1alpha1.RedisDatabase, sentinelSecret *corev1.Secret) bool {
+	desired := map[string]string{
+		"REDIS_HOST": redisHost(rdb),
+		"REDIS_PORT": fmt.Sprintf("%d", redisPort),
+	}
+	if rdb.Spec.Sentinel {
+		desired["REDIS_SENTINEL_HOSTS"] = strings.Join(redisSentinelAddrs(rdb), ",")
+		desired["REDIS_MASTER_NAME"] = redisSentinelMasterName(rdb)
+		desired["REDIS_SENTINEL_USERNAME"] = string(sentinelSecret.Data["REDIS_SENTINEL_USERNAME"])
+		desired["REDIS_SENTINEL_PASSWORD"] = string(sentinelSecret.Data["REDIS_SENTINEL_PASSWORD"])
+	}
+	if redisClusterMode(rdb) {
+		desired["REDIS_CLUSTER_NODES"] = strings.Join(redisPodAddrs(rdb), ",")
+	}
+	changed := false
+	for _, key := range []string{"REDIS_SENTINEL_HOSTS", "REDIS_MASTER_NAME", "REDIS_SENTINEL_USERNAME",
+		"REDIS_SENTINEL_PASSWORD", "REDIS_CLUSTER_NODES"} {
+		if _, want := desired[key]; want {
+			continue
+		}
+		if _, ok := secret.Data[key]; ok {
+			delete(secret.Data, key)
+			changed = true
+		}
+	}
+	for key, value := range desired {
+		if string(secret.Data[key]) == value {
+			continue
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[key] = []byte(value)
+		changed = true
+	}
+	return changed
 }
 
 // labelsForRedisCredential returns the standard label set for resources owned by a RedisCredential.
@@ -265,12 +497,61 @@ func labelsForRedisCredential(rcred *v1alpha1.RedisCredential, instanceName stri
 	}
 }
 
+// credentialsForDatabaseSecret maps the admin Secret of a RedisDatabase to
+// every RedisCredential that targets that database, so none keeps acting on a
+// rotated admin password.
+func (r *RedisCredentialReconciler) credentialsForDatabaseSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels["app.kubernetes.io/name"] != "redis" {
+		return nil
+	}
+
+	var creds v1alpha1.RedisCredentialList
+	if err := r.client.list(ctx, &creds, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "listing RedisCredentials for database Secret", "secret", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cred := range creds.Items {
+		if cred.Spec.DatabaseRef == labels["app.kubernetes.io/instance"] {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cred)})
+		}
+	}
+	return requests
+}
+
+// credentialsForDatabase maps a RedisDatabase to every RedisCredential that
+// targets it. Its status changes when a pod restarts or is added, and when
+// Sentinel promotes a new master, so ACL users reach pods that lack them and
+// credential Secrets follow the master.
+func (r *RedisCredentialReconciler) credentialsForDatabase(ctx context.Context, obj client.Object) []reconcile.Request {
+	var creds v1alpha1.RedisCredentialList
+	if err := r.client.list(ctx, &creds, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "listing RedisCredentials for database", "database", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cred := range creds.Items {
+		if cred.Spec.DatabaseRef == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cred)})
+		}
+	}
+	return requests
+}
+
 // SetupWithManager registers the RedisCredentialReconciler with the controller manager.
+// The admin Secret of the target database is watched as well, so credentials
+// are reconciled again as soon as its password changes, and so is the
+// database itself.
 func (r *RedisCredentialReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.client = redisCredentialClient{inner: mgr.GetClient(), scheme: mgr.GetScheme()}
-	r.redisMgr = redisManager{}
+	r.redisMgr = instrumentedRedisManager{inner: redisManager{}}
+	r.recorder = mgr.GetEventRecorder("rediscredential-controller")
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.RedisCredential{}).
 		Owns(&corev1.Secret{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.credentialsForDatabaseSecret),
+			builder.WithPredicates(managedByInstance(r.InstanceName))).
+		Watches(&v1alpha1.RedisDatabase{}, handler.EnqueueRequestsFromMapFunc(r.credentialsForDatabase)).
 		Complete(r)
 }
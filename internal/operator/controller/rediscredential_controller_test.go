@@ -14,6 +14,8 @@ import (
 
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -356,6 +358,153 @@ var _ = Describe("RedisCredentialReconciler", func() {
 		})
 	})
 
+	// ── ACL drift: hand edits are reverted, users survive restarts ───────────
+	Context("when the ACL user drifts from the spec", Ordered, func() {
+		var (
+			ns                *corev1.Namespace
+			rdb               *v1alpha1.RedisDatabase
+			rcred             *v1alpha1.RedisCredential
+			dbLookup          types.NamespacedName
+			adminSecretLookup types.NamespacedName
+			credLookup        types.NamespacedName
+			credSecretLookup  types.NamespacedName
+		)
+
+		// aclEvents returns the Events recorded against the credential with
+		// the given reason.
+		aclEvents := func(g Gomega, reason string) []eventsv1.Event {
+			var list eventsv1.EventList
+			g.Expect(K8sClient.List(Ctx, &list, client.InNamespace(ns.Name))).To(Succeed())
+			var found []eventsv1.Event
+			for _, event := range list.Items {
+				if event.Regarding.Name == rcred.Name && event.Reason == reason {
+					found = append(found, event)
+				}
+			}
+			return found
+		}
+
+		forceReconcile := func() {
+			var fetched v1alpha1.RedisCredential
+			Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+			if fetched.Annotations == nil {
+				fetched.Annotations = map[string]string{}
+			}
+			fetched.Annotations["test/force-reconcile"] = fmt.Sprintf("%d", time.Now().UnixNano())
+			Expect(K8sClient.Update(Ctx, &fetched)).To(Succeed())
+		}
+
+		BeforeAll(func() {
+			ns, rdb, dbLookup, adminSecretLookup = NewRedisDatabase("rcred-drift-db")
+			WaitForRedisDatabase(dbLookup)
+
+			rcred = &v1alpha1.RedisCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rcred-drift",
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.RedisCredentialSpec{
+					DatabaseRef:   rdb.Name,
+					Username:      "driftuser",
+					SecretName:    "rcred-drift-secret",
+					KeyPatterns:   []string{"drift:*"},
+					ACLCategories: []v1alpha1.RedisACLCategory{v1alpha1.RedisACLCategoryRead, v1alpha1.RedisACLCategoryWrite},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, rcred)).To(Succeed())
+			credLookup = types.NamespacedName{Name: rcred.Name, Namespace: ns.Name}
+			credSecretLookup = types.NamespacedName{Name: rcred.Spec.SecretName, Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisCredential
+				g.Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisCredentialPhaseReady))
+				g.Expect(meta.IsStatusConditionTrue(fetched.Status.Conditions, "ACLInSync")).To(BeTrue())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should save the ACL user to the ACL file", func() {
+			redisCli, close := ConnectToRedisDatabase(dbLookup, adminSecretLookup)
+			defer close()
+
+			Expect(redisCli.ConfigGet(Ctx, "aclfile").Val()).To(HaveKeyWithValue("aclfile", "/data/users.acl"))
+			// Reloading the file would drop a user that was never saved.
+			Expect(redisCli.Do(Ctx, "ACL", "LOAD").Err()).To(Succeed())
+			Expect(redisCli.Do(Ctx, "ACL", "GETUSER", "driftuser").Err()).To(Succeed())
+		})
+
+		It("should revert rules changed by hand and record an Event", func() {
+			redisCli, close := ConnectToRedisDatabase(dbLookup, adminSecretLookup)
+			defer close()
+			Expect(redisCli.Do(Ctx, "ACL", "SETUSER", "driftuser", "allkeys", "+@all").Err()).To(Succeed())
+
+			forceReconcile()
+
+			Eventually(func(g Gomega) {
+				user, err := redisCli.Do(Ctx, "ACL", "GETUSER", "driftuser").Result()
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(fmt.Sprint(user)).To(ContainSubstring("-@all +@read +@write"))
+				g.Expect(fmt.Sprint(user)).NotTo(ContainSubstring("~*"))
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				events := aclEvents(g, "ACLDriftCorrected")
+				g.Expect(events).NotTo(BeEmpty())
+				g.Expect(events[0].Type).To(Equal(corev1.EventTypeWarning))
+				g.Expect(events[0].Note).To(ContainSubstring("commands, keys"))
+			}, Timeout, Interval).Should(Succeed())
+
+			var fetched v1alpha1.RedisCredential
+			Expect(K8sClient.Get(Ctx, credLookup, &fetched)).To(Succeed())
+			cond := meta.FindStatusCondition(fetched.Status.Conditions, "ACLInSync")
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal("DriftCorrected"))
+		})
+
+		It("should restore a deleted ACL user and record an Event", func() {
+			redisCli, close := ConnectToRedisDatabase(dbLookup, adminSecretLookup)
+			defer close()
+			Expect(redisCli.Do(Ctx, "ACL", "DELUSER", "driftuser").Err()).To(Succeed())
+
+			forceReconcile()
+
+			Eventually(func(g Gomega) {
+				g.Expect(aclEvents(g, "ACLUserRestored")).NotTo(BeEmpty())
+				rc, close := ConnectToRedisDatabase(dbLookup, credSecretLookup)
+				defer close()
+				g.Expect(rc.Set(Ctx, "drift:foo", "bar", 0).Err()).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should keep the ACL user across a pod restart", func() {
+			before := len(aclEvents(Default, "ACLUserRestored"))
+
+			var pod corev1.Pod
+			podKey := types.NamespacedName{Name: rdb.Name + "-0", Namespace: ns.Name}
+			Expect(K8sClient.Get(Ctx, podKey, &pod)).To(Succeed())
+			Expect(K8sClient.Delete(Ctx, &pod)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var restarted corev1.Pod
+				g.Expect(K8sClient.Get(Ctx, podKey, &restarted)).To(Succeed())
+				g.Expect(restarted.UID).NotTo(Equal(pod.UID))
+				rc, close := ConnectToRedisDatabase(dbLookup, credSecretLookup)
+				defer close()
+				g.Expect(rc.Set(Ctx, "drift:foo", "bar", 0).Err()).To(Succeed())
+			}, 3*Timeout, Interval).Should(Succeed())
+
+			// The user came back from the ACL file, not from the operator.
+			Expect(aclEvents(Default, "ACLUserRestored")).To(HaveLen(before))
+		})
+	})
+
 	// ── Instance label filtering ─────────────────────────────────────────────
 	Context("when a RedisCredential has no operator-instance label", Ordered, func() {
 		var (
@@ -2,18 +2,141 @@ package controller
 
 import (
 	"fmt"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/benjamin-wright/db-operator/internal/pgconfig"
+	"github.com/benjamin-wright/db-operator/internal/redisconfig"
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
 
+// redisConfigMountPath is where the instance ConfigMap is mounted in the
+// Redis pod.
+const redisConfigMountPath = "/etc/redis"
+
+// redisConfKey is the server configuration file inside the instance ConfigMap.
+const redisConfKey = "redis.conf"
+
+// redisMasterHostKey holds the host of the current master inside the
+// instance ConfigMap. Pods fall back to it when no Sentinel answers.
+const redisMasterHostKey = "master-host"
+
+// redisConfigChecksumAnnotation digests redis.conf on the pod template, so a
+// configuration change rolls the pod.
+const redisConfigChecksumAnnotation = "checksum/config"
+
+// redisAdminMountPath is where the admin Secret is mounted in the Redis and
+// Sentinel pods.
+// The kubelet refreshes the files when the Secret changes, unlike environment
+// variables, so probes keep authenticating after the password rotates.
+const redisAdminMountPath = "/etc/redis-admin"
+
+// redisProbeCommand checks that Redis answers an authenticated PING.
+const redisProbeCommand = `redis-cli -a "$(cat ` + redisAdminMountPath + `/REDIS_PASSWORD)" --no-auth-warning ping`
+
+// redisACLFileScript prepares the ACL file on the pod's volume before Redis
+// loads it. Redis takes the default user from the file rather than from
+// --requirepass, so its entry is rewritten to accept the admin password the
+// pod was started with; users the operator created are kept as they were.
+const redisACLFileScript = `acl=` + redisconfig.ACLFile + `
+touch "${acl}"
+grep -v '^user default ' "${acl}" > "${acl}.tmp" || true
+printf 'user default on #%s ~* &* +@all\n' "$(printf '%s' "${REDIS_PASSWORD}" | sha256sum | cut -d ' ' -f 1)" >> "${acl}.tmp"
+mv "${acl}.tmp" "${acl}"
+`
+
+// redisStartScript runs in every Redis pod. The master is whichever host a
+// Sentinel reports, or the ConfigMap's master host when there are no
+// Sentinels or none answers yet; every other pod starts as its replica. Pods
+// announce their DNS name rather than their IP, so the addresses Sentinel
+// hands out survive pod restarts. The admin password doubles as masterauth,
+// since replicas authenticate to the master as the default user.
+const redisStartScript = `set -eu
+` + redisACLFileScript + `self="${HOSTNAME}.${REDIS_SERVICE_DOMAIN}"
+master="$(cat /etc/redis/master-host)"
+for sentinel in ${REDIS_SENTINEL_HOSTS:-}; do
+  found="$(redis-cli -t 2 -h "${sentinel%:*}" -p "${sentinel##*:}" -a "${REDIS_PASSWORD}" --no-auth-warning SENTINEL get-master-addr-by-name "${REDIS_MASTER_NAME}" 2>/dev/null | head -n 1)"
+  if [ -n "${found}" ]; then
+    master="${found}"
+    break
+  fi
+done
+set -- redis-server /etc/redis/redis.conf --requirepass "${REDIS_PASSWORD}" --masterauth "${REDIS_PASSWORD}" --replica-announce-ip "${self}"
+if [ "${master}" != "${self}" ]; then
+  set -- "$@" --replicaof "${master}" 6379
+fi
+exec "$@"
+`
+
+// redisClusterStartScript runs in every pod of a cluster-mode database. The
+// operator joins the nodes and assigns their roles once they are up, so each
+// pod only announces its DNS name for redirects and authenticates to the
+// master it is later told to replicate.
+const redisClusterStartScript = `set -eu
+` + redisACLFileScript + `self="${HOSTNAME}.${REDIS_SERVICE_DOMAIN}"
+exec redis-server /etc/redis/redis.conf --requirepass "${REDIS_PASSWORD}" --masterauth "${REDIS_PASSWORD}" --cluster-announce-hostname "${self}"
+`
+
+// redisSentinelStartScript runs in every Sentinel pod. A Sentinel joining a
+// running group asks its peers for the master, so it does not monitor a pod
+// that has since been demoted; the first Sentinels fall back to the
+// ConfigMap's master host. Sentinel rewrites its configuration file, so the
+// file is generated into an emptyDir rather than mounted read-only. Sentinels
+// require the admin password, from clients and from each other, and also
+// accept the read-only client login of the Sentinel Secret.
+const redisSentinelStartScript = `set -eu
+self="${HOSTNAME}.${REDIS_SENTINEL_SERVICE_DOMAIN}"
+master="$(cat /etc/redis/master-host)"
+for sentinel in ${REDIS_SENTINEL_HOSTS}; do
+  if [ "${sentinel%:*}" = "${self}" ]; then
+    continue
+  fi
+  found="$(redis-cli -t 2 -h "${sentinel%:*}" -p "${sentinel##*:}" -a "${REDIS_PASSWORD}" --no-auth-warning SENTINEL get-master-addr-by-name "${REDIS_MASTER_NAME}" 2>/dev/null | head -n 1)"
+  if [ -n "${found}" ]; then
+    master="${found}"
+    break
+  fi
+done
+until getent hosts "${master}" >/dev/null; do sleep 2; done
+cat > /data/sentinel.conf <<EOF
+port 26379
+requirepass ${REDIS_PASSWORD}
+user ${REDIS_SENTINEL_USERNAME} on >${REDIS_SENTINEL_PASSWORD} ` + redisSentinelClientRules + `
+sentinel sentinel-pass ${REDIS_PASSWORD}
+sentinel resolve-hostnames yes
+sentinel announce-hostnames yes
+sentinel announce-ip ${self}
+sentinel monitor ${REDIS_MASTER_NAME} ${master} 6379 2
+sentinel auth-pass ${REDIS_MASTER_NAME} ${REDIS_PASSWORD}
+sentinel down-after-milliseconds ${REDIS_MASTER_NAME} 5000
+sentinel failover-timeout ${REDIS_MASTER_NAME} 60000
+sentinel parallel-syncs ${REDIS_MASTER_NAME} 1
+EOF
+exec redis-sentinel /data/sentinel.conf
+`
+
+// redisSentinelProbeCommand checks that Sentinel answers an authenticated
+// PING.
+const redisSentinelProbeCommand = `redis-cli -p 26379 -a "$(cat ` + redisAdminMountPath + `/REDIS_PASSWORD)" --no-auth-warning ping`
+
+// redisSentinelClientUser is the Sentinel login credential Secrets carry, so
+// Sentinel-aware clients can find the master without the admin password.
+const redisSentinelClientUser = "sentinel_client"
+
+// redisSentinelClientRules let redisSentinelClientUser look up the master,
+// replicas and Sentinels and follow failover events, and nothing else.
+const redisSentinelClientRules = "allchannels -@all +ping +hello +auth +client|setname +client|setinfo " +
+	"+subscribe +psubscribe +unsubscribe +punsubscribe " +
+	"+sentinel|get-master-addr-by-name +sentinel|master +sentinel|replicas +sentinel|slaves +sentinel|sentinels"
+
 // redisDatabaseBuilder constructs the desired Kubernetes resources for a
 // RedisDatabase instance. It owns the "how" — the shape of each resource —
 // leaving the reconciler free to own the "when".
@@ -42,6 +165,51 @@ func (b redisDatabaseBuilder) desiredAdminSecret(rdb *v1alpha1.RedisDatabase) (*
 	return secret, nil
 }
 
+// desiredMetricsSecret holds the password of redisExporterUser. It is never
+// rotated: the user can only read server statistics.
+func (b redisDatabaseBuilder) desiredMetricsSecret(rdb *v1alpha1.RedisDatabase) (*corev1.Secret, error) {
+	password, err := generatePassword(24)
+	if err != nil {
+		return nil, fmt.Errorf("generating metrics password: %w", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisMetricsSecretName(rdb),
+			Namespace: rdb.Namespace,
+			Labels:    labelsForRedisDatabase(rdb, b.instanceName),
+		},
+		Data: map[string][]byte{
+			"REDIS_USERNAME": []byte(redisExporterUser),
+			"REDIS_PASSWORD": []byte(password),
+		},
+	}
+	_ = controllerutil.SetControllerReference(rdb, secret, b.scheme)
+	return secret, nil
+}
+
+// desiredSentinelSecret holds the password of redisSentinelClientUser, which
+// the credential Secrets copy. It is never rotated: the user can only look up
+// addresses.
+func (b redisDatabaseBuilder) desiredSentinelSecret(rdb *v1alpha1.RedisDatabase) (*corev1.Secret, error) {
+	password, err := generatePassword(24)
+	if err != nil {
+		return nil, fmt.Errorf("generating Sentinel password: %w", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisSentinelSecretName(rdb),
+			Namespace: rdb.Namespace,
+			Labels:    labelsForRedisSentinel(rdb, b.instanceName),
+		},
+		Data: map[string][]byte{
+			"REDIS_SENTINEL_USERNAME": []byte(redisSentinelClientUser),
+			"REDIS_SENTINEL_PASSWORD": []byte(password),
+		},
+	}
+	_ = controllerutil.SetControllerReference(rdb, secret, b.scheme)
+	return secret, nil
+}
+
 func (b redisDatabaseBuilder) desiredService(rdb *v1alpha1.RedisDatabase) *corev1.Service {
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -62,12 +230,49 @@ func (b redisDatabaseBuilder) desiredService(rdb *v1alpha1.RedisDatabase) *corev
 			},
 		},
 	}
+	if rdb.Spec.Metrics != nil {
+		svc.Spec.Ports = append(svc.Spec.Ports, metricsServicePort(redisMetricsPort))
+	}
 	_ = controllerutil.SetControllerReference(rdb, svc, b.scheme)
 	return svc
 }
 
+func (b redisDatabaseBuilder) desiredServiceMonitor(rdb *v1alpha1.RedisDatabase) *unstructured.Unstructured {
+	return desiredServiceMonitor(rdb, b.scheme, rdb.Spec.Metrics, labelsForRedisDatabase(rdb, b.instanceName))
+}
+
+// desiredConfigMap holds the redis.conf the server starts from. The memory
+// settings are also applied live by reconcileRedisMemory; they are written
+// here so a restarted server starts with them.
+func (b redisDatabaseBuilder) desiredConfigMap(rdb *v1alpha1.RedisDatabase) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisConfigMapName(rdb),
+			Namespace: rdb.Namespace,
+			Labels:    labelsForRedisDatabase(rdb, b.instanceName),
+		},
+		Data: map[string]string{
+			redisConfKey:       redisServerConfig(rdb) + redisconfig.Memory(&rdb.Spec),
+			redisMasterHostKey: redisPodHost(rdb, rdb.Status.MasterOrdinal),
+		},
+	}
+	_ = controllerutil.SetControllerReference(rdb, cm, b.scheme)
+	return cm
+}
+
+// desiredStatefulSet starts Redis from the redis.conf in the instance
+// ConfigMap. The password stays a command-line argument so it never lands in
+// the ConfigMap. The pod template carries a checksum of the persistence and
+// cluster settings, since Redis only reads them at startup; the memory
+// settings and master host are left out of it because they change without a
+// restart.
+//
+// The StatefulSet never scales below the current master, so lowering
+// spec.replicas after a Sentinel failover cannot remove the master pod. In
+// cluster mode it runs every shard's master and replicas; which pod plays
+// which role is decided by reconcileRedisCluster.
 func (b redisDatabaseBuilder) desiredStatefulSet(rdb *v1alpha1.RedisDatabase) *appsv1.StatefulSet {
-	replicas := int32(1)
+	replicas := redisPodCount(rdb)
 
 	sts := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -84,13 +289,16 @@ func (b redisDatabaseBuilder) desiredStatefulSet(rdb *v1alpha1.RedisDatabase) *a
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: labelsForRedisDatabase(rdb, b.instanceName),
+					Annotations: map[string]string{
+						redisConfigChecksumAnnotation: pgconfig.Checksum(redisServerConfig(rdb)),
+					},
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
 						{
 							Name:    "redis",
 							Image:   redisImage,
-							Command: []string{"redis-server", "--requirepass", "$(REDIS_PASSWORD)"},
+							Command: []string{"sh", "-c", redisStartScriptFor(rdb)},
 							Ports: []corev1.ContainerPort{
 								{
 									Name:          "redis",
@@ -110,20 +318,31 @@ func (b redisDatabaseBuilder) desiredStatefulSet(rdb *v1alpha1.RedisDatabase) *a
 										},
 									},
 								},
+								{
+									Name:  "REDIS_SERVICE_DOMAIN",
+									Value: redisServiceDomain(redisServiceName(rdb), rdb.Namespace),
+								},
 							},
 							VolumeMounts: []corev1.VolumeMount{
 								{
 									Name:      "data",
-									MountPath: "/data",
+									MountPath: redisconfig.DataDir,
+								},
+								{
+									Name:      "admin",
+									MountPath: redisAdminMountPath,
+									ReadOnly:  true,
+								},
+								{
+									Name:      "config",
+									MountPath: redisConfigMountPath,
+									ReadOnly:  true,
 								},
 							},
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									Exec: &corev1.ExecAction{
-										Command: []string{
-											"sh", "-c",
-											"redis-cli -a $REDIS_PASSWORD ping",
-										},
+										Command: []string{"sh", "-c", redisProbeCommand},
 									},
 								},
 								InitialDelaySeconds: 5,
@@ -132,10 +351,7 @@ func (b redisDatabaseBuilder) desiredStatefulSet(rdb *v1alpha1.RedisDatabase) *a
 							LivenessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									Exec: &corev1.ExecAction{
-										Command: []string{
-											"sh", "-c",
-											"redis-cli -a $REDIS_PASSWORD ping",
-										},
+										Command: []string{"sh", "-c", redisProbeCommand},
 									},
 								},
 								InitialDelaySeconds: 15,
@@ -154,6 +370,7 @@ func (b redisDatabaseBuilder) desiredStatefulSet(rdb *v1alpha1.RedisDatabase) *a
 						AccessModes: []corev1.PersistentVolumeAccessMode{
 							corev1.ReadWriteOnce,
 						},
+						StorageClassName: storageClassName(rdb.Spec.StorageClassName),
 						Resources: corev1.VolumeResourceRequirements{
 							Requests: corev1.ResourceList{
 								corev1.ResourceStorage: resource.MustParse(rdb.Spec.StorageSize.String()),
@@ -164,10 +381,261 @@ func (b redisDatabaseBuilder) desiredStatefulSet(rdb *v1alpha1.RedisDatabase) *a
 			},
 		},
 	}
+	sts.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{
+			Name: "admin",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: redisAdminSecretName(rdb)},
+			},
+		},
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: redisConfigMapName(rdb)},
+				},
+			},
+		},
+	}
+	if rdb.Spec.Sentinel {
+		sts.Spec.Template.Spec.Containers[0].Env = append(sts.Spec.Template.Spec.Containers[0].Env, redisSentinelEnv(rdb)...)
+	}
+	if rdb.Spec.Metrics != nil {
+		sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, redisExporterContainer(rdb))
+	}
+	applyPodTemplate(&sts.Spec.Template, "redis", rdb.Spec.PodTemplate)
+	_ = controllerutil.SetControllerReference(rdb, sts, b.scheme)
+	return sts
+}
+
+// desiredSentinelService is the headless Service that gives each Sentinel pod
+// a stable DNS name.
+func (b redisDatabaseBuilder) desiredSentinelService(rdb *v1alpha1.RedisDatabase) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisSentinelName(rdb),
+			Namespace: rdb.Namespace,
+			Labels:    labelsForRedisSentinel(rdb, b.instanceName),
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labelsForRedisSentinel(rdb, b.instanceName),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "sentinel",
+					Port:       redisSentinelPort,
+					TargetPort: intstr.FromInt32(redisSentinelPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+	_ = controllerutil.SetControllerReference(rdb, svc, b.scheme)
+	return svc
+}
+
+// desiredSentinelStatefulSet runs the Sentinels that monitor the Redis
+// master. They start in parallel, since each only needs the master to be
+// resolvable, and take the Redis pods' node selector and tolerations but not
+// their resources.
+func (b redisDatabaseBuilder) desiredSentinelStatefulSet(rdb *v1alpha1.RedisDatabase) *appsv1.StatefulSet {
+	replicas := int32(redisSentinelReplicas)
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisSentinelName(rdb),
+			Namespace: rdb.Namespace,
+			Labels:    labelsForRedisSentinel(rdb, b.instanceName),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &replicas,
+			ServiceName:         redisSentinelName(rdb),
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labelsForRedisSentinel(rdb, b.instanceName),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelsForRedisSentinel(rdb, b.instanceName),
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "sentinel",
+							Image:   redisImage,
+							Command: []string{"sh", "-c", redisSentinelStartScript},
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "sentinel",
+									ContainerPort: redisSentinelPort,
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							Env: append([]corev1.EnvVar{
+								{
+									Name: "REDIS_PASSWORD",
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: redisAdminSecretName(rdb),
+											},
+											Key: "REDIS_PASSWORD",
+										},
+									},
+								},
+								{
+									Name: "REDIS_SENTINEL_USERNAME",
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: redisSentinelSecretName(rdb),
+											},
+											Key: "REDIS_SENTINEL_USERNAME",
+										},
+									},
+								},
+								{
+									Name: "REDIS_SENTINEL_PASSWORD",
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: redisSentinelSecretName(rdb),
+											},
+											Key: "REDIS_SENTINEL_PASSWORD",
+										},
+									},
+								},
+								{
+									Name:  "REDIS_SENTINEL_SERVICE_DOMAIN",
+									Value: redisServiceDomain(redisSentinelName(rdb), rdb.Namespace),
+								},
+							}, redisSentinelEnv(rdb)...),
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "data",
+									MountPath: "/data",
+								},
+								{
+									Name:      "admin",
+									MountPath: redisAdminMountPath,
+									ReadOnly:  true,
+								},
+								{
+									Name:      "config",
+									MountPath: redisConfigMountPath,
+									ReadOnly:  true,
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"sh", "-c", redisSentinelProbeCommand},
+									},
+								},
+								InitialDelaySeconds: 5,
+								PeriodSeconds:       5,
+							},
+							LivenessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"sh", "-c", redisSentinelProbeCommand},
+									},
+								},
+								InitialDelaySeconds: 15,
+								PeriodSeconds:       10,
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name:         "data",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+						{
+							Name: "admin",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: redisAdminSecretName(rdb)},
+							},
+						},
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: redisConfigMapName(rdb)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	applyPodPlacement(&sts.Spec.Template.Spec, rdb.Spec.PodTemplate)
 	_ = controllerutil.SetControllerReference(rdb, sts, b.scheme)
 	return sts
 }
 
+// redisServerConfig is the part of redis.conf that Redis only reads at
+// startup.
+func redisServerConfig(rdb *v1alpha1.RedisDatabase) string {
+	config := redisconfig.Build(rdb.Spec.Persistence)
+	if redisClusterMode(rdb) {
+		config += redisconfig.Cluster()
+	}
+	return config
+}
+
+// redisStartScriptFor returns the start script for the database's mode.
+func redisStartScriptFor(rdb *v1alpha1.RedisDatabase) string {
+	if redisClusterMode(rdb) {
+		return redisClusterStartScript
+	}
+	return redisStartScript
+}
+
+// redisSentinelEnv tells a pod where the Sentinels are and which master they
+// monitor.
+func redisSentinelEnv(rdb *v1alpha1.RedisDatabase) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "REDIS_SENTINEL_HOSTS", Value: strings.Join(redisSentinelAddrs(rdb), " ")},
+		{Name: "REDIS_MASTER_NAME", Value: redisSentinelMasterName(rdb)},
+	}
+}
+
+// redisExporterContainer runs redis_exporter against the server in the same
+// pod, as the read-only redisExporterUser. The exporter reads its password
+// once at startup, so the password lives in the metrics Secret, which admin
+// password rotation leaves alone.
+func redisExporterContainer(rdb *v1alpha1.RedisDatabase) corev1.Container {
+	container := metricsContainer(rdb.Spec.Metrics, redisExporterImage, redisMetricsPort)
+	container.Env = []corev1.EnvVar{
+		{
+			Name:  "REDIS_ADDR",
+			Value: fmt.Sprintf("redis://localhost:%d", redisPort),
+		},
+		{
+			Name:  "REDIS_USER",
+			Value: redisExporterUser,
+		},
+		{
+			Name: "REDIS_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: redisMetricsSecretName(rdb),
+					},
+					Key: "REDIS_PASSWORD",
+				},
+			},
+		},
+		{
+			Name:  "REDIS_EXPORTER_CONFIG_COMMAND",
+			Value: "-",
+		},
+	}
+	return container
+}
+
 // ---------- Naming helpers ----------
 
 func redisStatefulSetName(rdb *v1alpha1.RedisDatabase) string {
@@ -178,10 +646,86 @@ func redisServiceName(rdb *v1alpha1.RedisDatabase) string {
 	return rdb.Name
 }
 
+func redisSentinelName(rdb *v1alpha1.RedisDatabase) string {
+	return rdb.Name + "-sentinel"
+}
+
+// redisSentinelMasterName is the name the Sentinels monitor the master under.
+func redisSentinelMasterName(rdb *v1alpha1.RedisDatabase) string {
+	return rdb.Name
+}
+
+func redisConfigMapName(rdb *v1alpha1.RedisDatabase) string {
+	return rdb.Name + "-config"
+}
+
 func redisAdminSecretName(rdb *v1alpha1.RedisDatabase) string {
 	return rdb.Name + "-admin"
 }
 
+func redisMetricsSecretName(rdb *v1alpha1.RedisDatabase) string {
+	return rdb.Name + "-metrics"
+}
+
+func redisSentinelSecretName(rdb *v1alpha1.RedisDatabase) string {
+	return redisSentinelName(rdb) + "-client"
+}
+
+// redisReplicas returns spec.replicas, treating an unset value as one pod.
+func redisReplicas(rdb *v1alpha1.RedisDatabase) int32 {
+	if rdb.Spec.Replicas < 1 {
+		return 1
+	}
+	return rdb.Spec.Replicas
+}
+
+// redisPodCount is the number of Redis pods to run: spec.replicas, or enough
+// to keep the current master when it sits above that. In cluster mode it is
+// every shard's master plus its replicas.
+func redisPodCount(rdb *v1alpha1.RedisDatabase) int32 {
+	if redisClusterMode(rdb) {
+		return redisClusterShards(rdb) * (1 + redisClusterReplicasPerShard(rdb))
+	}
+	return max(redisReplicas(rdb), rdb.Status.MasterOrdinal+1)
+}
+
+// maxRedisReplicas mirrors the CRD's maximum for spec.replicas.
+const maxRedisReplicas = 9
+
+func redisClusterMode(rdb *v1alpha1.RedisDatabase) bool {
+	return rdb.Spec.Mode == v1alpha1.RedisModeCluster
+}
+
+// redisClusterShards returns cluster.shards, defaulting to three.
+func redisClusterShards(rdb *v1alpha1.RedisDatabase) int32 {
+	if rdb.Spec.Cluster == nil || rdb.Spec.Cluster.Shards < 3 {
+		return 3
+	}
+	return rdb.Spec.Cluster.Shards
+}
+
+// redisClusterReplicasPerShard returns cluster.replicasPerShard, defaulting
+// to one.
+func redisClusterReplicasPerShard(rdb *v1alpha1.RedisDatabase) int32 {
+	if rdb.Spec.Cluster == nil || rdb.Spec.Cluster.ReplicasPerShard == nil {
+		return 1
+	}
+	return *rdb.Spec.Cluster.ReplicasPerShard
+}
+
+// redisSentinelReplicas is the number of Sentinel pods. With a quorum of two,
+// failover still works while one of them is down.
+const redisSentinelReplicas = 3
+
+func labelsForRedisSentinel(rdb *v1alpha1.RedisDatabase, instanceName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":                                   "redis-sentinel",
+		"app.kubernetes.io/instance":                               rdb.Name,
+		"app.kubernetes.io/managed-by":                             "db-operator",
+		"db-operator.benjamin-wright.github.com/operator-instance": instanceName,
+	}
+}
+
 func labelsForRedisDatabase(rdb *v1alpha1.RedisDatabase, instanceName string) map[string]string {
 	return map[string]string{
 		"app.kubernetes.io/name":                                   "redis",
@@ -4,7 +4,10 @@ import (
 	"context"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
 
 // redisDatabaseClient encapsulates all cluster interactions for the
@@ -43,6 +46,30 @@ func (c *redisDatabaseClient) delete(ctx context.Context, obj client.Object) err
 	return nil
 }
 
+// deleteOrphan removes obj but leaves its dependents, such as a StatefulSet's
+// pods, running. A not-found error is treated as success.
+func (c *redisDatabaseClient) deleteOrphan(ctx context.Context, obj client.Object) error {
+	err := c.inner.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *redisDatabaseClient) list(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+	return c.inner.List(ctx, obj, opts...)
+}
+
 func (c *redisDatabaseClient) updateStatus(ctx context.Context, obj client.Object) error {
 	return c.inner.Status().Update(ctx, obj)
 }
+
+// redisClaimKeys returns the keys of the data PVCs for the first n Redis
+// StatefulSet ordinals.
+func redisClaimKeys(rdb *v1alpha1.RedisDatabase, n int32) []client.ObjectKey {
+	keys := make([]client.ObjectKey, 0, n)
+	for i := range int(n) {
+		keys = append(keys, client.ObjectKey{Namespace: rdb.Namespace, Name: pvcName(redisStatefulSetName(rdb), i)})
+	}
+	return keys
+}
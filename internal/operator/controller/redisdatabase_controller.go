@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -15,6 +19,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/benjamin-wright/db-operator/internal/redisacl"
+	"github.com/benjamin-wright/db-operator/internal/rediscluster"
+	"github.com/benjamin-wright/db-operator/internal/redisconfig"
 	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
 )
 
@@ -26,8 +33,25 @@ const (
 	// redisPort is the default port used by Redis.
 	redisPort = 6379
 
+	// redisSentinelPort is the default port used by Redis Sentinel.
+	redisSentinelPort = 26379
+
 	// redisImage is the hardcoded Redis 8 image.
 	redisImage = "redis:8"
+
+	// redisAdminPasswordGracePeriod is how long Redis keeps accepting the
+	// previous admin password after a rotation. It covers the kubelet
+	// refreshing the mounted admin Secret the probes read.
+	redisAdminPasswordGracePeriod = 5 * time.Minute
+
+	// redisClusterReconfiguringConditionType is the condition recording
+	// the step the operator last took to shape a cluster-mode database.
+	redisClusterReconfiguringConditionType = "ClusterReconfiguring"
+
+	// redisClusterMigrationBatch bounds how many hash slots one reconcile
+	// moves while resharding, so progress shows up in status between
+	// batches.
+	redisClusterMigrationBatch = 512
 )
 
 // errRedisStatefulSetBeingRecreated is returned by reconcileRedisStatefulSet when
@@ -42,11 +66,15 @@ type RedisDatabaseReconciler struct {
 	InstanceName string
 	client       redisDatabaseClient
 	builder      redisDatabaseBuilder
+	redisMgr     RedisManager
 }
 
 // +kubebuilder:rbac:groups=games-hub.io,resources=redisdatabases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=games-hub.io,resources=redisdatabases/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=games-hub.io,resources=redisdatabases/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile handles create/update/delete events for RedisDatabase resources.
 func (r *RedisDatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -73,29 +101,76 @@ func (r *RedisDatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}
 
+	r.observeRedisMaster(ctx, &rdb)
+
 	var result ctrl.Result
 	var reconcileErr error
 	if err := r.reconcileRedisAdminSecret(ctx, &rdb); err != nil {
 		reconcileErr = err
 		result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
 			"AdminSecretReconcileFailed", err.Error())
+	} else if err := r.reconcileRedisConfigMap(ctx, &rdb); err != nil {
+		reconcileErr = err
+		result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
+			"ConfigMapReconcileFailed", err.Error())
 	} else if err := r.reconcileRedisService(ctx, &rdb); err != nil {
 		reconcileErr = err
 		result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
 			"ServiceReconcileFailed", err.Error())
+	} else if err := reconcileServiceMonitor(ctx, &r.client, client.ObjectKeyFromObject(&rdb),
+		r.builder.desiredServiceMonitor(&rdb)); err != nil {
+		reconcileErr = err
+		result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
+			"ServiceMonitorReconcileFailed", err.Error())
+	} else if sentinel, err := r.reconcileRedisSentinel(ctx, &rdb); err != nil {
+		reconcileErr = err
+		result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
+			"SentinelReconcileFailed", err.Error())
 	} else {
 		sts, err := r.reconcileRedisStatefulSet(ctx, &rdb)
 		if err != nil {
 			if errors.Is(err, errRedisStatefulSetBeingRecreated) {
 				result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhasePending,
-					"StatefulSetBeingRecreated", "StatefulSet is being recreated to apply storage size changes")
+					"StatefulSetBeingRecreated", "StatefulSet is being recreated to apply storage changes")
 			} else {
 				reconcileErr = err
 				result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
 					"StatefulSetReconcileFailed", err.Error())
 			}
+		} else if resizing, err := reconcileStorageResizing(ctx, &r.client, &rdb.Status.Conditions, rdb.Generation,
+			redisClaimKeys(&rdb, *sts.Spec.Replicas)); err != nil {
+			reconcileErr = err
+			result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
+				"StorageResizingReconcileFailed", err.Error())
+		} else if err := r.reconcileRedisAdminPassword(ctx, &rdb, sts); err != nil {
+			reconcileErr = err
+			result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
+				"AdminPasswordRotationFailed", err.Error())
+		} else if err := r.reconcileRedisMemory(ctx, &rdb, sts); err != nil {
+			reconcileErr = err
+			result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
+				"MemoryConfigFailed", err.Error())
+		} else if err := r.reconcileRedisExporterUser(ctx, &rdb, sts); err != nil {
+			reconcileErr = err
+			result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
+				"ExporterUserReconcileFailed", err.Error())
+		} else if waiting, reconfiguring, err := r.reconcileRedisCluster(ctx, &rdb, sts); err != nil {
+			reconcileErr = err
+			result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhaseFailed,
+				"ClusterReconcileFailed", err.Error())
+		} else if waiting != "" {
+			result = r.setRedisPhase(&rdb, v1alpha1.RedisDatabasePhasePending, "ClusterNotReady", waiting)
 		} else {
-			result = r.updateRedisPhaseFromStatefulSet(&rdb, sts)
+			result = r.updateRedisPhaseFromStatefulSet(&rdb, sts, sentinel)
+			requeueForAdminPasswordRotation(&result, &rdb, rdb.Spec.AdminPasswordRotation, rdb.Status.AdminPasswordRotatedAt)
+			if expires := rdb.Status.PreviousAdminPasswordExpiresAt; expires != nil {
+				requeueAt(&result, expires.Time)
+			}
+			// PVC status changes do not trigger a reconcile, so poll until an
+			// expansion completes; a cluster reshards one batch per pass.
+			if (resizing || reconfiguring) && result.RequeueAfter == 0 {
+				result.RequeueAfter = 5 * time.Second
+			}
 		}
 	}
 
@@ -150,6 +225,34 @@ func (r *RedisDatabaseReconciler) reconcileDelete(ctx context.Context, rdb *v1al
 		return ctrl.Result{}, fmt.Errorf("deleting Service: %w", err)
 	}
 
+	if err := r.deleteRedisSentinel(ctx, rdb); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisConfigMapName(rdb),
+			Namespace: rdb.Namespace,
+		},
+	}
+	if err := r.client.delete(ctx, cm); err != nil {
+		return ctrl.Result{}, fmt.Errorf("deleting ConfigMap: %w", err)
+	}
+
+	if err := reconcileServiceMonitor(ctx, &r.client, client.ObjectKeyFromObject(rdb), nil); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	metricsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisMetricsSecretName(rdb),
+			Namespace: rdb.Namespace,
+		},
+	}
+	if err := r.client.delete(ctx, metricsSecret); err != nil {
+		return ctrl.Result{}, fmt.Errorf("deleting metrics Secret: %w", err)
+	}
+
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      redisAdminSecretName(rdb),
@@ -200,6 +303,456 @@ func (r *RedisDatabaseReconciler) reconcileRedisAdminSecret(ctx context.Context,
 	return nil
 }
 
+// reconcileRedisAdminPassword rotates the admin password when the
+// RotateAdminPasswordAnnotation or spec.adminPasswordRotation asks for it,
+// once the pod is ready. The new password is staged in the admin Secret, added
+// to the default user, then moved into REDIS_PASSWORD. Redis and the Sentinels
+// keep accepting the previous password for redisAdminPasswordGracePeriod,
+// after which it is removed.
+func (r *RedisDatabaseReconciler) reconcileRedisAdminPassword(ctx context.Context, rdb *v1alpha1.RedisDatabase, sts *appsv1.StatefulSet) error {
+	logger := log.FromContext(ctx)
+
+	var secret corev1.Secret
+	found, err := r.client.get(ctx, client.ObjectKey{Namespace: rdb.Namespace, Name: redisAdminSecretName(rdb)}, &secret)
+	if err != nil {
+		return fmt.Errorf("fetching admin Secret: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("admin Secret %q not found", redisAdminSecretName(rdb))
+	}
+
+	hosts := redisPodHosts(rdb)
+	current := string(secret.Data["REDIS_PASSWORD"])
+	if expires := rdb.Status.PreviousAdminPasswordExpiresAt; expires != nil && !time.Now().Before(expires.Time) {
+		for _, host := range hosts {
+			if err := r.redisMgr.ResetDefaultPasswords(ctx, host, current); err != nil {
+				return fmt.Errorf("removing previous admin password on %s: %w", host, err)
+			}
+		}
+		if rdb.Spec.Sentinel {
+			for _, host := range redisSentinelHosts(rdb) {
+				if err := r.redisMgr.ResetSentinelPasswords(ctx, host, current); err != nil {
+					return fmt.Errorf("removing previous admin password on %s: %w", host, err)
+				}
+			}
+		}
+		rdb.Status.PreviousAdminPasswordExpiresAt = nil
+	}
+
+	pendingKey := "REDIS_PASSWORD" + pendingPasswordSuffix
+	due, request := adminPasswordRotationDue(rdb, rdb.Spec.AdminPasswordRotation,
+		rdb.Status.AdminPasswordRotatedAt, rdb.Status.AdminPasswordRotationRequest)
+	pending := string(secret.Data[pendingKey])
+	if pending == "" {
+		if !due || sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+			return nil
+		}
+		if pending, err = generatePassword(24); err != nil {
+			return fmt.Errorf("generating admin password: %w", err)
+		}
+		secret.Data[pendingKey] = []byte(pending)
+		if err := r.client.update(ctx, &secret); err != nil {
+			return fmt.Errorf("staging admin password: %w", err)
+		}
+	}
+
+	for _, host := range hosts {
+		if err := r.redisMgr.AddDefaultPassword(ctx, host, current, pending); err != nil {
+			// An interrupted rotation may already have added the password.
+			if retryErr := r.redisMgr.AddDefaultPassword(ctx, host, pending, pending); retryErr != nil {
+				return err
+			}
+		}
+	}
+	if rdb.Spec.Sentinel {
+		for _, host := range redisSentinelHosts(rdb) {
+			if err := r.redisMgr.AddSentinelPassword(ctx, host, current, pending); err != nil {
+				if retryErr := r.redisMgr.AddSentinelPassword(ctx, host, pending, pending); retryErr != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Replicas and Sentinels authenticate to the master, and Sentinels to
+	// each other, with the admin password too, and must switch before the
+	// previous one expires.
+	for _, host := range hosts {
+		if err := r.redisMgr.SetMasterAuth(ctx, host, pending, pending); err != nil {
+			return err
+		}
+	}
+	if rdb.Spec.Sentinel {
+		for _, host := range redisSentinelHosts(rdb) {
+			if err := r.redisMgr.SetSentinelAuth(ctx, host, pending, redisSentinelMasterName(rdb), pending); err != nil {
+				return err
+			}
+		}
+	}
+
+	secret.Data["REDIS_PASSWORD"] = []byte(pending)
+	delete(secret.Data, pendingKey)
+	if err := r.client.update(ctx, &secret); err != nil {
+		return fmt.Errorf("updating admin Secret: %w", err)
+	}
+
+	now := metav1.Now()
+	expires := metav1.NewTime(now.Add(redisAdminPasswordGracePeriod))
+	rdb.Status.AdminPasswordRotatedAt = &now
+	rdb.Status.AdminPasswordRotationRequest = request
+	rdb.Status.PreviousAdminPasswordExpiresAt = &expires
+	logger.Info("rotated admin password")
+	return nil
+}
+
+// reconcileRedisMemory applies maxmemory and maxmemory-policy to every
+// running server once the pods are ready, so changing them needs no restart.
+// CONFIG SET is idempotent, so it is sent on every pass rather than tracking
+// what the server last received; this also covers a server that restarted
+// before its ConfigMap volume caught up.
+func (r *RedisDatabaseReconciler) reconcileRedisMemory(ctx context.Context, rdb *v1alpha1.RedisDatabase, sts *appsv1.StatefulSet) error {
+	if sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+		return nil
+	}
+
+	var secret corev1.Secret
+	found, err := r.client.get(ctx, client.ObjectKey{Namespace: rdb.Namespace, Name: redisAdminSecretName(rdb)}, &secret)
+	if err != nil {
+		return fmt.Errorf("fetching admin Secret: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("admin Secret %q not found", redisAdminSecretName(rdb))
+	}
+
+	for _, host := range redisPodHosts(rdb) {
+		if err := r.redisMgr.ConfigureMemory(ctx, host, string(secret.Data["REDIS_PASSWORD"]),
+			redisconfig.MaxMemory(&rdb.Spec), redisconfig.EvictionPolicy(&rdb.Spec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileRedisExporterUser keeps the ACL user redis_exporter logs in as on
+// every pod while metrics are enabled, re-applying it wherever it is missing
+// or differs. Once metrics are disabled the user is dropped and its Secret
+// deleted.
+func (r *RedisDatabaseReconciler) reconcileRedisExporterUser(ctx context.Context, rdb *v1alpha1.RedisDatabase, sts *appsv1.StatefulSet) error {
+	var secret corev1.Secret
+	secretKey := client.ObjectKey{Namespace: rdb.Namespace, Name: redisMetricsSecretName(rdb)}
+	found, err := r.client.get(ctx, secretKey, &secret)
+	if err != nil {
+		return fmt.Errorf("fetching metrics Secret: %w", err)
+	}
+	if rdb.Spec.Metrics == nil && !found {
+		return nil
+	}
+	if !found {
+		desired, err := r.builder.desiredMetricsSecret(rdb)
+		if err != nil {
+			return fmt.Errorf("building metrics Secret: %w", err)
+		}
+		if err := r.client.create(ctx, desired); err != nil {
+			return fmt.Errorf("creating metrics Secret: %w", err)
+		}
+		secret = *desired
+	}
+
+	if sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+		return nil
+	}
+	var adminSecret corev1.Secret
+	found, err = r.client.get(ctx, client.ObjectKey{Namespace: rdb.Namespace, Name: redisAdminSecretName(rdb)}, &adminSecret)
+	if err != nil {
+		return fmt.Errorf("fetching admin Secret: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("admin Secret %q not found", redisAdminSecretName(rdb))
+	}
+	adminPass := string(adminSecret.Data["REDIS_PASSWORD"])
+
+	if rdb.Spec.Metrics == nil {
+		for _, host := range redisPodHosts(rdb) {
+			if err := r.redisMgr.DropACLUser(ctx, host, adminPass, redisExporterUser); err != nil {
+				return err
+			}
+		}
+		if err := r.client.delete(ctx, &secret); err != nil {
+			return fmt.Errorf("deleting metrics Secret: %w", err)
+		}
+		return nil
+	}
+
+	spec := redisacl.Spec{Password: string(secret.Data["REDIS_PASSWORD"]), Commands: redisExporterCommands}
+	for _, host := range redisPodHosts(rdb) {
+		user, err := r.redisMgr.GetACLUser(ctx, host, adminPass, redisExporterUser)
+		if err != nil {
+			return err
+		}
+		if user != nil && len(redisacl.Drift(*user, spec)) == 0 {
+			continue
+		}
+		if err := r.redisMgr.EnsureACLUser(ctx, host, adminPass, redisExporterUser, spec.Password,
+			nil, nil, spec.Commands); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileRedisCluster forms the pods of a cluster-mode database into one
+// Redis Cluster once they are all ready, recording the step it took in the
+// ClusterReconfiguring condition and reporting whether there is more to do.
+// Joining new pods, attaching replicas and resharding happen while the
+// cluster keeps serving every slot, so they leave the database Ready; it
+// returns what the database is waiting on only while the cluster does not
+// report cluster_state:ok.
+func (r *RedisDatabaseReconciler) reconcileRedisCluster(ctx context.Context, rdb *v1alpha1.RedisDatabase, sts *appsv1.StatefulSet) (string, bool, error) {
+	if !redisClusterMode(rdb) || sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+		return "", false, nil
+	}
+
+	var secret corev1.Secret
+	found, err := r.client.get(ctx, client.ObjectKey{Namespace: rdb.Namespace, Name: redisAdminSecretName(rdb)}, &secret)
+	if err != nil {
+		return "", false, fmt.Errorf("fetching admin Secret: %w", err)
+	}
+	if !found {
+		return "", false, fmt.Errorf("admin Secret %q not found", redisAdminSecretName(rdb))
+	}
+	adminPass := string(secret.Data["REDIS_PASSWORD"])
+
+	reason, message, err := r.reconcileRedisClusterTopology(ctx, rdb, int(*sts.Spec.Replicas), adminPass)
+	if err != nil {
+		return "", false, err
+	}
+	condition := metav1.Condition{
+		Type:               redisClusterReconfiguringConditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Settled",
+		Message:            "every node has its role and the hash slots are balanced",
+		ObservedGeneration: rdb.Generation,
+	}
+	if reason != "" {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = message
+	}
+	meta.SetStatusCondition(&rdb.Status.Conditions, condition)
+
+	state, err := r.redisMgr.ClusterState(ctx, redisPodHost(rdb, 0), adminPass)
+	if err != nil {
+		return "", false, err
+	}
+	if state != "ok" {
+		if reason != "" {
+			return message, true, nil
+		}
+		return fmt.Sprintf("cluster state is %q", state), false, nil
+	}
+	return "", reason != "", nil
+}
+
+// reconcileRedisClusterTopology takes the next step towards the cluster the
+// spec describes, and returns the reason and a message for it, or "" once
+// there is nothing left to do. In order:
+//
+//   - nodes that left for good, such as a pod whose volume was replaced, are
+//     forgotten once the cluster flags them as failed and they own no slots;
+//   - ordinal 0 meets every pod it does not know at its current IP;
+//   - on a fresh cluster, the first cluster.shards pods are given an even
+//     share of the hash slots;
+//   - empty pods become the masters of new shards, up to cluster.shards, and
+//     the rest replicate the master with the fewest replicas;
+//   - slots are then moved onto new shards, redisClusterMigrationBatch at a
+//     time, until every master owns an even share.
+func (r *RedisDatabaseReconciler) reconcileRedisClusterTopology(ctx context.Context, rdb *v1alpha1.RedisDatabase, n int, adminPass string) (string, string, error) {
+	logger := log.FromContext(ctx)
+
+	// CLUSTER MEET only takes IPs, so they come from the pods themselves.
+	var pods corev1.PodList
+	if err := r.client.list(ctx, &pods,
+		client.InNamespace(rdb.Namespace),
+		client.MatchingLabels(labelsForRedisDatabase(rdb, r.InstanceName)),
+	); err != nil {
+		return "", "", fmt.Errorf("listing pods: %w", err)
+	}
+	ips := map[int]string{}
+	for i := range pods.Items {
+		ips[podOrdinal(&pods.Items[i])] = pods.Items[i].Status.PodIP
+	}
+
+	peers := make([]RedisClusterPeer, n)
+	selves := make([]rediscluster.Node, n)
+	views := make([][]rediscluster.Node, n)
+	ordinals := map[string]int{}
+	for i := range n {
+		host := redisPodHost(rdb, int32(i))
+		if ips[i] == "" {
+			return "WaitingForPods", fmt.Sprintf("waiting for pod %d to get an IP", i), nil
+		}
+		text, err := r.redisMgr.ClusterNodes(ctx, host, adminPass)
+		if err != nil {
+			return "", "", err
+		}
+		view, err := rediscluster.ParseNodes(text)
+		if err != nil {
+			return "", "", fmt.Errorf("parsing cluster nodes of %s: %w", host, err)
+		}
+		self := slices.IndexFunc(view, rediscluster.Node.Myself)
+		if self < 0 {
+			return "", "", fmt.Errorf("cluster nodes of %s do not include the node itself", host)
+		}
+		peers[i] = RedisClusterPeer{Host: host, IP: ips[i], ID: view[self].ID}
+		selves[i] = view[self]
+		views[i] = view
+		ordinals[view[self].ID] = i
+	}
+
+	for i, view := range views {
+		for _, node := range view {
+			if _, current := ordinals[node.ID]; current || !node.Failed() || node.Handshake() || len(node.Slots) > 0 {
+				continue
+			}
+			logger.Info("forgetting departed cluster node", "node", node.ID, "on", peers[i].Host)
+			if err := r.redisMgr.ClusterForget(ctx, peers[i].Host, adminPass, node.ID); err != nil {
+				return "", "", err
+			}
+		}
+	}
+
+	met := false
+	for _, peer := range peers[1:] {
+		known := slices.IndexFunc(views[0], func(node rediscluster.Node) bool { return node.ID == peer.ID })
+		if known >= 0 && views[0][known].IP() == peer.IP {
+			continue
+		}
+		if err := r.redisMgr.ClusterMeet(ctx, peers[0].Host, adminPass, peer.IP); err != nil {
+			return "", "", err
+		}
+		met = true
+	}
+	if met {
+		return "JoiningNodes", "joining nodes into the cluster", nil
+	}
+	for i, view := range views {
+		for _, peer := range peers {
+			if !slices.ContainsFunc(view, func(node rediscluster.Node) bool { return node.ID == peer.ID && !node.Handshake() }) {
+				return "JoiningNodes", fmt.Sprintf("waiting for %s to learn about %s", peers[i].Host, peer.Host), nil
+			}
+		}
+	}
+
+	shards := int(redisClusterShards(rdb))
+	owned := map[int]bool{}
+	for _, view := range views {
+		for _, node := range view {
+			for _, slot := range node.Slots {
+				owned[slot] = true
+			}
+		}
+	}
+	if len(owned) < rediscluster.SlotCount {
+		assigned := false
+		for k, slots := range rediscluster.SplitSlots(shards) {
+			if !selves[k].Master() || len(selves[k].Slots) > 0 || owned[slots.Start] {
+				continue
+			}
+			if err := r.redisMgr.ClusterAddSlots(ctx, peers[k].Host, adminPass, slots); err != nil {
+				return "", "", err
+			}
+			assigned = true
+		}
+		if !assigned {
+			return "", "", fmt.Errorf("%d hash slots are not served by any node", rediscluster.SlotCount-len(owned))
+		}
+		logger.Info("assigned hash slots to a new cluster", "shards", shards)
+		return "AssigningSlots", "assigning hash slots", nil
+	}
+
+	// A master without slots is an empty pod unless replicas already follow
+	// it, in which case an earlier pass made it the master of a new shard.
+	replicas := map[string]int{}
+	for _, self := range selves {
+		if !self.Master() {
+			replicas[self.MasterID]++
+		}
+	}
+	var masters, empty []int
+	for i, self := range selves {
+		switch {
+		case !self.Master():
+		case len(self.Slots) > 0 || replicas[self.ID] > 0:
+			masters = append(masters, i)
+		default:
+			empty = append(empty, i)
+		}
+	}
+	rdb.Status.ClusterShards = int32(len(masters))
+	promoted := min(max(shards-len(masters), 0), len(empty))
+	masters = append(masters, empty[:promoted]...)
+	slices.Sort(masters)
+
+	attached := false
+	for _, i := range empty[promoted:] {
+		target := slices.MinFunc(masters, func(a, b int) int {
+			return replicas[selves[a].ID] - replicas[selves[b].ID]
+		})
+		if err := r.redisMgr.ClusterReplicate(ctx, peers[i].Host, adminPass, selves[target].ID); err != nil {
+			return "", "", err
+		}
+		logger.Info("attached cluster replica", "replica", i, "master", target)
+		replicas[selves[target].ID]++
+		attached = true
+	}
+	if attached {
+		return "AttachingReplicas", "attaching replicas to their masters", nil
+	}
+
+	nodes := make([]rediscluster.Node, len(masters))
+	for k, i := range masters {
+		nodes[k] = selves[i]
+	}
+	moves := rediscluster.PlanRebalance(nodes, redisClusterMigrationBatch)
+	for _, move := range moves {
+		if err := r.redisMgr.ClusterMigrateSlot(ctx, adminPass, move.Slot,
+			peers[ordinals[move.From]], peers[ordinals[move.To]]); err != nil {
+			return "", "", err
+		}
+	}
+	if len(moves) > 0 {
+		logger.Info("moved hash slots between shards", "slots", len(moves))
+		return "Resharding", fmt.Sprintf("moved %d hash slots onto new shards", len(moves)), nil
+	}
+	return "", "", nil
+}
+
+// reconcileRedisConfigMap ensures the ConfigMap holding redis.conf exists and
+// is up-to-date.
+func (r *RedisDatabaseReconciler) reconcileRedisConfigMap(ctx context.Context, rdb *v1alpha1.RedisDatabase) error {
+	desired := r.builder.desiredConfigMap(rdb)
+
+	var existing corev1.ConfigMap
+	found, err := r.client.get(ctx, client.ObjectKeyFromObject(desired), &existing)
+	if err != nil {
+		return fmt.Errorf("fetching ConfigMap: %w", err)
+	}
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return fmt.Errorf("creating ConfigMap: %w", err)
+		}
+		return nil
+	}
+
+	if !equality.Semantic.DeepEqual(existing.Data, desired.Data) {
+		existing.Data = desired.Data
+		if err := r.client.update(ctx, &existing); err != nil {
+			return fmt.Errorf("updating ConfigMap: %w", err)
+		}
+	}
+	return nil
+}
+
 // reconcileRedisService ensures the headless Service exists and is up-to-date.
 func (r *RedisDatabaseReconciler) reconcileRedisService(ctx context.Context, rdb *v1alpha1.RedisDatabase) error {
 	desired := r.builder.desiredService(rdb)
@@ -228,15 +781,137 @@ func (r *RedisDatabaseReconciler) reconcileRedisService(ctx context.Context, rdb
 	return nil
 }
 
+// reconcileRedisSentinel ensures the Sentinel Service and StatefulSet exist
+// and are up-to-date when spec.sentinel is set, and removes them otherwise.
+// It returns the Sentinel StatefulSet, or nil when Sentinel is disabled.
+func (r *RedisDatabaseReconciler) reconcileRedisSentinel(ctx context.Context, rdb *v1alpha1.RedisDatabase) (*appsv1.StatefulSet, error) {
+	if !rdb.Spec.Sentinel {
+		return nil, r.deleteRedisSentinel(ctx, rdb)
+	}
+
+	var secret corev1.Secret
+	found, err := r.client.get(ctx, client.ObjectKey{Namespace: rdb.Namespace, Name: redisSentinelSecretName(rdb)}, &secret)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Sentinel Secret: %w", err)
+	}
+	if !found {
+		desired, err := r.builder.desiredSentinelSecret(rdb)
+		if err != nil {
+			return nil, fmt.Errorf("building Sentinel Secret: %w", err)
+		}
+		if err := r.client.create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("creating Sentinel Secret: %w", err)
+		}
+	}
+
+	desiredSvc := r.builder.desiredSentinelService(rdb)
+	var existingSvc corev1.Service
+	found, err = r.client.get(ctx, client.ObjectKeyFromObject(desiredSvc), &existingSvc)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Sentinel Service: %w", err)
+	}
+	if !found {
+		if err := r.client.create(ctx, desiredSvc); err != nil {
+			return nil, fmt.Errorf("creating Sentinel Service: %w", err)
+		}
+	} else if !equality.Semantic.DeepEqual(existingSvc.Spec.Ports, desiredSvc.Spec.Ports) ||
+		!equality.Semantic.DeepEqual(existingSvc.Spec.Selector, desiredSvc.Spec.Selector) {
+		existingSvc.Spec.Ports = desiredSvc.Spec.Ports
+		existingSvc.Spec.Selector = desiredSvc.Spec.Selector
+		if err := r.client.update(ctx, &existingSvc); err != nil {
+			return nil, fmt.Errorf("updating Sentinel Service: %w", err)
+		}
+	}
+
+	desired := r.builder.desiredSentinelStatefulSet(rdb)
+	var existing appsv1.StatefulSet
+	found, err = r.client.get(ctx, client.ObjectKeyFromObject(desired), &existing)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Sentinel StatefulSet: %w", err)
+	}
+	if !found {
+		if err := r.client.create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("creating Sentinel StatefulSet: %w", err)
+		}
+		return desired, nil
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Template, desired.Spec.Template) {
+		existing.Spec.Template = desired.Spec.Template
+		if err := r.client.update(ctx, &existing); err != nil {
+			return nil, fmt.Errorf("updating Sentinel StatefulSet: %w", err)
+		}
+	}
+	return &existing, nil
+}
+
+// deleteRedisSentinel removes the Sentinel StatefulSet, Service and Secret.
+func (r *RedisDatabaseReconciler) deleteRedisSentinel(ctx context.Context, rdb *v1alpha1.RedisDatabase) error {
+	key := metav1.ObjectMeta{Name: redisSentinelName(rdb), Namespace: rdb.Namespace}
+	if err := r.client.delete(ctx, &appsv1.StatefulSet{ObjectMeta: key}); err != nil {
+		return fmt.Errorf("deleting Sentinel StatefulSet: %w", err)
+	}
+	if err := r.client.delete(ctx, &corev1.Service{ObjectMeta: key}); err != nil {
+		return fmt.Errorf("deleting Sentinel Service: %w", err)
+	}
+	secretKey := metav1.ObjectMeta{Name: redisSentinelSecretName(rdb), Namespace: rdb.Namespace}
+	if err := r.client.delete(ctx, &corev1.Secret{ObjectMeta: secretKey}); err != nil {
+		return fmt.Errorf("deleting Sentinel Secret: %w", err)
+	}
+	return nil
+}
+
+// observeRedisMaster records in status.masterOrdinal which pod the Sentinels
+// report as the master after a failover. The ConfigMap and StatefulSet size
+// are derived from it, so restarted pods rejoin the current master and
+// scaling down keeps it. Sentinels that cannot be reached, for example while
+// they start, leave the status as it is.
+func (r *RedisDatabaseReconciler) observeRedisMaster(ctx context.Context, rdb *v1alpha1.RedisDatabase) {
+	if !rdb.Spec.Sentinel {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	var secret corev1.Secret
+	found, err := r.client.get(ctx, client.ObjectKey{Namespace: rdb.Namespace, Name: redisAdminSecretName(rdb)}, &secret)
+	if err != nil || !found {
+		logger.V(1).Info("admin Secret not available to query the Sentinels", "error", err)
+		return
+	}
+
+	prefix := redisStatefulSetName(rdb) + "-"
+	suffix := "." + redisServiceDomain(redisServiceName(rdb), rdb.Namespace)
+	for _, sentinel := range redisSentinelHosts(rdb) {
+		host, err := r.redisMgr.SentinelMaster(ctx, sentinel, string(secret.Data["REDIS_PASSWORD"]), redisSentinelMasterName(rdb))
+		if err != nil {
+			logger.V(1).Info("Sentinel did not report a master", "sentinel", sentinel, "error", err)
+			continue
+		}
+		ordinal, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(host, prefix), suffix), 10, 32)
+		if err != nil || !strings.HasPrefix(host, prefix) || !strings.HasSuffix(host, suffix) {
+			logger.Info("Sentinel reported an unexpected master host", "sentinel", sentinel, "host", host)
+			continue
+		}
+		if int32(ordinal) != rdb.Status.MasterOrdinal {
+			logger.Info("Sentinel promoted a new master", "from", rdb.Status.MasterOrdinal, "to", ordinal)
+			rdb.Status.MasterOrdinal = int32(ordinal)
+		}
+		return
+	}
+}
+
 // reconcileRedisStatefulSet ensures the StatefulSet exists and is up-to-date.
 // It returns the StatefulSet as returned by the API server so callers can inspect
 // the latest state without a redundant cache read.
 //
-// Because StatefulSet.spec.volumeClaimTemplates is immutable, a storage size
-// change requires deleting the StatefulSet and its PVC, then recreating both.
-// WARNING: this destroys all data in the Redis instance. During the deletion
-// window the method returns errRedisStatefulSetBeingRecreated so the caller
-// sets phase=Pending rather than phase=Failed.
+// StatefulSet.spec.volumeClaimTemplates is immutable. When storage grows and
+// the claim's StorageClass allows volume expansion, the existing PVC is
+// expanded in place and the StatefulSet is deleted without its pod, so it is
+// recreated with the new template while Redis keeps running. Any other
+// storage change requires deleting the StatefulSet and its PVC, then
+// recreating both. WARNING: this destroys all data in the Redis instance.
+// During the deletion window the method returns
+// errRedisStatefulSetBeingRecreated so the caller sets phase=Pending rather
+// than phase=Failed.
 func (r *RedisDatabaseReconciler) reconcileRedisStatefulSet(ctx context.Context, rdb *v1alpha1.RedisDatabase) (*appsv1.StatefulSet, error) {
 	logger := log.FromContext(ctx)
 	desired := r.builder.desiredStatefulSet(rdb)
@@ -259,32 +934,72 @@ func (r *RedisDatabaseReconciler) reconcileRedisStatefulSet(ctx context.Context,
 		return nil, errRedisStatefulSetBeingRecreated
 	}
 
-	// volumeClaimTemplates is immutable. When storage has changed, delete both
-	// the StatefulSet and its PVC — this destroys all data — then requeue so
-	// the next reconcile recreates everything fresh with the new storage size.
 	if volumeClaimStorageChanged(&existing, desired) {
 		currentSize := existing.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
 		desiredSize := desired.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
-		logger.V(1).Info("WARNING: storageSize change requires destroying and recreating the database; all data will be lost",
-			"name", rdb.Name, "namespace", rdb.Namespace,
-			"currentSize", currentSize.String(),
-			"desiredSize", desiredSize.String())
+		claims := redisClaimKeys(rdb, max(maxRedisReplicas, redisPodCount(rdb)))
 
-		name := pvcName(rdb.Name, 0)
-		pvc := &corev1.PersistentVolumeClaim{
-			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: rdb.Namespace},
-		}
-		if err := r.client.delete(ctx, pvc); err != nil {
-			return nil, fmt.Errorf("deleting PVC %s for storage resize: %w", name, err)
-		}
+		// Growth on an expandable StorageClass: expand the live PVCs, then
+		// orphan the pods so the StatefulSet can be recreated with the new
+		// template and adopt them again without a restart.
+		if volumeClaimStorageGrown(&existing, desired) {
+			expandable, err := volumeClaimsExpandable(ctx, &r.client, claims)
+			if err != nil {
+				return nil, err
+			}
+			if expandable {
+				logger.Info("expanding PVCs in place",
+					"currentSize", currentSize.String(), "desiredSize", desiredSize.String())
+				if err := expandVolumeClaims(ctx, &r.client, claims, desiredSize); err != nil {
+					return nil, err
+				}
+				if err := r.client.deleteOrphan(ctx, &existing); err != nil {
+					return nil, fmt.Errorf("deleting StatefulSet for storage resize: %w", err)
+				}
+				return nil, errRedisStatefulSetBeingRecreated
+			}
+			// Recreating the volume would lose its data for a change that
+			// only asked for more space, so the growth is left undone.
+			setStorageResizeSkipped(&rdb.Status.Conditions, rdb.Generation, "ExpansionNotSupported",
+				"the StorageClass does not allow volume expansion; the volume keeps its current size")
+		} else {
+			setStorageResizeSkipped(&rdb.Status.Conditions, rdb.Generation, "VolumesRecreated",
+				"storage can only grow in place; the volume was recreated at the smaller size")
 
-		if err := r.client.delete(ctx, &existing); err != nil {
-			return nil, fmt.Errorf("deleting StatefulSet for storage resize: %w", err)
+			// Shrinking needs new volumes: delete both the StatefulSet and its
+			// PVC — this destroys all data — then requeue so the next reconcile
+			// recreates everything fresh with the new storage.
+			logger.V(1).Info("WARNING: storage change requires destroying and recreating the database; all data will be lost",
+				"name", rdb.Name, "namespace", rdb.Namespace,
+				"currentSize", currentSize.String(),
+				"desiredSize", desiredSize.String())
+
+			for _, key := range claims {
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				}
+				if err := r.client.delete(ctx, pvc); err != nil {
+					return nil, fmt.Errorf("deleting PVC %s for storage resize: %w", key.Name, err)
+				}
+			}
+
+			if err := r.client.delete(ctx, &existing); err != nil {
+				return nil, fmt.Errorf("deleting StatefulSet for storage resize: %w", err)
+			}
+
+			// The recreated instance starts empty with ordinal 0 as its master,
+			// and in cluster mode with no shards until slots are assigned again.
+			rdb.Status.MasterOrdinal = 0
+			rdb.Status.ClusterShards = 0
+			return nil, errRedisStatefulSetBeingRecreated
 		}
-		return nil, errRedisStatefulSetBeingRecreated
 	}
 
-	if !equality.Semantic.DeepEqual(existing.Spec.Template, desired.Spec.Template) {
+	// Scaling down leaves the PVCs of removed replicas in place, so scaling
+	// back up resumes from their existing data.
+	if *existing.Spec.Replicas != *desired.Spec.Replicas ||
+		!equality.Semantic.DeepEqual(existing.Spec.Template, desired.Spec.Template) {
+		existing.Spec.Replicas = desired.Spec.Replicas
 		existing.Spec.Template = desired.Spec.Template
 		if err := r.client.update(ctx, &existing); err != nil {
 			return nil, fmt.Errorf("updating StatefulSet: %w", err)
@@ -294,16 +1009,21 @@ func (r *RedisDatabaseReconciler) reconcileRedisStatefulSet(ctx context.Context,
 	return &existing, nil
 }
 
-// updateRedisPhaseFromStatefulSet checks the StatefulSet readiness and sets the
-// RedisDatabase phase accordingly in memory.
-func (r *RedisDatabaseReconciler) updateRedisPhaseFromStatefulSet(rdb *v1alpha1.RedisDatabase, sts *appsv1.StatefulSet) ctrl.Result {
-	if sts.Status.ReadyReplicas >= 1 && sts.Status.ReadyReplicas == *sts.Spec.Replicas {
-		return r.setRedisPhase(rdb, v1alpha1.RedisDatabasePhaseReady,
-			"StatefulSetReady", "StatefulSet has all replicas ready")
+// updateRedisPhaseFromStatefulSet checks the readiness of the Redis
+// StatefulSet and, when Sentinel is enabled, the Sentinel StatefulSet, and
+// sets the RedisDatabase phase accordingly in memory.
+func (r *RedisDatabaseReconciler) updateRedisPhaseFromStatefulSet(rdb *v1alpha1.RedisDatabase, sts, sentinel *appsv1.StatefulSet) ctrl.Result {
+	if sts.Status.ReadyReplicas < 1 || sts.Status.ReadyReplicas != *sts.Spec.Replicas {
+		return r.setRedisPhase(rdb, v1alpha1.RedisDatabasePhasePending,
+			"StatefulSetNotReady", "waiting for StatefulSet replicas to become ready")
+	}
+	if sentinel != nil && sentinel.Status.ReadyReplicas != *sentinel.Spec.Replicas {
+		return r.setRedisPhase(rdb, v1alpha1.RedisDatabasePhasePending,
+			"SentinelNotReady", "waiting for Sentinel replicas to become ready")
 	}
 
-	return r.setRedisPhase(rdb, v1alpha1.RedisDatabasePhasePending,
-		"StatefulSetNotReady", "waiting for StatefulSet replicas to become ready")
+	return r.setRedisPhase(rdb, v1alpha1.RedisDatabasePhaseReady,
+		"StatefulSetReady", "StatefulSet has all replicas ready")
 }
 
 // setRedisPhase mutates the RedisDatabase status phase and condition in memory.
@@ -339,10 +1059,12 @@ func (r *RedisDatabaseReconciler) setRedisPhase(
 func (r *RedisDatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.client = redisDatabaseClient{inner: mgr.GetClient()}
 	r.builder = redisDatabaseBuilder{instanceName: r.InstanceName, scheme: mgr.GetScheme()}
+	r.redisMgr = instrumentedRedisManager{inner: redisManager{}}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.RedisDatabase{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
 		Complete(r)
 }
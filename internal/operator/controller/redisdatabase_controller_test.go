@@ -4,6 +4,7 @@ package controller_test
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	. "github.com/benjamin-wright/db-operator/internal/test_utils"
@@ -12,6 +13,8 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -137,7 +140,9 @@ var _ = Describe("RedisDatabaseReconciler", func() {
 			var sts appsv1.StatefulSet
 			Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
 			container := sts.Spec.Template.Spec.Containers[0]
-			Expect(container.Command).To(Equal([]string{"redis-server", "--requirepass", "$(REDIS_PASSWORD)"}))
+			Expect(container.Command).To(HaveLen(3))
+			Expect(container.Command[:2]).To(Equal([]string{"sh", "-c"}))
+			Expect(container.Command[2]).To(ContainSubstring(`redis-server /etc/redis/redis.conf --requirepass "${REDIS_PASSWORD}"`))
 		})
 
 		It("should source REDIS_PASSWORD from the admin Secret", func() {
@@ -203,18 +208,21 @@ var _ = Describe("RedisDatabaseReconciler", func() {
 	})
 
 	// ── Storage resize ───────────────────────────────────────────────────────
-	// Verify that increasing StorageSize destroys and recreates the database
-	// with the new storage size, and returns to Ready.
-	Context("when the StorageSize of a ready RedisDatabase is increased", Ordered, func() {
+	// Growing storage on a StorageClass without volume expansion leaves the
+	// volumes, and the data on them, as they are.
+	Context("when the StorageSize of a ready RedisDatabase grows on a StorageClass without expansion", Ordered, func() {
 		var (
 			ns     *corev1.Namespace
-			rdb    *v1alpha1.RedisDatabase
 			lookup types.NamespacedName
+			pvcKey types.NamespacedName
+			pvcUID types.UID
 		)
 
 		BeforeAll(func() {
+			var rdb *v1alpha1.RedisDatabase
 			ns, rdb, lookup, _ = newTestRedisResources("test-rdb")
 			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+			pvcKey = types.NamespacedName{Name: "data-" + rdb.Name + "-0", Namespace: ns.Name}
 
 			// Wait for the database to become Ready before attempting a resize.
 			Eventually(func(g Gomega) {
@@ -223,6 +231,10 @@ var _ = Describe("RedisDatabaseReconciler", func() {
 				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
 			}, Timeout, Interval).Should(Succeed())
 
+			var pvc corev1.PersistentVolumeClaim
+			Expect(K8sClient.Get(Ctx, pvcKey, &pvc)).To(Succeed())
+			pvcUID = pvc.UID
+
 			// Increase the storage size.
 			var latest v1alpha1.RedisDatabase
 			Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
@@ -234,23 +246,630 @@ var _ = Describe("RedisDatabaseReconciler", func() {
 			_ = K8sClient.Delete(Ctx, ns)
 		})
 
-		It("should update the StatefulSet VolumeClaimTemplate to the new size", func() {
+		It("should report that the StorageClass does not allow expansion", func() {
 			Eventually(func(g Gomega) {
-				var sts appsv1.StatefulSet
-				g.Expect(K8sClient.Get(Ctx, lookup, &sts)).To(Succeed())
-				g.Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
-				vcStorage := sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
-				g.Expect(vcStorage.Cmp(resource.MustParse("512Mi"))).To(Equal(0))
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				cond := meta.FindStatusCondition(fetched.Status.Conditions, "StorageResizing")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal("ExpansionNotSupported"))
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should keep the existing PVC at its current size", func() {
+			Consistently(func(g Gomega) {
+				var pvc corev1.PersistentVolumeClaim
+				g.Expect(K8sClient.Get(Ctx, pvcKey, &pvc)).To(Succeed())
+				g.Expect(pvc.UID).To(Equal(pvcUID))
+				storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(storage.Cmp(resource.MustParse("256Mi"))).To(Equal(0))
+			}, 10*time.Second, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Online storage expansion ─────────────────────────────────────────────
+	Context("when storageSize grows on an expandable StorageClass", Ordered, func() {
+		var (
+			ns     *corev1.Namespace
+			sc     *storagev1.StorageClass
+			lookup types.NamespacedName
+			pvcKey types.NamespacedName
+			pvcUID types.UID
+		)
+
+		BeforeAll(func() {
+			var rdb *v1alpha1.RedisDatabase
+			sc = newExpandableStorageClass()
+			ns, rdb, lookup, _ = newTestRedisResources("test-rdb")
+			rdb.Spec.StorageClassName = sc.Name
+			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+			pvcKey = types.NamespacedName{Name: "data-" + rdb.Name + "-0", Namespace: ns.Name}
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+
+			var pvc corev1.PersistentVolumeClaim
+			Expect(K8sClient.Get(Ctx, pvcKey, &pvc)).To(Succeed())
+			pvcUID = pvc.UID
+
+			var latest v1alpha1.RedisDatabase
+			Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+			latest.Spec.StorageSize = resource.MustParse("512Mi")
+			Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+			_ = K8sClient.Delete(Ctx, sc)
+		})
+
+		It("should reject a change of storageClassName", func() {
+			var latest v1alpha1.RedisDatabase
+			Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+			latest.Spec.StorageClassName = ""
+			err := K8sClient.Update(Ctx, &latest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("storageClassName is immutable"))
+		})
+
+		It("should expand the existing PVC rather than recreating it", func() {
+			Eventually(func(g Gomega) {
+				var pvc corev1.PersistentVolumeClaim
+				g.Expect(K8sClient.Get(Ctx, pvcKey, &pvc)).To(Succeed())
+				g.Expect(pvc.UID).To(Equal(pvcUID))
+				storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(storage.Cmp(resource.MustParse("512Mi"))).To(Equal(0))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should report the expansion and return to Ready", func() {
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				expectStorageResizing(g, fetched.Status.Conditions)
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Pod template overrides ───────────────────────────────────────────────
+	Context("when a podTemplate is set", Ordered, func() {
+		var (
+			ns     *corev1.Namespace
+			rdb    *v1alpha1.RedisDatabase
+			lookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, rdb, lookup, _ = newTestRedisResources("test-rdb")
+			rdb.Spec.PodTemplate = newPodTemplateOverrides()
+			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should run the redis pod with the overrides applied", func() {
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: rdb.Name + "-0", Namespace: ns.Name}, &pod)).To(Succeed())
+			expectPodTemplateApplied(Default, &pod, "redis", "redis")
+		})
+	})
+
+	// ── Persistence ──────────────────────────────────────────────────────────
+	Context("when persistence uses an append-only file", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			rdb          *v1alpha1.RedisDatabase
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, rdb, lookup, secretLookup = newTestRedisResources("test-rdb")
+			rdb.Spec.Persistence = &v1alpha1.RedisPersistenceSpec{
+				AOF: &v1alpha1.RedisAOFSpec{Fsync: v1alpha1.RedisAppendFsyncAlways},
+			}
+			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+			WaitForRedisDatabase(lookup)
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should render the settings into redis.conf", func() {
+			var cm corev1.ConfigMap
+			Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: rdb.Name + "-config", Namespace: ns.Name}, &cm)).To(Succeed())
+			Expect(cm.Data["redis.conf"]).To(ContainSubstring("appendonly yes\nappendfsync always\n"))
+		})
+
+		It("should start Redis with the append-only file enabled", func() {
+			rc, close := ConnectToRedisDatabase(lookup, secretLookup)
+			defer close()
+			Expect(rc.ConfigGet(Ctx, "appendonly").Val()).To(HaveKeyWithValue("appendonly", "yes"))
+			Expect(rc.ConfigGet(Ctx, "appendfsync").Val()).To(HaveKeyWithValue("appendfsync", "always"))
+			Expect(rc.ConfigGet(Ctx, "save").Val()).To(HaveKeyWithValue("save", ""))
+		})
+
+		It("should restart Redis with RDB snapshots when the persistence changes", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.Persistence = &v1alpha1.RedisPersistenceSpec{
+					RDB: &v1alpha1.RedisRDBSpec{SavePoints: []v1alpha1.RedisSavePoint{{Seconds: 60, Changes: 100}}},
+				}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				rc, close := ConnectToRedisDatabase(lookup, secretLookup)
+				defer close()
+				g.Expect(rc.ConfigGet(Ctx, "appendonly").Val()).To(HaveKeyWithValue("appendonly", "no"))
+				g.Expect(rc.ConfigGet(Ctx, "save").Val()).To(HaveKeyWithValue("save", "60 100"))
+			}, 2*Timeout, 2*time.Second).Should(Succeed())
+		})
+	})
+
+	// ── Memory ───────────────────────────────────────────────────────────────
+	Context("when the Redis container has a memory limit", Ordered, func() {
+		var (
+			ns           *corev1.Namespace
+			rdb          *v1alpha1.RedisDatabase
+			lookup       types.NamespacedName
+			secretLookup types.NamespacedName
+			podUID       types.UID
+		)
+
+		BeforeAll(func() {
+			ns, rdb, lookup, secretLookup = newTestRedisResources("test-rdb")
+			rdb.Spec.PodTemplate = &v1alpha1.PodTemplateOverrides{
+				Resources: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+			WaitForRedisDatabase(lookup)
+
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: rdb.Name + "-0", Namespace: ns.Name}, &pod)).To(Succeed())
+			podUID = pod.UID
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should default maxmemory to three quarters of the limit", func() {
+			Eventually(func(g Gomega) {
+				rc, close := ConnectToRedisDatabase(lookup, secretLookup)
+				defer close()
+				g.Expect(rc.ConfigGet(Ctx, "maxmemory").Val()).To(HaveKeyWithValue("maxmemory", "100663296"))
+				g.Expect(rc.ConfigGet(Ctx, "maxmemory-policy").Val()).To(HaveKeyWithValue("maxmemory-policy", "noeviction"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should apply an explicit maxMemory and evictionPolicy without a restart", func() {
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				maxMemory := resource.MustParse("64Mi")
+				latest.Spec.MaxMemory = &maxMemory
+				latest.Spec.EvictionPolicy = v1alpha1.RedisEvictionAllKeysLRU
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				rc, close := ConnectToRedisDatabase(lookup, secretLookup)
+				defer close()
+				g.Expect(rc.ConfigGet(Ctx, "maxmemory").Val()).To(HaveKeyWithValue("maxmemory", "67108864"))
+				g.Expect(rc.ConfigGet(Ctx, "maxmemory-policy").Val()).To(HaveKeyWithValue("maxmemory-policy", "allkeys-lru"))
+			}, Timeout, Interval).Should(Succeed())
+
+			var pod corev1.Pod
+			Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: rdb.Name + "-0", Namespace: ns.Name}, &pod)).To(Succeed())
+			Expect(pod.UID).To(Equal(podUID))
+		})
+
+		It("should write the memory settings to redis.conf", func() {
+			Eventually(func(g Gomega) {
+				var cm corev1.ConfigMap
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: rdb.Name + "-config", Namespace: ns.Name}, &cm)).To(Succeed())
+				g.Expect(cm.Data["redis.conf"]).To(ContainSubstring("maxmemory 67108864\nmaxmemory-policy allkeys-lru\n"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Replication and Sentinel ─────────────────────────────────────────────
+	Context("when replicas and Sentinel are enabled", Ordered, func() {
+		var (
+			ns               *corev1.Namespace
+			rdb              *v1alpha1.RedisDatabase
+			lookup           types.NamespacedName
+			secretLookup     types.NamespacedName
+			credSecretLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, rdb, lookup, secretLookup = newTestRedisResources("test-rdb")
+			rdb.Spec.Replicas = 2
+			rdb.Spec.Sentinel = true
+			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, 3*Timeout, Interval).Should(Succeed())
+
+			rcred := &v1alpha1.RedisCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "app",
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.RedisCredentialSpec{
+					DatabaseRef:   rdb.Name,
+					Username:      "app",
+					SecretName:    "app-redis",
+					KeyPatterns:   []string{"*"},
+					ACLCategories: []v1alpha1.RedisACLCategory{v1alpha1.RedisACLCategoryRead, v1alpha1.RedisACLCategoryWrite},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, rcred)).To(Succeed())
+			credSecretLookup = types.NamespacedName{Name: rcred.Spec.SecretName, Namespace: ns.Name}
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisCredential
+				g.Expect(K8sClient.Get(Ctx, client.ObjectKeyFromObject(rcred), &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisCredentialPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should run ordinal 1 as a replica of ordinal 0", func() {
+			Eventually(func(g Gomega) {
+				rc, close := ConnectToRedisDatabaseOrdinal(lookup, secretLookup, 1)
+				defer close()
+				info := rc.Info(Ctx, "replication").Val()
+				g.Expect(info).To(ContainSubstring("role:slave"))
+				g.Expect(info).To(ContainSubstring("master_host:" + rdb.Name + "-0." + rdb.Name + "." + ns.Name))
+				g.Expect(info).To(ContainSubstring("master_link_status:up"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		It("should run three ready Sentinels", func() {
+			var sts appsv1.StatefulSet
+			Expect(K8sClient.Get(Ctx, types.NamespacedName{Name: rdb.Name + "-sentinel", Namespace: ns.Name}, &sts)).To(Succeed())
+			Expect(sts.Status.ReadyReplicas).To(Equal(int32(3)))
+		})
+
+		It("should create the credential's ACL user on every node", func() {
+			for ordinal := range 2 {
+				rc, close := ConnectToRedisDatabaseOrdinal(lookup, credSecretLookup, ordinal)
+				Expect(rc.Ping(Ctx).Err()).To(Succeed(), "ordinal %d", ordinal)
+				close()
+			}
+		})
+
+		It("should add the Sentinel addresses to the credential Secret", func() {
+			var secret corev1.Secret
+			Expect(K8sClient.Get(Ctx, credSecretLookup, &secret)).To(Succeed())
+			Expect(string(secret.Data["REDIS_MASTER_NAME"])).To(Equal(rdb.Name))
+			hosts := strings.Split(string(secret.Data["REDIS_SENTINEL_HOSTS"]), ",")
+			Expect(hosts).To(HaveLen(3))
+			Expect(hosts[0]).To(Equal(fmt.Sprintf("%s-sentinel-0.%s-sentinel.%s.svc.cluster.local:26379", rdb.Name, rdb.Name, ns.Name)))
+		})
+
+		It("should require a password on the Sentinels", func() {
+			sentinel, close := ConnectToRedisSentinel(lookup, "", "", 0)
+			defer close()
+			Expect(sentinel.GetMasterAddrByName(Ctx, rdb.Name).Err()).To(MatchError(ContainSubstring("NOAUTH")))
+		})
+
+		It("should let the credential's Sentinel login look up the master and nothing else", func() {
+			var secret corev1.Secret
+			Expect(K8sClient.Get(Ctx, credSecretLookup, &secret)).To(Succeed())
+			sentinel, close := ConnectToRedisSentinel(lookup,
+				string(secret.Data["REDIS_SENTINEL_USERNAME"]), string(secret.Data["REDIS_SENTINEL_PASSWORD"]), 0)
+			defer close()
+
+			addr, err := sentinel.GetMasterAddrByName(Ctx, rdb.Name).Result()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addr[0]).To(HavePrefix(rdb.Name + "-0."))
+			Expect(sentinel.Set(Ctx, rdb.Name, "down-after-milliseconds", "1000").Err()).To(MatchError(ContainSubstring("NOPERM")))
+		})
+
+		It("should promote the replica when the master pod goes away", func() {
+			Expect(K8sClient.Delete(Ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: rdb.Name + "-0", Namespace: ns.Name},
+			})).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.MasterOrdinal).To(Equal(int32(1)))
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, 3*Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				rc, close := ConnectToRedisDatabaseOrdinal(lookup, credSecretLookup, 1)
+				defer close()
+				g.Expect(rc.Info(Ctx, "replication").Val()).To(ContainSubstring("role:master"))
+				g.Expect(rc.Set(Ctx, "failover", "ok", 0).Err()).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, credSecretLookup, &secret)).To(Succeed())
+				g.Expect(string(secret.Data["REDIS_HOST"])).To(HavePrefix(rdb.Name + "-1."))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Cluster mode ─────────────────────────────────────────────────────────
+	Context("when mode is cluster", Ordered, func() {
+		var (
+			ns               *corev1.Namespace
+			rdb              *v1alpha1.RedisDatabase
+			lookup           types.NamespacedName
+			secretLookup     types.NamespacedName
+			credSecretLookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, rdb, lookup, secretLookup = newTestRedisResources("test-rdb")
+			replicasPerShard := int32(1)
+			rdb.Spec.Mode = v1alpha1.RedisModeCluster
+			rdb.Spec.Cluster = &v1alpha1.RedisClusterSpec{Shards: 3, ReplicasPerShard: &replicasPerShard}
+			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+				g.Expect(fetched.Status.ClusterShards).To(Equal(int32(3)))
+			}, 4*Timeout, Interval).Should(Succeed())
+
+			rcred := &v1alpha1.RedisCredential{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "app",
+					Namespace: ns.Name,
+					Labels: map[string]string{
+						"db-operator.benjamin-wright.github.com/operator-instance": "test",
+					},
+				},
+				Spec: v1alpha1.RedisCredentialSpec{
+					DatabaseRef:   rdb.Name,
+					Username:      "app",
+					SecretName:    "app-redis",
+					KeyPatterns:   []string{"*"},
+					ACLCategories: []v1alpha1.RedisACLCategory{v1alpha1.RedisACLCategoryRead, v1alpha1.RedisACLCategoryWrite},
+				},
+			}
+			Expect(K8sClient.Create(Ctx, rcred)).To(Succeed())
+			credSecretLookup = types.NamespacedName{Name: rcred.Spec.SecretName, Namespace: ns.Name}
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisCredential
+				g.Expect(K8sClient.Get(Ctx, client.ObjectKeyFromObject(rcred), &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisCredentialPhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should form a healthy cluster of three masters with a replica each", func() {
+			for ordinal := range 6 {
+				rc, close := ConnectToRedisDatabaseOrdinal(lookup, secretLookup, ordinal)
+				Expect(rc.ClusterInfo(Ctx).Val()).To(ContainSubstring("cluster_state:ok"), "ordinal %d", ordinal)
+				close()
+			}
+
+			rc, close := ConnectToRedisDatabase(lookup, secretLookup)
+			defer close()
+			nodes := rc.ClusterNodes(Ctx).Val()
+			Expect(strings.Count(nodes, "master")).To(Equal(3))
+			Expect(strings.Count(nodes, "slave")).To(Equal(3))
+		})
+
+		It("should create the credential's ACL user on every node", func() {
+			for ordinal := range 6 {
+				rc, close := ConnectToRedisDatabaseOrdinal(lookup, credSecretLookup, ordinal)
+				Expect(rc.Ping(Ctx).Err()).To(Succeed(), "ordinal %d", ordinal)
+				close()
+			}
+		})
+
+		It("should add the cluster seed list to the credential Secret", func() {
+			var secret corev1.Secret
+			Expect(K8sClient.Get(Ctx, credSecretLookup, &secret)).To(Succeed())
+			nodes := strings.Split(string(secret.Data["REDIS_CLUSTER_NODES"]), ",")
+			Expect(nodes).To(HaveLen(6))
+			Expect(nodes[0]).To(Equal(fmt.Sprintf("%s-0.%s.%s.svc.cluster.local:6379", rdb.Name, rdb.Name, ns.Name)))
+		})
+
+		It("should move hash slots onto a new shard when shards grows", func() {
+			owner := func(g Gomega, key string) int {
+				rc, close := ConnectToRedisDatabase(lookup, secretLookup)
+				defer close()
+				slot := rc.ClusterKeySlot(Ctx, key).Val()
+				for _, slots := range rc.ClusterSlots(Ctx).Val() {
+					if int64(slots.Start) <= slot && slot <= int64(slots.End) {
+						var ordinal int
+						_, err := fmt.Sscanf(strings.TrimPrefix(slots.Nodes[0].Addr, rdb.Name+"-"), "%d.", &ordinal)
+						g.Expect(err).NotTo(HaveOccurred())
+						return ordinal
+					}
+				}
+				g.Expect(fmt.Errorf("slot %d is not served", slot)).NotTo(HaveOccurred())
+				return -1
+			}
+
+			// Slot 5461 is the last slot of the first shard, so it is among
+			// the first to move to the new one.
+			const key = "{resharded-50193}"
+			Eventually(func(g Gomega) {
+				rc, close := ConnectToRedisDatabaseOrdinal(lookup, secretLookup, owner(g, key))
+				defer close()
+				g.Expect(rc.Set(Ctx, key, "kept", 0).Err()).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Spec.Cluster.Shards = 4
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
+			}, Timeout, Interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.ClusterShards).To(Equal(int32(4)))
+				g.Expect(meta.IsStatusConditionFalse(fetched.Status.Conditions, "ClusterReconfiguring")).To(BeTrue())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, 6*Timeout, Interval).Should(Succeed())
+
+			rc, close := ConnectToRedisDatabase(lookup, secretLookup)
+			owned := map[string]int{}
+			for _, slots := range rc.ClusterSlots(Ctx).Val() {
+				owned[slots.Nodes[0].ID] += int(slots.End - slots.Start + 1)
+				Expect(slots.Nodes).To(HaveLen(2), "slots %d-%d should have a replica", slots.Start, slots.End)
+			}
+			close()
+			Expect(owned).To(HaveLen(4))
+			for id, n := range owned {
+				Expect(n).To(Equal(4096), "master %s", id)
+			}
+
+			Eventually(func(g Gomega) {
+				rc, close := ConnectToRedisDatabaseOrdinal(lookup, credSecretLookup, owner(g, key))
+				defer close()
+				g.Expect(rc.Get(Ctx, key).Val()).To(Equal("kept"))
+			}, Timeout, Interval).Should(Succeed())
+		})
+	})
+
+	// ── Metrics ──────────────────────────────────────────────────────────────
+	Context("when metrics are enabled", Ordered, func() {
+		var (
+			ns     *corev1.Namespace
+			rdb    *v1alpha1.RedisDatabase
+			lookup types.NamespacedName
+		)
+
+		BeforeAll(func() {
+			ns, rdb, lookup, _ = newTestRedisResources("test-rdb")
+			rdb.Spec.Metrics = &v1alpha1.MetricsSpec{
+				ServiceMonitor: &v1alpha1.ServiceMonitorSpec{},
+			}
+			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should expose a metrics port on the Service", func() {
+			var svc corev1.Service
+			Expect(K8sClient.Get(Ctx, lookup, &svc)).To(Succeed())
+			Expect(svc.Spec.Ports).To(ContainElement(HaveField("Name", "metrics")))
+		})
+
+		It("should report the server as up from the exporter sidecar", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(ScrapeMetrics(ns.Name, rdb.Name+"-0", 9121)).To(ContainSubstring("redis_up 1"))
+			}, Timeout, 2*time.Second).Should(Succeed())
+		})
+
+		It("should scrape as a user that can read statistics but not keys", func() {
+			metricsSecretLookup := types.NamespacedName{Namespace: ns.Name, Name: rdb.Name + "-metrics"}
+			redisCli, close := ConnectToRedisDatabase(lookup, metricsSecretLookup)
+			defer close()
+
+			Expect(redisCli.Info(Ctx, "server").Err()).To(Succeed())
+			Expect(redisCli.Get(Ctx, "foo").Err()).To(MatchError(ContainSubstring("NOPERM")))
+			Expect(redisCli.ConfigGet(Ctx, "requirepass").Err()).To(MatchError(ContainSubstring("NOPERM")))
+		})
+	})
+
+	// ── Admin password rotation ──────────────────────────────────────────────
+	Context("when the rotate-admin-password annotation is set", Ordered, func() {
+		var (
+			ns               *corev1.Namespace
+			rdb              *v1alpha1.RedisDatabase
+			lookup           types.NamespacedName
+			secretLookup     types.NamespacedName
+			originalPassword string
+		)
+
+		BeforeAll(func() {
+			ns, rdb, lookup, secretLookup = newTestRedisResources("test-rdb")
+			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var fetched v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+			}, Timeout, Interval).Should(Succeed())
+
+			var secret corev1.Secret
+			Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed())
+			originalPassword = string(secret.Data["REDIS_PASSWORD"])
+
+			Eventually(func(g Gomega) {
+				var latest v1alpha1.RedisDatabase
+				g.Expect(K8sClient.Get(Ctx, lookup, &latest)).To(Succeed())
+				latest.Annotations = map[string]string{v1alpha1.RotateAdminPasswordAnnotation: "1"}
+				g.Expect(K8sClient.Update(Ctx, &latest)).To(Succeed())
 			}, Timeout, Interval).Should(Succeed())
 		})
 
-		It("should return to Ready phase after the resize", func() {
+		AfterAll(func() {
+			_ = K8sClient.Delete(Ctx, ns)
+		})
+
+		It("should write a new password to the admin Secret and start the grace period", func() {
 			Eventually(func(g Gomega) {
 				var fetched v1alpha1.RedisDatabase
 				g.Expect(K8sClient.Get(Ctx, lookup, &fetched)).To(Succeed())
+				g.Expect(fetched.Status.AdminPasswordRotationRequest).To(Equal("1"))
+				g.Expect(fetched.Status.AdminPasswordRotatedAt).NotTo(BeNil())
+				g.Expect(fetched.Status.PreviousAdminPasswordExpiresAt).NotTo(BeNil())
 				g.Expect(fetched.Status.Phase).To(Equal(v1alpha1.RedisDatabasePhaseReady))
+
+				var secret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed())
+				g.Expect(string(secret.Data["REDIS_PASSWORD"])).NotTo(Equal(originalPassword))
+				g.Expect(secret.Data).NotTo(HaveKey("REDIS_PASSWORD_PENDING"))
 			}, Timeout, Interval).Should(Succeed())
 		})
+
+		It("should accept the new password", func() {
+			rc, close := ConnectToRedisDatabase(lookup, secretLookup)
+			defer close()
+			Expect(rc.Ping(Ctx).Err()).To(Succeed())
+		})
 	})
 
 	// ── Instance label filtering ─────────────────────────────────────────────
@@ -318,6 +937,9 @@ var _ = Describe("RedisDatabaseReconciler", func() {
 
 		BeforeAll(func() {
 			ns, rdb, lookup, secretLookup = newTestRedisResources("test-rdb")
+			rdb.Spec.Metrics = &v1alpha1.MetricsSpec{
+				ServiceMonitor: &v1alpha1.ServiceMonitorSpec{},
+			}
 			Expect(K8sClient.Create(Ctx, rdb)).To(Succeed())
 
 			// Wait for all owned resources to exist.
@@ -328,6 +950,8 @@ var _ = Describe("RedisDatabaseReconciler", func() {
 				g.Expect(K8sClient.Get(Ctx, lookup, &svc)).To(Succeed())
 				var secret corev1.Secret
 				g.Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed())
+				var metricsSecret corev1.Secret
+				g.Expect(K8sClient.Get(Ctx, types.NamespacedName{Namespace: ns.Name, Name: rdb.Name + "-metrics"}, &metricsSecret)).To(Succeed())
 			}, Timeout, Interval).Should(Succeed())
 
 			// Delete the CR and wait for it to be fully removed (finalizer handled).
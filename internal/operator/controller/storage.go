@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// storageResizingConditionType reports the progress of an in-place volume
+// expansion. It is added the first time a resize starts and reads False with
+// reason ResizeComplete once every volume has reached the requested size.
+const storageResizingConditionType = "StorageResizing"
+
+// volumeClaimClient is the part of the per-kind clients needed to expand
+// PersistentVolumeClaims in place.
+type volumeClaimClient interface {
+	get(ctx context.Context, key client.ObjectKey, obj client.Object) (bool, error)
+	update(ctx context.Context, obj client.Object) error
+}
+
+// storageClassName converts a spec's StorageClass name to the PVC field, where
+// nil selects the cluster's default StorageClass.
+func storageClassName(name string) *string {
+	if name == "" {
+		return nil
+	}
+	return &name
+}
+
+// volumeClaimStorageChanged returns true when the storage request or
+// StorageClass in the first VolumeClaimTemplate of the existing StatefulSet
+// differs from the desired one.
+func volumeClaimStorageChanged(existing, desired *appsv1.StatefulSet) bool {
+	if len(existing.Spec.VolumeClaimTemplates) == 0 || len(desired.Spec.VolumeClaimTemplates) == 0 {
+		return false
+	}
+	existingSpec := existing.Spec.VolumeClaimTemplates[0].Spec
+	desiredSpec := desired.Spec.VolumeClaimTemplates[0].Spec
+	existingStorage := existingSpec.Resources.Requests[corev1.ResourceStorage]
+	desiredStorage := desiredSpec.Resources.Requests[corev1.ResourceStorage]
+	return existingStorage.Cmp(desiredStorage) != 0 ||
+		derefString(existingSpec.StorageClassName) != derefString(desiredSpec.StorageClassName)
+}
+
+// volumeClaimStorageGrown returns true when the only change between the first
+// VolumeClaimTemplates of existing and desired is a larger storage request,
+// which the live claims can take without being recreated.
+func volumeClaimStorageGrown(existing, desired *appsv1.StatefulSet) bool {
+	if len(existing.Spec.VolumeClaimTemplates) == 0 || len(desired.Spec.VolumeClaimTemplates) == 0 {
+		return false
+	}
+	existingSpec := existing.Spec.VolumeClaimTemplates[0].Spec
+	desiredSpec := desired.Spec.VolumeClaimTemplates[0].Spec
+	existingStorage := existingSpec.Resources.Requests[corev1.ResourceStorage]
+	desiredStorage := desiredSpec.Resources.Requests[corev1.ResourceStorage]
+	return existingStorage.Cmp(desiredStorage) < 0 &&
+		derefString(existingSpec.StorageClassName) == derefString(desiredSpec.StorageClassName)
+}
+
+// volumeClaimsExpandable reports whether every existing claim in keys belongs
+// to a StorageClass that allows volume expansion. Claims that do not exist yet
+// are ignored, since they will be created from the updated template.
+func volumeClaimsExpandable(ctx context.Context, c volumeClaimClient, keys []client.ObjectKey) (bool, error) {
+	for _, key := range keys {
+		var pvc corev1.PersistentVolumeClaim
+		found, err := c.get(ctx, key, &pvc)
+		if err != nil {
+			return false, fmt.Errorf("fetching PVC %s: %w", key.Name, err)
+		}
+		if !found {
+			continue
+		}
+		expandable, err := storageClassAllowsExpansion(ctx, c, derefString(pvc.Spec.StorageClassName))
+		if err != nil || !expandable {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// storageClassAllowsExpansion reports whether the named StorageClass exists
+// and sets allowVolumeExpansion.
+func storageClassAllowsExpansion(ctx context.Context, c volumeClaimClient, name string) (bool, error) {
+	if name == "" {
+		return false, nil
+	}
+	var sc storagev1.StorageClass
+	found, err := c.get(ctx, client.ObjectKey{Name: name}, &sc)
+	if err != nil {
+		return false, fmt.Errorf("fetching StorageClass %s: %w", name, err)
+	}
+	return found && sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// expandVolumeClaims raises the storage request of each existing claim in
+// keys to size. Claims that are missing or already request at least size are
+// left alone; callers check volumeClaimsExpandable first.
+func expandVolumeClaims(ctx context.Context, c volumeClaimClient, keys []client.ObjectKey, size resource.Quantity) error {
+	for _, key := range keys {
+		var pvc corev1.PersistentVolumeClaim
+		found, err := c.get(ctx, key, &pvc)
+		if err != nil {
+			return fmt.Errorf("fetching PVC %s: %w", key.Name, err)
+		}
+		if !found {
+			continue
+		}
+		current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if current.Cmp(size) >= 0 {
+			continue
+		}
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size.DeepCopy()
+		if err := c.update(ctx, &pvc); err != nil {
+			return fmt.Errorf("expanding PVC %s: %w", key.Name, err)
+		}
+	}
+	return nil
+}
+
+// reconcileStorageResizing records the progress of in-place expansion of the
+// claims in keys in the StorageResizing condition and reports whether any
+// bound claim has yet to reach its requested size. PVC status changes do not
+// trigger a reconcile, so callers requeue while it returns true.
+func reconcileStorageResizing(ctx context.Context, c volumeClaimClient, conditions *[]metav1.Condition, generation int64, keys []client.ObjectKey) (bool, error) {
+	var expanding, fsPending []string
+	for _, key := range keys {
+		var pvc corev1.PersistentVolumeClaim
+		found, err := c.get(ctx, key, &pvc)
+		if err != nil {
+			return false, fmt.Errorf("fetching PVC %s: %w", key.Name, err)
+		}
+		if !found || pvc.Status.Phase != corev1.ClaimBound {
+			continue
+		}
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(requested) >= 0 {
+			continue
+		}
+		if fileSystemResizePending(&pvc) {
+			fsPending = append(fsPending, pvc.Name)
+		} else {
+			expanding = append(expanding, pvc.Name)
+		}
+	}
+
+	switch {
+	case len(expanding) > 0:
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               storageResizingConditionType,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Expanding",
+			Message:            "waiting for volumes to expand: " + strings.Join(expanding, ", "),
+			ObservedGeneration: generation,
+		})
+		return true, nil
+	case len(fsPending) > 0:
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               storageResizingConditionType,
+			Status:             metav1.ConditionTrue,
+			Reason:             "FileSystemResizePending",
+			Message:            "waiting for file systems to be resized on the node: " + strings.Join(fsPending, ", "),
+			ObservedGeneration: generation,
+		})
+		return true, nil
+	}
+
+	if meta.IsStatusConditionTrue(*conditions, storageResizingConditionType) {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               storageResizingConditionType,
+			Status:             metav1.ConditionFalse,
+			Reason:             "ResizeComplete",
+			Message:            "all volumes have reached the requested size",
+			ObservedGeneration: generation,
+		})
+	}
+	return false, nil
+}
+
+// setStorageResizeSkipped records in the StorageResizing condition that a
+// storage change could not be applied to the volumes in place.
+func setStorageResizeSkipped(conditions *[]metav1.Condition, generation int64, reason, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               storageResizingConditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// fileSystemResizePending reports whether the volume behind pvc has been
+// expanded and is waiting for the kubelet to grow its file system.
+func fileSystemResizePending(pvc *corev1.PersistentVolumeClaim) bool {
+	for _, c := range pvc.Status.Conditions {
+		if c.Type == corev1.PersistentVolumeClaimFileSystemResizePending && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
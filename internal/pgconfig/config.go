@@ -0,0 +1,136 @@
+package pgconfig
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChecksumSetting is a custom setting written at the end of every rendered
+// configuration. Reading it back with current_setting() shows which revision
+// of the file a running server has loaded.
+const ChecksumSetting = "db_operator.config_checksum"
+
+// defaults are operator-chosen settings that user parameters may override.
+// wal_keep_size retains enough WAL for a briefly disconnected standby to resume.
+var defaults = map[string]string{
+	"wal_keep_size": "64MB",
+}
+
+// restartParameters lists the settings PostgreSQL only reads at server start
+// (context "postmaster" in pg_settings) for the supported major versions.
+var restartParameters = map[string]bool{
+	"archive_mode":                    true,
+	"autovacuum_max_workers":          true,
+	"bonjour":                         true,
+	"bonjour_name":                    true,
+	"cluster_name":                    true,
+	"dynamic_shared_memory_type":      true,
+	"event_source":                    true,
+	"hot_standby":                     true,
+	"huge_pages":                      true,
+	"huge_page_size":                  true,
+	"jit_provider":                    true,
+	"logging_collector":               true,
+	"max_connections":                 true,
+	"max_files_per_process":           true,
+	"max_locks_per_transaction":       true,
+	"max_logical_replication_workers": true,
+	"max_pred_locks_per_transaction":  true,
+	"max_prepared_transactions":       true,
+	"max_replication_slots":           true,
+	"max_wal_senders":                 true,
+	"max_worker_processes":            true,
+	"min_dynamic_shared_memory":       true,
+	"old_snapshot_threshold":          true,
+	"recovery_target":                 true,
+	"recovery_target_action":          true,
+	"recovery_target_inclusive":       true,
+	"recovery_target_lsn":             true,
+	"recovery_target_name":            true,
+	"recovery_target_time":            true,
+	"recovery_target_timeline":        true,
+	"recovery_target_xid":             true,
+	"reserved_connections":            true,
+	"shared_buffers":                  true,
+	"shared_memory_type":              true,
+	"shared_preload_libraries":        true,
+	"superuser_reserved_connections":  true,
+	"track_activity_query_size":       true,
+	"track_commit_timestamp":          true,
+	"unix_socket_directories":         true,
+	"unix_socket_group":               true,
+	"unix_socket_permissions":         true,
+	"wal_buffers":                     true,
+	"wal_decode_buffer_size":          true,
+	"wal_level":                       true,
+	"wal_log_hints":                   true,
+}
+
+// RequiresRestart reports whether a change to the named parameter only takes
+// effect after the server restarts. Names are case-insensitive, as in PostgreSQL.
+func RequiresRestart(name string) bool {
+	return restartParameters[strings.ToLower(name)]
+}
+
+// Build renders a postgresql.conf that first includes base, the configuration
+// initdb wrote into the data directory, then applies the operator defaults and
+// params on top. Parameters are written in name order so the output is stable.
+//
+// The returned revision identifies the rendered content. It is also written
+// into the file as ChecksumSetting, so a server reports which revision it has
+// loaded.
+func Build(base string, params map[string]string) (config, revision string) {
+	merged := make(map[string]string, len(defaults)+len(params))
+	for name, value := range defaults {
+		merged[name] = value
+	}
+	for name, value := range params {
+		merged[strings.ToLower(name)] = value
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "include_if_exists = %s\n", quote(base))
+	for _, name := range sortedKeys(merged) {
+		fmt.Fprintf(&b, "%s = %s\n", name, quote(merged[name]))
+	}
+	revision = Checksum(b.String())
+	fmt.Fprintf(&b, "%s = %s\n", ChecksumSetting, quote(revision))
+	return b.String(), revision
+}
+
+// RestartChecksum digests only the parameters in params that require a
+// restart. Used as a pod template annotation, it rolls the pods when such a
+// parameter changes and leaves them running when only reloadable ones do.
+func RestartChecksum(params map[string]string) string {
+	var b strings.Builder
+	for _, name := range sortedKeys(params) {
+		if RequiresRestart(name) {
+			fmt.Fprintf(&b, "%s=%s\n", strings.ToLower(name), params[name])
+		}
+	}
+	return Checksum(b.String())
+}
+
+// Checksum returns a short SHA-256 hex digest of s.
+func Checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// quote renders value as a single-quoted configuration string.
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `''`)
+	return "'" + value + "'"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,111 @@
+package pgconfig_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benjamin-wright/db-operator/internal/pgconfig"
+)
+
+const base = "/var/lib/postgresql/data/postgresql.conf"
+
+// build renders params against base, discarding the revision.
+func build(params map[string]string) string {
+	config, _ := pgconfig.Build(base, params)
+	return config
+}
+
+// body strips the trailing revision line so tests can compare the rest exactly.
+func body(config string) string {
+	lines := strings.SplitAfter(config, "\n")
+	return strings.Join(lines[:len(lines)-2], "")
+}
+
+func TestBuild_Defaults(t *testing.T) {
+	got := body(build(nil))
+	want := "include_if_exists = '/var/lib/postgresql/data/postgresql.conf'\nwal_keep_size = '64MB'\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuild_SortedAndOverridesDefaults(t *testing.T) {
+	got := body(build(map[string]string{
+		"work_mem":        "16MB",
+		"Max_Connections": "200",
+		"wal_keep_size":   "1GB",
+	}))
+	want := `include_if_exists = '/var/lib/postgresql/data/postgresql.conf'
+max_connections = '200'
+wal_keep_size = '1GB'
+work_mem = '16MB'
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuild_QuotesValues(t *testing.T) {
+	got := build(map[string]string{"log_line_prefix": `%m it's \ here `})
+	want := `log_line_prefix = '%m it''s \\ here '` + "\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("expected %q in:\n%s", want, got)
+	}
+}
+
+func TestBuild_EndsWithRevision(t *testing.T) {
+	config, revision := pgconfig.Build(base, map[string]string{"work_mem": "16MB"})
+	if revision != pgconfig.Checksum(body(config)) {
+		t.Errorf("expected revision to be the checksum of the parameters, got %q", revision)
+	}
+	want := pgconfig.ChecksumSetting + " = '" + revision + "'\n"
+	if !strings.HasSuffix(config, want) {
+		t.Errorf("expected config to end with %q, got:\n%s", want, config)
+	}
+}
+
+func TestBuild_RevisionChangesWithParameters(t *testing.T) {
+	_, a := pgconfig.Build(base, map[string]string{"work_mem": "16MB"})
+	_, b := pgconfig.Build(base, map[string]string{"work_mem": "32MB"})
+	if a == b {
+		t.Error("expected different revisions for different parameters")
+	}
+}
+
+func TestRequiresRestart(t *testing.T) {
+	cases := map[string]bool{
+		"shared_buffers":             true,
+		"MAX_CONNECTIONS":            true,
+		"shared_preload_libraries":   true,
+		"work_mem":                   false,
+		"log_min_duration_statement": false,
+	}
+	for name, want := range cases {
+		if got := pgconfig.RequiresRestart(name); got != want {
+			t.Errorf("RequiresRestart(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRestartChecksum_IgnoresReloadableParameters(t *testing.T) {
+	a := pgconfig.RestartChecksum(map[string]string{"shared_buffers": "128MB", "work_mem": "4MB"})
+	b := pgconfig.RestartChecksum(map[string]string{"shared_buffers": "128MB", "work_mem": "64MB"})
+	if a != b {
+		t.Error("expected reloadable parameters not to affect the restart checksum")
+	}
+}
+
+func TestRestartChecksum_ChangesWithRestartParameters(t *testing.T) {
+	a := pgconfig.RestartChecksum(map[string]string{"shared_buffers": "128MB"})
+	b := pgconfig.RestartChecksum(map[string]string{"shared_buffers": "256MB"})
+	if a == b {
+		t.Error("expected different checksums for different restart parameters")
+	}
+}
+
+func TestChecksum_Length(t *testing.T) {
+	sum := pgconfig.Checksum("any config")
+	if len(sum) != 16 {
+		t.Errorf("expected 16 hex chars (8 bytes), got %d: %q", len(sum), sum)
+	}
+}
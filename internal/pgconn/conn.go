@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/lib/pq"
 )
@@ -14,6 +15,10 @@ type ConnDetails struct {
 	Port     string
 	User     string
 	Password string
+	// SSLMode is the libpq sslmode to connect with; empty means disable.
+	SSLMode string
+	// CACert is the PEM CA bundle the server certificate is verified against.
+	CACert string
 }
 
 // Column describes a result column.
@@ -32,10 +37,17 @@ type QueryResult struct {
 // to 10 seconds, executes sqlText, and returns up to rowLimit rows.
 // The connection is closed before returning.
 func Query(ctx context.Context, details ConnDetails, database, sqlText string, rowLimit int) (*QueryResult, error) {
+	sslMode := details.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
 	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		details.Host, details.Port, details.User, details.Password, database,
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		details.Host, details.Port, details.User, details.Password, database, sslMode,
 	)
+	if details.CACert != "" {
+		dsn += " sslinline=true sslrootcert=" + QuoteValue(details.CACert)
+	}
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
@@ -99,3 +111,11 @@ func Query(ctx context.Context, details ConnDetails, database, sqlText string, r
 
 	return &QueryResult{Columns: columns, Rows: result}, nil
 }
+
+// QuoteValue renders value as a single-quoted value for a libpq key/value
+// connection string.
+func QuoteValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
@@ -16,6 +16,9 @@ type ClusterInfo struct {
 	Port      string
 	User      string
 	Password  string
+	// SSLMode and CACert mirror the credential Secret's PGSSLMODE and ca.crt.
+	SSLMode   string
+	CACert    string
 	Databases []string
 	// Ready is true once the operator-produced credential Secret is present and populated.
 	Ready bool
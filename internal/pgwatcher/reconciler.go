@@ -106,6 +106,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		Port:      string(secret.Data["PGPORT"]),
 		User:      string(secret.Data["PGUSER"]),
 		Password:  string(secret.Data["PGPASSWORD"]),
+		SSLMode:   string(secret.Data["PGSSLMODE"]),
+		CACert:    string(secret.Data["ca.crt"]),
 		Databases: userDatabases,
 		Ready:     true,
 	})
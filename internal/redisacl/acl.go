@@ -0,0 +1,123 @@
+// Package redisacl renders the Redis ACL user a RedisCredential declares and
+// compares it with the user a server reports. It only computes; the operator
+// issues the resulting commands.
+package redisacl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+// Spec is the ACL user a RedisCredential declares.
+type Spec struct {
+	Password    string
+	KeyPatterns []string
+	Categories  []v1alpha1.RedisACLCategory
+	Commands    []string
+}
+
+// User is an ACL user as ACL GETUSER reports it.
+type User struct {
+	Flags []string
+	// Passwords are the SHA-256 hashes, hex encoded, of the passwords the
+	// user accepts.
+	Passwords []string
+	Commands  string
+	Keys      string
+	Channels  string
+	// Selectors holds the user's additional selectors, each rendered as its
+	// rules.
+	Selectors []string
+}
+
+// Rules returns the ACL SETUSER rules that turn any existing user into the
+// one spec declares. They start from reset, so passwords, patterns and
+// selectors added by hand are dropped rather than kept alongside.
+func Rules(spec Spec) []string {
+	rules := []string{"reset", "on", ">" + spec.Password}
+	for _, p := range spec.KeyPatterns {
+		rules = append(rules, "~"+p)
+	}
+	for _, cat := range spec.Categories {
+		rules = append(rules, "+@"+string(cat))
+	}
+	for _, cmd := range spec.Commands {
+		rules = append(rules, "+"+cmd)
+	}
+	return rules
+}
+
+// Drift returns the parts of user that differ from spec, as the names ACL
+// GETUSER gives them, or nil when the user matches.
+func Drift(user User, spec Spec) []string {
+	var drift []string
+	if !slices.Contains(user.Flags, "on") {
+		drift = append(drift, "flags")
+	}
+	if !slices.Equal(user.Passwords, []string{passwordHash(spec.Password)}) {
+		drift = append(drift, "passwords")
+	}
+	if !sameRules(commandRules(user.Commands), desiredCommandRules(spec)) {
+		drift = append(drift, "commands")
+	}
+	keys := make([]string, 0, len(spec.KeyPatterns))
+	for _, p := range spec.KeyPatterns {
+		keys = append(keys, "~"+p)
+	}
+	if !sameRules(strings.Fields(user.Keys), keys) {
+		drift = append(drift, "keys")
+	}
+	if user.Channels != "" {
+		drift = append(drift, "channels")
+	}
+	if len(user.Selectors) > 0 {
+		drift = append(drift, "selectors")
+	}
+	return drift
+}
+
+// desiredCommandRules returns the command rules ACL GETUSER reports for a
+// user created from spec. Redis lowercases them, and a +@all rule replaces
+// every rule before it.
+func desiredCommandRules(spec Spec) []string {
+	rules := []string{"-@all"}
+	for _, cat := range spec.Categories {
+		rules = append(rules, "+@"+string(cat))
+	}
+	for _, cmd := range spec.Commands {
+		rules = append(rules, "+"+cmd)
+	}
+	return commandRules(strings.Join(rules, " "))
+}
+
+// commandRules splits a command rule string, keeping only the rules from the
+// last one that resets every command.
+func commandRules(text string) []string {
+	rules := strings.Fields(strings.ToLower(text))
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i] == "+@all" || rules[i] == "-@all" {
+			return rules[i:]
+		}
+	}
+	return rules
+}
+
+// sameRules reports whether two rule lists hold the same rules, ignoring
+// order and repeats. Every rule the operator sets grants rather than revokes,
+// so their order does not change what the user may do.
+func sameRules(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// passwordHash returns the hash ACL GETUSER reports for password.
+func passwordHash(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
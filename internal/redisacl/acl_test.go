@@ -0,0 +1,79 @@
+package redisacl_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/benjamin-wright/db-operator/internal/redisacl"
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+// sha256 of "secret".
+const secretHash = "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+
+var spec = redisacl.Spec{
+	Password:    "secret",
+	KeyPatterns: []string{"cache:*", "session:*"},
+	Categories:  []v1alpha1.RedisACLCategory{v1alpha1.RedisACLCategoryRead, v1alpha1.RedisACLCategoryWrite},
+	Commands:    []string{"PING"},
+}
+
+// matching is the user Redis reports after applying Rules(spec).
+func matching() redisacl.User {
+	return redisacl.User{
+		Flags:     []string{"on", "sanitize-payload"},
+		Passwords: []string{secretHash},
+		Commands:  "-@all +@read +@write +ping",
+		Keys:      "~cache:* ~session:*",
+	}
+}
+
+func TestRules(t *testing.T) {
+	got := redisacl.Rules(spec)
+	want := []string{"reset", "on", ">secret", "~cache:*", "~session:*", "+@read", "+@write", "+PING"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDrift_InSync(t *testing.T) {
+	if drift := redisacl.Drift(matching(), spec); len(drift) != 0 {
+		t.Errorf("got drift %v for a matching user", drift)
+	}
+}
+
+func TestDrift_InSyncInAnyOrder(t *testing.T) {
+	user := matching()
+	user.Commands = "-@all +ping +@write +@read"
+	user.Keys = "~session:* ~cache:*"
+	if drift := redisacl.Drift(user, spec); len(drift) != 0 {
+		t.Errorf("got drift %v for reordered rules", drift)
+	}
+}
+
+func TestDrift_AllCategory(t *testing.T) {
+	all := spec
+	all.Categories = []v1alpha1.RedisACLCategory{v1alpha1.RedisACLCategoryRead, v1alpha1.RedisACLCategoryAll}
+	all.Commands = nil
+	user := matching()
+	user.Commands = "+@all"
+	if drift := redisacl.Drift(user, all); len(drift) != 0 {
+		t.Errorf("got drift %v, want +@all to replace the rules before it", drift)
+	}
+}
+
+func TestDrift_Changed(t *testing.T) {
+	user := redisacl.User{
+		Flags:     []string{"off"},
+		Passwords: []string{secretHash, "0000"},
+		Commands:  "-@all +@read +@write +ping +flushall",
+		Keys:      "~*",
+		Channels:  "&*",
+		Selectors: []string{"~other:* +get"},
+	}
+	got := redisacl.Drift(user, spec)
+	want := []string{"flags", "passwords", "commands", "keys", "channels", "selectors"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+// Package rediscluster reads Redis Cluster topology and plans how hash slots
+// are spread over its masters. It only computes; the operator issues the
+// resulting commands.
+package rediscluster
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SlotCount is the number of hash slots a Redis Cluster divides keys into.
+const SlotCount = 16384
+
+// Node is one line of CLUSTER NODES: a node as seen by the node that was
+// asked.
+type Node struct {
+	ID string
+	// Addr is the node's ip:port, without the cluster bus port or hostname.
+	Addr     string
+	Hostname string
+	Flags    []string
+	// MasterID is the ID of the master a replica follows, or "" for a master.
+	MasterID string
+	// Slots lists the slots the node owns. Slots being migrated or imported
+	// are left out.
+	Slots []int
+}
+
+// Myself reports whether the node is the one that answered CLUSTER NODES.
+func (n Node) Myself() bool { return slices.Contains(n.Flags, "myself") }
+
+// Master reports whether the node is a master.
+func (n Node) Master() bool { return slices.Contains(n.Flags, "master") }
+
+// Failed reports whether the node is unreachable or flagged as failing.
+func (n Node) Failed() bool {
+	return slices.Contains(n.Flags, "fail") || slices.Contains(n.Flags, "noaddr")
+}
+
+// Handshake reports whether the node has been met but not yet joined.
+func (n Node) Handshake() bool { return slices.Contains(n.Flags, "handshake") }
+
+// IP returns the host part of Addr.
+func (n Node) IP() string {
+	ip, _, _ := strings.Cut(n.Addr, ":")
+	return ip
+}
+
+// ParseNodes parses the output of CLUSTER NODES.
+func ParseNodes(text string) ([]Node, error) {
+	var nodes []Node
+	for line := range strings.SplitSeq(strings.TrimSpace(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("malformed CLUSTER NODES line %q", line)
+		}
+
+		node := Node{ID: fields[0], Flags: strings.Split(fields[2], ",")}
+		addr, hostname, _ := strings.Cut(fields[1], ",")
+		node.Addr, _, _ = strings.Cut(addr, "@")
+		node.Hostname = hostname
+		if fields[3] != "-" {
+			node.MasterID = fields[3]
+		}
+		for _, field := range fields[8:] {
+			if strings.HasPrefix(field, "[") {
+				continue
+			}
+			first, last, isRange := strings.Cut(field, "-")
+			start, err := strconv.Atoi(first)
+			if err != nil {
+				return nil, fmt.Errorf("malformed slot %q: %w", field, err)
+			}
+			end := start
+			if isRange {
+				if end, err = strconv.Atoi(last); err != nil {
+					return nil, fmt.Errorf("malformed slot %q: %w", field, err)
+				}
+			}
+			for slot := start; slot <= end; slot++ {
+				node.Slots = append(node.Slots, slot)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// SlotRange is an inclusive range of hash slots.
+type SlotRange struct {
+	Start, End int
+}
+
+// SplitSlots divides every slot into n contiguous ranges whose sizes differ
+// by at most one, the larger ranges first.
+func SplitSlots(n int) []SlotRange {
+	ranges := make([]SlotRange, 0, n)
+	start := 0
+	for i := range n {
+		size := targetSlots(i, n)
+		ranges = append(ranges, SlotRange{Start: start, End: start + size - 1})
+		start += size
+	}
+	return ranges
+}
+
+// Move migrates one slot between two masters, identified by node ID.
+type Move struct {
+	Slot     int
+	From, To string
+}
+
+// PlanRebalance returns up to limit slot moves that leave every master with
+// SlotCount/len(masters) slots, or one more. The extra slots stay with the
+// masters that already own the most, so a balanced cluster needs no moves
+// whatever order masters are listed in. Masters that own too many slots give
+// up their highest slots first, so each keeps a contiguous range where it
+// had one. The plan is deterministic, so a rebalance interrupted part-way is
+// resumed rather than redirected.
+func PlanRebalance(masters []Node, limit int) []Move {
+	n := len(masters)
+	if n == 0 {
+		return nil
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return len(masters[b].Slots) - len(masters[a].Slots)
+	})
+	targets := make([]int, n)
+	for rank, i := range order {
+		targets[i] = targetSlots(rank, n)
+	}
+
+	type donor struct {
+		id    string
+		slots []int
+	}
+	var donors []donor
+	var recipients []string
+	var wants []int
+	for i, master := range masters {
+		target := targets[i]
+		switch have := len(master.Slots); {
+		case have > target:
+			slots := slices.Clone(master.Slots)
+			slices.Sort(slots)
+			donors = append(donors, donor{id: master.ID, slots: slots[target:]})
+		case have < target:
+			recipients = append(recipients, master.ID)
+			wants = append(wants, target-have)
+		}
+	}
+
+	var moves []Move
+	for d := range donors {
+		slots := donors[d].slots
+		for len(slots) > 0 && len(recipients) > 0 && len(moves) < limit {
+			slot := slots[len(slots)-1]
+			slots = slots[:len(slots)-1]
+			moves = append(moves, Move{Slot: slot, From: donors[d].id, To: recipients[0]})
+			wants[0]--
+			if wants[0] == 0 {
+				recipients, wants = recipients[1:], wants[1:]
+			}
+		}
+	}
+	return moves
+}
+
+// targetSlots is how many slots the i-th of n masters should own.
+func targetSlots(i, n int) int {
+	size := SlotCount / n
+	if i < SlotCount%n {
+		size++
+	}
+	return size
+}
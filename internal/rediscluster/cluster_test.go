@@ -0,0 +1,132 @@
+package rediscluster_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/benjamin-wright/db-operator/internal/rediscluster"
+)
+
+const clusterNodes = `07c37dfeb235213a872192d90877d0cd55635b91 10.0.0.4:6379@16379,cache-1 slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected
+67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 10.0.0.3:6379@16379,cache-2 master - 0 1426238316232 2 connected 5461-10922
+292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 10.0.0.5:6379@16379,cache-3 master - 0 1426238318243 3 connected 10923-16383 [42->-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1]
+e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 10.0.0.2:6379@16379,cache-0 myself,master - 0 0 1 connected 0-5459 5460
+6ec23923021cf3ffec47632106199cb7f496ce01 :0@0 noaddr,handshake - 0 0 0 disconnected
+`
+
+func TestParseNodes(t *testing.T) {
+	nodes, err := rediscluster.ParseNodes(clusterNodes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodes) != 5 {
+		t.Fatalf("got %d nodes, want 5", len(nodes))
+	}
+
+	replica := nodes[0]
+	if replica.Master() || replica.MasterID != "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca" {
+		t.Errorf("replica parsed as master %v following %q", replica.Master(), replica.MasterID)
+	}
+	if replica.Addr != "10.0.0.4:6379" || replica.IP() != "10.0.0.4" || replica.Hostname != "cache-1" {
+		t.Errorf("got addr %q ip %q hostname %q", replica.Addr, replica.IP(), replica.Hostname)
+	}
+
+	if got := len(nodes[2].Slots); got != 16384-10923 {
+		t.Errorf("got %d slots with a migrating slot, want %d", got, 16384-10923)
+	}
+
+	self := nodes[3]
+	if !self.Myself() || !self.Master() || self.MasterID != "" {
+		t.Errorf("got flags %v and master %q for myself", self.Flags, self.MasterID)
+	}
+	if len(self.Slots) != 5461 || self.Slots[5460] != 5460 {
+		t.Errorf("got %d slots ending %d, want 5461 ending 5460", len(self.Slots), self.Slots[len(self.Slots)-1])
+	}
+
+	if !nodes[4].Handshake() || !nodes[4].Failed() {
+		t.Errorf("got flags %v, want handshake and noaddr", nodes[4].Flags)
+	}
+}
+
+func TestParseNodes_Malformed(t *testing.T) {
+	if _, err := rediscluster.ParseNodes("abc 10.0.0.1:6379@16379 master"); err == nil {
+		t.Error("expected an error for a truncated line")
+	}
+	if _, err := rediscluster.ParseNodes("abc 10.0.0.1:6379@16379 master - 0 0 1 connected x-7"); err == nil {
+		t.Error("expected an error for a malformed slot")
+	}
+}
+
+func TestSplitSlots(t *testing.T) {
+	got := rediscluster.SplitSlots(3)
+	want := []rediscluster.SlotRange{{Start: 0, End: 5461}, {Start: 5462, End: 10922}, {Start: 10923, End: 16383}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func mastersFromRanges(ranges ...rediscluster.SlotRange) []rediscluster.Node {
+	masters := make([]rediscluster.Node, len(ranges))
+	for i, r := range ranges {
+		masters[i].ID = string(rune('a' + i))
+		for slot := r.Start; slot <= r.End; slot++ {
+			masters[i].Slots = append(masters[i].Slots, slot)
+		}
+	}
+	return masters
+}
+
+func TestPlanRebalance_Balanced(t *testing.T) {
+	masters := mastersFromRanges(rediscluster.SplitSlots(3)...)
+	if moves := rediscluster.PlanRebalance(masters, 100); len(moves) != 0 {
+		t.Errorf("got %d moves for a balanced cluster", len(moves))
+	}
+}
+
+func TestPlanRebalance_BalancedInAnyOrder(t *testing.T) {
+	ranges := rediscluster.SplitSlots(3)
+	masters := mastersFromRanges(ranges[2], ranges[1], ranges[0])
+	if moves := rediscluster.PlanRebalance(masters, 100); len(moves) != 0 {
+		t.Errorf("got %d moves after the masters were reordered", len(moves))
+	}
+}
+
+func TestPlanRebalance_NewMaster(t *testing.T) {
+	masters := mastersFromRanges(append(rediscluster.SplitSlots(3), rediscluster.SlotRange{Start: 0, End: -1})...)
+
+	moves := rediscluster.PlanRebalance(masters, rediscluster.SlotCount)
+	if len(moves) != 4096 {
+		t.Fatalf("got %d moves, want 4096", len(moves))
+	}
+	owned := map[string]int{}
+	for _, m := range masters {
+		owned[m.ID] = len(m.Slots)
+	}
+	for _, m := range moves {
+		if m.To != "d" {
+			t.Fatalf("slot %d moved to %q, want d", m.Slot, m.To)
+		}
+		owned[m.From]--
+		owned[m.To]++
+	}
+	for id, n := range owned {
+		if n != 4096 {
+			t.Errorf("master %s owns %d slots after the rebalance, want 4096", id, n)
+		}
+	}
+	if moves[0].Slot != 5461 || moves[0].From != "a" {
+		t.Errorf("got first move %+v, want a's highest slot", moves[0])
+	}
+}
+
+func TestPlanRebalance_Limit(t *testing.T) {
+	masters := mastersFromRanges(rediscluster.SplitSlots(1)[0], rediscluster.SlotRange{Start: 0, End: -1})
+
+	first := rediscluster.PlanRebalance(masters, 10)
+	if len(first) != 10 {
+		t.Fatalf("got %d moves, want the limit of 10", len(first))
+	}
+	if again := rediscluster.PlanRebalance(masters, 10); !slices.Equal(first, again) {
+		t.Errorf("plan is not deterministic: %v then %v", first, again)
+	}
+}
@@ -0,0 +1,106 @@
+package redisconfig
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+// DataDir is where the instance's volume is mounted, and where Redis writes
+// its RDB snapshots and append-only file.
+const DataDir = "/data"
+
+// ACLFile is where ACL users are kept on the instance's volume, so users
+// created at runtime survive a restart. The start script seeds it with the
+// default user.
+const ACLFile = DataDir + "/users.acl"
+
+// maxMemoryLimitPercent is the share of the container memory limit given to
+// maxmemory when it is not set explicitly. The rest covers client buffers,
+// fragmentation, and the copy-on-write pages of the fork that persists data.
+const maxMemoryLimitPercent = 75
+
+// Build generates the redis.conf for an instance. Settings not rendered here
+// keep Redis's built-in defaults; a nil persistence leaves persistence at
+// those defaults too. ACLs are always kept in ACLFile.
+func Build(persistence *v1alpha1.RedisPersistenceSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "dir %s\n", DataDir)
+	fmt.Fprintf(&b, "aclfile %s\n", ACLFile)
+
+	if persistence == nil {
+		return b.String()
+	}
+
+	if persistence.RDB == nil {
+		b.WriteString("save \"\"\n")
+	} else {
+		points := make([]string, 0, len(persistence.RDB.SavePoints))
+		for _, p := range persistence.RDB.SavePoints {
+			points = append(points, fmt.Sprintf("%d %d", p.Seconds, p.Changes))
+		}
+		fmt.Fprintf(&b, "save %s\n", strings.Join(points, " "))
+	}
+
+	if persistence.AOF == nil {
+		b.WriteString("appendonly no\n")
+	} else {
+		fsync := persistence.AOF.Fsync
+		if fsync == "" {
+			fsync = v1alpha1.RedisAppendFsyncEverySec
+		}
+		b.WriteString("appendonly yes\n")
+		fmt.Fprintf(&b, "appendfsync %s\n", fsync)
+	}
+
+	return b.String()
+}
+
+// Cluster generates the redis.conf settings of a Redis Cluster node. The
+// node's state lives in nodes.conf on its volume, so a restarted pod rejoins
+// with the same node ID and slots. Redirects name nodes by hostname, which
+// the pod announces at startup, since pod IPs change across restarts.
+func Cluster() string {
+	var b strings.Builder
+	b.WriteString("cluster-enabled yes\n")
+	fmt.Fprintf(&b, "cluster-config-file %s/nodes.conf\n", DataDir)
+	b.WriteString("cluster-node-timeout 5000\n")
+	b.WriteString("cluster-preferred-endpoint-type hostname\n")
+	return b.String()
+}
+
+// Memory generates the memory settings of redis.conf. They are kept out of
+// Build because the operator applies them to a running server with CONFIG
+// SET; the file only makes them survive a restart.
+func Memory(spec *v1alpha1.RedisDatabaseSpec) string {
+	return fmt.Sprintf("maxmemory %d\nmaxmemory-policy %s\n", MaxMemory(spec), EvictionPolicy(spec))
+}
+
+// MaxMemory returns the maxmemory of an instance in bytes: spec.maxMemory
+// when set, otherwise maxMemoryLimitPercent of the Redis container's memory
+// limit, otherwise 0, which Redis treats as no cap.
+func MaxMemory(spec *v1alpha1.RedisDatabaseSpec) int64 {
+	if spec.MaxMemory != nil {
+		return spec.MaxMemory.Value()
+	}
+	if spec.PodTemplate == nil || spec.PodTemplate.Resources == nil {
+		return 0
+	}
+	limit, ok := spec.PodTemplate.Resources.Limits[corev1.ResourceMemory]
+	if !ok {
+		return 0
+	}
+	return limit.Value() * maxMemoryLimitPercent / 100
+}
+
+// EvictionPolicy returns the maxmemory-policy of an instance, noeviction
+// when it is not set.
+func EvictionPolicy(spec *v1alpha1.RedisDatabaseSpec) v1alpha1.RedisEvictionPolicy {
+	if spec.EvictionPolicy == "" {
+		return v1alpha1.RedisEvictionNone
+	}
+	return spec.EvictionPolicy
+}
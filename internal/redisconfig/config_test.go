@@ -0,0 +1,107 @@
+package redisconfig_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/benjamin-wright/db-operator/internal/redisconfig"
+	v1alpha1 "github.com/benjamin-wright/db-operator/pkg/api/v1alpha1"
+)
+
+func TestBuild_DefaultPersistence(t *testing.T) {
+	got := redisconfig.Build(nil)
+	want := "dir /data\naclfile /data/users.acl\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuild_NoPersistence(t *testing.T) {
+	got := redisconfig.Build(&v1alpha1.RedisPersistenceSpec{})
+	want := "dir /data\naclfile /data/users.acl\nsave \"\"\nappendonly no\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuild_SavePoints(t *testing.T) {
+	got := redisconfig.Build(&v1alpha1.RedisPersistenceSpec{
+		RDB: &v1alpha1.RedisRDBSpec{SavePoints: []v1alpha1.RedisSavePoint{
+			{Seconds: 900, Changes: 1},
+			{Seconds: 60, Changes: 10000},
+		}},
+	})
+	want := "dir /data\naclfile /data/users.acl\nsave 900 1 60 10000\nappendonly no\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuild_AppendOnlyDefaultsToEverySec(t *testing.T) {
+	got := redisconfig.Build(&v1alpha1.RedisPersistenceSpec{
+		AOF: &v1alpha1.RedisAOFSpec{},
+	})
+	want := "dir /data\naclfile /data/users.acl\nsave \"\"\nappendonly yes\nappendfsync everysec\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuild_AppendOnlyFsyncPolicy(t *testing.T) {
+	got := redisconfig.Build(&v1alpha1.RedisPersistenceSpec{
+		AOF: &v1alpha1.RedisAOFSpec{Fsync: v1alpha1.RedisAppendFsyncAlways},
+	})
+	want := "dir /data\naclfile /data/users.acl\nsave \"\"\nappendonly yes\nappendfsync always\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMemory_Defaults(t *testing.T) {
+	got := redisconfig.Memory(&v1alpha1.RedisDatabaseSpec{})
+	want := "maxmemory 0\nmaxmemory-policy noeviction\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMemory_Explicit(t *testing.T) {
+	maxMemory := resource.MustParse("64Mi")
+	got := redisconfig.Memory(&v1alpha1.RedisDatabaseSpec{
+		MaxMemory:      &maxMemory,
+		EvictionPolicy: v1alpha1.RedisEvictionAllKeysLRU,
+	})
+	want := "maxmemory 67108864\nmaxmemory-policy allkeys-lru\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMaxMemory_DerivedFromLimit(t *testing.T) {
+	spec := &v1alpha1.RedisDatabaseSpec{
+		PodTemplate: &v1alpha1.PodTemplateOverrides{
+			Resources: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+			},
+		},
+	}
+	if got, want := redisconfig.MaxMemory(spec), int64(96*1024*1024); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+
+	maxMemory := resource.MustParse("32Mi")
+	spec.MaxMemory = &maxMemory
+	if got, want := redisconfig.MaxMemory(spec), int64(32*1024*1024); got != want {
+		t.Errorf("explicit maxMemory: got %d, want %d", got, want)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	got := redisconfig.Cluster()
+	want := "cluster-enabled yes\ncluster-config-file /data/nodes.conf\ncluster-node-timeout 5000\ncluster-preferred-endpoint-type hostname\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
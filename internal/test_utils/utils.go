@@ -6,6 +6,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -200,6 +201,102 @@ func ConnectToDatabaseNamed(dbLookup types.NamespacedName, secretLookup types.Na
 	}
 }
 
+// ConnectToDatabaseOrdinal opens a connection to a specific pod of the
+// PostgresDatabase StatefulSet, e.g. a standby at ordinal 1.
+func ConnectToDatabaseOrdinal(dbLookup types.NamespacedName, secretLookup types.NamespacedName, ordinal int) (*sql.DB, func()) {
+	var secret corev1.Secret
+	Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed(), "fetching credential secret")
+
+	username := string(secret.Data["PGUSER"])
+	Expect(username).NotTo(BeEmpty(), "PGUSER in secret should not be empty")
+
+	password := string(secret.Data["PGPASSWORD"])
+	Expect(password).NotTo(BeEmpty(), "PGPASSWORD in secret should not be empty")
+
+	pfwdClose, port := portForward(dbLookup.Namespace, fmt.Sprintf("%s-%d", dbLookup.Name, ordinal), 5432)
+
+	connStr := fmt.Sprintf("host=localhost port=%d user=%s password=%s dbname=postgres sslmode=disable",
+		port, username, password,
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	Expect(err).NotTo(HaveOccurred(), "opening database connection")
+
+	return db, func() {
+		db.Close()
+		pfwdClose()
+	}
+}
+
+// ConnectToPooler opens a connection to dbName through a ready pod of the
+// PostgresDatabase's PgBouncer pooler.
+func ConnectToPooler(dbLookup types.NamespacedName, secretLookup types.NamespacedName, dbName string) (*sql.DB, func()) {
+	var secret corev1.Secret
+	Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed(), "fetching credential secret")
+
+	var pods corev1.PodList
+	Expect(K8sClient.List(Ctx, &pods, client.InNamespace(dbLookup.Namespace), client.MatchingLabels{
+		"app.kubernetes.io/name":     "postgres-pooler",
+		"app.kubernetes.io/instance": dbLookup.Name,
+	})).To(Succeed(), "listing pooler pods")
+	podName := ""
+	for _, pod := range pods.Items {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue && pod.DeletionTimestamp == nil {
+				podName = pod.Name
+			}
+		}
+	}
+	Expect(podName).NotTo(BeEmpty(), "no ready pooler pod")
+
+	pfwdClose, port := portForward(dbLookup.Namespace, podName, 6432)
+
+	connStr := fmt.Sprintf("host=localhost port=%d user=%s password=%s dbname=%s sslmode=disable",
+		port, secret.Data["PGUSER"], secret.Data["PGPASSWORD"], dbName,
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	Expect(err).NotTo(HaveOccurred(), "opening database connection")
+
+	return db, func() {
+		db.Close()
+		pfwdClose()
+	}
+}
+
+// ScrapeMetrics returns the body of the /metrics page served on remotePort of
+// the named pod.
+func ScrapeMetrics(namespace, podName string, remotePort int) string {
+	pfwdClose, port := portForward(namespace, podName, remotePort)
+	defer pfwdClose()
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+	Expect(err).NotTo(HaveOccurred(), "scraping metrics")
+	defer resp.Body.Close()
+	Expect(resp.StatusCode).To(Equal(http.StatusOK), "metrics status")
+
+	body, err := io.ReadAll(resp.Body)
+	Expect(err).NotTo(HaveOccurred(), "reading metrics")
+	return string(body)
+}
+
+// ScrapeOperatorMetrics returns the /metrics page of the operator under test,
+// deployed by the Tiltfile into the db-operator namespace.
+func ScrapeOperatorMetrics() string {
+	var pods corev1.PodList
+	Expect(K8sClient.List(Ctx, &pods, client.InNamespace("db-operator"), client.MatchingLabels{
+		"app.kubernetes.io/name": "db-operator",
+	})).To(Succeed(), "listing operator pods")
+	podName := ""
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			podName = pod.Name
+		}
+	}
+	Expect(podName).NotTo(BeEmpty(), "no running operator pod")
+	return ScrapeMetrics("db-operator", podName, 8080)
+}
+
 func portForward(namespace, podName string, remotePort int) (func(), uint16) {
 	url := Clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
@@ -371,13 +468,19 @@ func ConnectToNats(clusterLookup types.NamespacedName, secretLookup types.Namesp
 // ConnectToRedisDatabase opens an authenticated Redis client by port-forwarding
 // to the Redis pod and reading credentials from the given Secret.
 func ConnectToRedisDatabase(dbLookup types.NamespacedName, secretLookup types.NamespacedName) (*goredis.Client, func()) {
+	return ConnectToRedisDatabaseOrdinal(dbLookup, secretLookup, 0)
+}
+
+// ConnectToRedisDatabaseOrdinal opens an authenticated Redis client to a
+// specific pod of the RedisDatabase StatefulSet, e.g. a replica at ordinal 1.
+func ConnectToRedisDatabaseOrdinal(dbLookup types.NamespacedName, secretLookup types.NamespacedName, ordinal int) (*goredis.Client, func()) {
 	var secret corev1.Secret
 	Expect(K8sClient.Get(Ctx, secretLookup, &secret)).To(Succeed(), "fetching admin secret")
 
 	username := string(secret.Data["REDIS_USERNAME"])
 	password := string(secret.Data["REDIS_PASSWORD"])
 
-	pfwdClose, port := portForward(dbLookup.Namespace, dbLookup.Name+"-0", 6379)
+	pfwdClose, port := portForward(dbLookup.Namespace, fmt.Sprintf("%s-%d", dbLookup.Name, ordinal), 6379)
 
 	rdb := goredis.NewClient(&goredis.Options{
 		Addr:     fmt.Sprintf("localhost:%d", port),
@@ -390,3 +493,21 @@ func ConnectToRedisDatabase(dbLookup types.NamespacedName, secretLookup types.Na
 		pfwdClose()
 	}
 }
+
+// ConnectToRedisSentinel port-forwards to the given Sentinel pod of a
+// RedisDatabase and logs in with username and password; an empty username
+// connects without authenticating.
+func ConnectToRedisSentinel(dbLookup types.NamespacedName, username, password string, ordinal int) (*goredis.SentinelClient, func()) {
+	pfwdClose, port := portForward(dbLookup.Namespace, fmt.Sprintf("%s-sentinel-%d", dbLookup.Name, ordinal), 26379)
+
+	sentinel := goredis.NewSentinelClient(&goredis.Options{
+		Addr:     fmt.Sprintf("localhost:%d", port),
+		Username: username,
+		Password: password,
+	})
+
+	return sentinel, func() {
+		_ = sentinel.Close()
+		pfwdClose()
+	}
+}
@@ -0,0 +1,20 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RotateAdminPasswordAnnotation requests an immediate admin password rotation
+// when set on a PostgresDatabase or RedisDatabase. Each new value triggers one
+// rotation; the value last acted on is recorded in
+// status.adminPasswordRotationRequest.
+const RotateAdminPasswordAnnotation = "db-operator.benjamin-wright.github.com/rotate-admin-password"
+
+// AdminPasswordRotation schedules rotation of a database's admin password.
+type AdminPasswordRotation struct {
+	// Interval is how long each admin password is used before it is replaced,
+	// as a duration such as "720h". It must be at least one hour.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1h')",message="interval must be at least 1h"
+	Interval metav1.Duration `json:"interval"`
+}
@@ -0,0 +1,39 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MetricsSpec runs a Prometheus exporter next to each database server and
+// exposes it on the instance's Service as a port named "metrics".
+type MetricsSpec struct {
+	// Image overrides the exporter image. When omitted, the operator's default
+	// exporter for the database kind is used.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Resources sets the compute resource requests and limits of the exporter
+	// container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// ServiceMonitor creates a Prometheus Operator ServiceMonitor that scrapes
+	// the metrics port. It is skipped while the ServiceMonitor CRD is not
+	// installed.
+	// +optional
+	ServiceMonitor *ServiceMonitorSpec `json:"serviceMonitor,omitempty"`
+}
+
+// ServiceMonitorSpec configures the ServiceMonitor created for an instance.
+type ServiceMonitorSpec struct {
+	// Interval is how often Prometheus scrapes the exporter, as a Prometheus
+	// duration such as "30s". When omitted, Prometheus's own default applies.
+	// +kubebuilder:validation:Pattern=`^(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?$`
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Labels are added to the ServiceMonitor, typically so that a Prometheus
+	// serviceMonitorSelector picks it up.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
@@ -20,11 +20,18 @@ const (
 
 // NatsJetStreamConfig configures JetStream persistence for a NatsCluster.
 // When present, a PersistentVolume is provisioned and JetStream is enabled.
+// +kubebuilder:validation:XValidation:rule="has(self.storageClassName) == has(oldSelf.storageClassName) && (!has(self.storageClassName) || self.storageClassName == oldSelf.storageClassName)",message="storageClassName is immutable"
 type NatsJetStreamConfig struct {
 	// StorageSize is the size of the PersistentVolume requested for JetStream storage
-	// (e.g. "1Gi", "10Gi").
+	// (e.g. "1Gi", "10Gi"). Increasing it expands the existing volume in place
+	// when its StorageClass allows volume expansion.
 	// +kubebuilder:validation:Required
 	StorageSize resource.Quantity `json:"storageSize"`
+
+	// StorageClassName is the StorageClass of the JetStream volume. When
+	// omitted, the cluster's default StorageClass is used.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
 }
 
 // NatsClusterSpec defines the desired state of NatsCluster.
@@ -38,6 +45,17 @@ type NatsClusterSpec struct {
 	// provisioned for storage. When omitted, JetStream is disabled.
 	// +optional
 	JetStream *NatsJetStreamConfig `json:"jetStream,omitempty"`
+
+	// PodTemplate customises the NATS server pod: resources, scheduling
+	// constraints, and extra labels and annotations.
+	// +optional
+	PodTemplate *PodTemplateOverrides `json:"podTemplate,omitempty"`
+
+	// Metrics runs prometheus-nats-exporter in the NATS pod, reading the
+	// server's monitoring endpoint, and exposes it on the Service's "metrics"
+	// port.
+	// +optional
+	Metrics *MetricsSpec `json:"metrics,omitempty"`
 }
 
 // NatsClusterStatus defines the observed state of NatsCluster.
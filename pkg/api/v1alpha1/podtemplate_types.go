@@ -0,0 +1,40 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodTemplateOverrides customises the pods the operator runs for a database.
+// Every field is optional; unset fields leave the operator's defaults in place.
+type PodTemplateOverrides struct {
+	// Labels are added to the pods. Labels the operator sets itself take
+	// precedence, since Services and selectors rely on them.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the pods. Annotations the operator sets itself
+	// take precedence.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Resources sets the compute resource requests and limits of the database
+	// container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector restricts the pods to nodes carrying these labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations let the pods schedule onto nodes with matching taints.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity sets node, pod affinity, and pod anti-affinity scheduling rules.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PriorityClassName is the PriorityClass assigned to the pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
@@ -18,7 +18,11 @@ const (
 )
 
 // DatabasePermission represents a PostgreSQL privilege that can be granted to a user.
-// +kubebuilder:validation:Enum=SELECT;INSERT;UPDATE;DELETE;TRUNCATE;REFERENCES;TRIGGER;ALL
+// Each privilege is granted on every kind of object in scope that accepts it:
+// USAGE and CREATE on schemas; SELECT through TRIGGER on tables; USAGE, SELECT
+// and UPDATE on sequences; EXECUTE on functions. ALL covers tables, sequences
+// and functions but not schemas, which take USAGE and CREATE explicitly.
+// +kubebuilder:validation:Enum=SELECT;INSERT;UPDATE;DELETE;TRUNCATE;REFERENCES;TRIGGER;USAGE;CREATE;EXECUTE;ALL
 type DatabasePermission string
 
 const (
@@ -29,30 +33,75 @@ const (
 	PermissionTruncate   DatabasePermission = "TRUNCATE"
 	PermissionReferences DatabasePermission = "REFERENCES"
 	PermissionTrigger    DatabasePermission = "TRIGGER"
+	PermissionUsage      DatabasePermission = "USAGE"
+	PermissionCreate     DatabasePermission = "CREATE"
+	PermissionExecute    DatabasePermission = "EXECUTE"
 	PermissionAll        DatabasePermission = "ALL"
 )
 
-// DatabasePermissionEntry maps a set of table-level privileges to one or more
-// logical databases within the target PostgreSQL instance.
+// DatabasePermissionEntry maps a set of privileges to one or more logical
+// databases within the target PostgreSQL instance.
+//
+// When tables, sequences or functions name specific objects, the entry covers
+// only those objects. Otherwise it covers every table, sequence and function
+// in its schemas, including ones created later.
 type DatabasePermissionEntry struct {
 	// Databases is the list of PostgreSQL database names this entry applies to.
 	// Each database will be created inside the target instance if it does not already exist.
 	// +kubebuilder:validation:MinItems=1
 	Databases []string `json:"databases"`
 
-	// Tables is the list of table names within those databases that these privileges apply to.
-	// If empty or omitted, the privileges apply to all tables in those databases.
+	// Schemas is the list of schemas within those databases that this entry
+	// applies to. Each schema must already exist. Defaults to public.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	Schemas []string `json:"schemas,omitempty"`
+
+	// Tables is the list of table names within those schemas that these privileges apply to.
 	// +optional
 	// +kubebuilder:validation:MinItems=1
 	Tables []string `json:"tables,omitempty"`
 
-	// Permissions is the set of table-level privileges to grant in those databases.
+	// Sequences is the list of sequence names within those schemas that these
+	// privileges apply to.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	Sequences []string `json:"sequences,omitempty"`
+
+	// Functions is the list of function names within those schemas that these
+	// privileges apply to. Each name must identify a single function; overloaded
+	// functions are rejected by PostgreSQL.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	Functions []string `json:"functions,omitempty"`
+
+	// Permissions is the set of privileges to grant in those databases.
 	// +kubebuilder:validation:MinItems=1
 	Permissions []DatabasePermission `json:"permissions"`
 }
 
+// CredentialRotation configures automatic password rotation for a credential.
+// +kubebuilder:validation:XValidation:rule="!has(self.gracePeriod) || duration(self.gracePeriod) < duration(self.interval)",message="gracePeriod must be shorter than interval"
+type CredentialRotation struct {
+	// Interval is how long each password is used before it is replaced, as a
+	// duration such as "720h". It must be at least one minute.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="interval must be at least 1m"
+	Interval metav1.Duration `json:"interval"`
+
+	// GracePeriod enables dual-credential rotation: the previous password keeps
+	// working for this long after each rotation, so pods still running with the
+	// old Secret contents can authenticate until they roll. Rotations then
+	// alternate between the role named by username and a second login role,
+	// <username>_alt, which acts as username once connected. When omitted, the
+	// previous password stops working as soon as the Secret is updated.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
 // PostgresCredentialSpec defines the desired state of PostgresCredential.
 // +kubebuilder:validation:XValidation:rule="!self.databaseOwner || size(self.permissions) > 0",message="databaseOwner: true requires at least one permissions entry"
+// +kubebuilder:validation:XValidation:rule="!has(self.rotation) || !has(self.rotation.gracePeriod) || size(self.username) <= 59",message="dual-credential rotation needs a username of at most 59 characters"
 type PostgresCredentialSpec struct {
 	// DatabaseRef is the name of the PostgresDatabase resource in the same namespace
 	// that this credential targets.
@@ -92,6 +141,53 @@ type PostgresCredentialSpec struct {
 	// the owner are auto-granted to those credentials.
 	// +optional
 	DatabaseOwner bool `json:"databaseOwner,omitempty"`
+
+	// MemberOf is the list of PostgresRole resources in the same namespace whose
+	// group roles this credential's role is a member of, inheriting their
+	// privileges. Each must target the same databaseRef. Memberships not listed
+	// here are revoked.
+	// +optional
+	// +listType=set
+	MemberOf []string `json:"memberOf,omitempty"`
+
+	// ConnectionLimit caps how many concurrent connections the role may hold,
+	// so one service cannot exhaust max_connections on a shared instance. When
+	// omitted the role has no limit of its own.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ConnectionLimit *int32 `json:"connectionLimit,omitempty"`
+
+	// ValidUntil is when the role's password stops being accepted. When
+	// omitted the password never expires.
+	// +optional
+	ValidUntil *metav1.Time `json:"validUntil,omitempty"`
+
+	// StatementTimeout aborts any statement the role runs for longer than this
+	// duration, such as "30s". Zero disables the timeout; when omitted the
+	// server default applies.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="statementTimeout must not be negative"
+	StatementTimeout *metav1.Duration `json:"statementTimeout,omitempty"`
+
+	// IdleInTransactionSessionTimeout ends any session of the role that stays
+	// idle inside an open transaction for longer than this duration. Zero
+	// disables the timeout; when omitted the server default applies.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="idleInTransactionSessionTimeout must not be negative"
+	IdleInTransactionSessionTimeout *metav1.Duration `json:"idleInTransactionSessionTimeout,omitempty"`
+
+	// SearchPath is the list of schemas the role's sessions search for
+	// unqualified names, in order. When omitted the server default applies.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:MinLength=1
+	SearchPath []string `json:"searchPath,omitempty"`
+
+	// Rotation replaces the generated password on a schedule. The new password
+	// is set in PostgreSQL first, then written to the Secret in a single update.
+	// When omitted, the password never changes.
+	// +optional
+	Rotation *CredentialRotation `json:"rotation,omitempty"`
 }
 
 // PostgresCredentialStatus defines the observed state of PostgresCredential.
@@ -109,6 +205,25 @@ type PostgresCredentialStatus struct {
 	// SecretName is the name of the Kubernetes Secret that was created for this credential.
 	// +optional
 	SecretName string `json:"secretName,omitempty"`
+
+	// LastRotated is when the password in the Secret was last rotated.
+	// +optional
+	LastRotated *metav1.Time `json:"lastRotated,omitempty"`
+
+	// ActiveUsername is the login role whose password the Secret currently
+	// holds: spec.username, or <username>_alt during dual-credential rotation.
+	// Empty means spec.username.
+	// +optional
+	ActiveUsername string `json:"activeUsername,omitempty"`
+
+	// PreviousUsername is the login role that held the Secret before the last
+	// dual-credential rotation. Its password stays valid until GraceExpiresAt.
+	// +optional
+	PreviousUsername string `json:"previousUsername,omitempty"`
+
+	// GraceExpiresAt is when the password of PreviousUsername is revoked.
+	// +optional
+	GraceExpiresAt *metav1.Time `json:"graceExpiresAt,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -6,12 +6,15 @@ import (
 )
 
 // DatabasePhase represents the current lifecycle phase of a PostgresDatabase.
-// +kubebuilder:validation:Enum=Pending;Ready;Failed
+// +kubebuilder:validation:Enum=Pending;Upgrading;Ready;Failed
 type DatabasePhase string
 
 const (
 	// DatabasePhasePending means the database is being provisioned.
 	DatabasePhasePending DatabasePhase = "Pending"
+	// DatabasePhaseUpgrading means the data directory is being upgraded to a
+	// new major version and the database is offline.
+	DatabasePhaseUpgrading DatabasePhase = "Upgrading"
 	// DatabasePhaseReady means the database is running and accepting connections.
 	DatabasePhaseReady DatabasePhase = "Ready"
 	// DatabasePhaseFailed means the database provisioning or reconciliation failed.
@@ -19,16 +22,329 @@ const (
 )
 
 // PostgresDatabaseSpec defines the desired state of PostgresDatabase.
+// +kubebuilder:validation:XValidation:rule="has(self.storageClassName) == has(oldSelf.storageClassName) && (!has(self.storageClassName) || self.storageClassName == oldSelf.storageClassName)",message="storageClassName is immutable"
 type PostgresDatabaseSpec struct {
 	// PostgresVersion is the major version of PostgreSQL to deploy (e.g. "16").
+	// Raising it upgrades the existing data directory with pg_upgrade; lowering
+	// it below the version of the data directory is refused.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Enum="14";"15";"16";"17"
 	PostgresVersion string `json:"postgresVersion"`
 
-	// StorageSize is the size of the PersistentVolume requested for this instance
-	// (e.g. "1Gi", "10Gi").
+	// StorageSize is the size of the PersistentVolume requested for each pod
+	// (e.g. "1Gi", "10Gi"). Increasing it expands the existing volumes in place
+	// when their StorageClass allows volume expansion, and otherwise leaves
+	// them at their current size. Decreasing it recreates the volumes and
+	// loses their data.
 	// +kubebuilder:validation:Required
 	StorageSize resource.Quantity `json:"storageSize"`
+
+	// StorageClassName is the StorageClass of the instance's volumes. When
+	// omitted, the cluster's default StorageClass is used. It cannot be
+	// changed after creation.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Replicas is the total number of PostgreSQL pods. One pod (initially
+	// ordinal 0) is the primary; every other pod is a hot standby that streams
+	// WAL from it and serves read-only queries through the "<name>-ro" Service.
+	// With more than one replica, a standby is promoted automatically when the
+	// primary stays unready.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=9
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Parameters sets postgresql.conf parameters by name (e.g. "work_mem": "16MB").
+	// Changes to parameters PostgreSQL can reload are applied to running pods;
+	// changes to parameters that need a restart roll the pods. Connection,
+	// file location, and TLS enablement settings are managed by the operator
+	// and cannot be set.
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[a-z][a-z0-9_.]*$'))",message="parameter names must be lower-case PostgreSQL setting names"
+	// +kubebuilder:validation:XValidation:rule="self.all(k, !(k in ['config_file', 'data_directory', 'hba_file', 'ident_file', 'external_pid_file', 'listen_addresses', 'port', 'include', 'include_dir', 'include_if_exists', 'ssl', 'ssl_cert_file', 'ssl_key_file', 'ssl_ca_file', 'ssl_crl_file', 'ssl_crl_dir']))",message="parameter is managed by the operator"
+	// +kubebuilder:validation:XValidation:rule="self.all(k, !self[k].contains('\\n'))",message="parameter values must be a single line"
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// PodTemplate customises the PostgreSQL pods: resources, scheduling
+	// constraints, and extra labels and annotations.
+	// +optional
+	PodTemplate *PodTemplateOverrides `json:"podTemplate,omitempty"`
+
+	// TLS encrypts client and replication connections to the instance. When
+	// set, connections from outside the pod must use TLS, and every
+	// PostgresCredential Secret carries the CA bundle to verify the server.
+	// +optional
+	TLS *PostgresTLSSpec `json:"tls,omitempty"`
+
+	// Backup enables scheduled pg_dumpall backups of the instance. When omitted,
+	// no backups are taken.
+	// +optional
+	Backup *PostgresBackupSpec `json:"backup,omitempty"`
+
+	// AdminPasswordRotation replaces the postgres superuser password on a
+	// schedule. The RotateAdminPasswordAnnotation triggers a rotation on demand
+	// whether or not this is set.
+	// +optional
+	AdminPasswordRotation *AdminPasswordRotation `json:"adminPasswordRotation,omitempty"`
+
+	// Databases declares logical databases inside the instance and the
+	// extensions installed in each. Listed databases are created if missing;
+	// databases named only by PostgresCredentials are created as before.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Databases []PostgresLogicalDatabase `json:"databases,omitempty"`
+
+	// DropUnreferencedDatabases, when set, drops logical databases that no
+	// PostgresCredential or PostgresRole names and spec.databases does not
+	// list, once they have stayed that way for the grace period. Only
+	// databases the operator created are dropped; ones created outside it are
+	// left alone. When omitted, databases are never dropped.
+	// +optional
+	DropUnreferencedDatabases *DropUnreferencedDatabases `json:"dropUnreferencedDatabases,omitempty"`
+
+	// Failover tunes automatic failover, which promotes a standby once the
+	// primary pod has been unready, and unreachable from the operator, for the
+	// grace period. It only applies with two or more replicas.
+	// +optional
+	Failover *PostgresFailoverSpec `json:"failover,omitempty"`
+
+	// Pooler deploys PgBouncer in front of the primary, so clients that open
+	// many short-lived connections share a small pool of server connections.
+	// Credential Secrets gain PGBOUNCER_HOST and PGBOUNCER_PORT keys while it
+	// is set.
+	// +optional
+	Pooler *PostgresPoolerSpec `json:"pooler,omitempty"`
+
+	// Metrics runs postgres_exporter in every PostgreSQL pod and exposes it
+	// on the headless Service's "metrics" port.
+	// +optional
+	Metrics *MetricsSpec `json:"metrics,omitempty"`
+}
+
+// PostgresPoolerMode is when PgBouncer returns a server connection to the pool.
+// +kubebuilder:validation:Enum=transaction;session
+type PostgresPoolerMode string
+
+const (
+	// PostgresPoolerModeTransaction releases the server connection at the end
+	// of each transaction. Session state such as prepared statements, SET and
+	// advisory locks does not carry over between transactions.
+	PostgresPoolerModeTransaction PostgresPoolerMode = "transaction"
+	// PostgresPoolerModeSession holds the server connection until the client
+	// disconnects.
+	PostgresPoolerModeSession PostgresPoolerMode = "session"
+)
+
+// PostgresPoolerSpec configures the PgBouncer pooler of a PostgresDatabase.
+type PostgresPoolerSpec struct {
+	// Mode is the PgBouncer pool_mode.
+	// +kubebuilder:default=transaction
+	// +optional
+	Mode PostgresPoolerMode `json:"mode,omitempty"`
+
+	// PoolSize is the number of server connections PgBouncer keeps for each
+	// user and database pair.
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PoolSize int32 `json:"poolSize,omitempty"`
+}
+
+// PostgresFailoverSpec configures automatic failover.
+type PostgresFailoverSpec struct {
+	// GracePeriod is how long the primary pod may stay unready before a
+	// standby is promoted, as a duration such as "30s". It should comfortably
+	// cover an ordinary pod restart. Defaults to 30s.
+	// +kubebuilder:default="30s"
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('5s')",message="gracePeriod must be at least 5s"
+	GracePeriod metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// DropUnreferencedDatabases configures when unreferenced logical databases are
+// dropped.
+type DropUnreferencedDatabases struct {
+	// GracePeriod is how long a database must stay unreferenced before it is
+	// dropped, as a duration such as "24h", so a credential that is deleted and
+	// recreated does not lose its data. Defaults to 24h.
+	// +kubebuilder:default="24h"
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="gracePeriod must not be negative"
+	GracePeriod metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// PostgresLogicalDatabase declares one logical database of a PostgresDatabase.
+type PostgresLogicalDatabase struct {
+	// Name is the name of the logical database.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Extensions are installed in the database with CREATE EXTENSION as the
+	// superuser, so extensions that need one can be used by any credential.
+	// Removing an entry leaves the extension installed.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Extensions []PostgresExtension `json:"extensions,omitempty"`
+}
+
+// PostgresExtension names an extension to install in a logical database.
+type PostgresExtension struct {
+	// Name is the extension name, such as "pgcrypto" or "pg_trgm". It must be
+	// available in the PostgreSQL image.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Version pins the extension version. An installed extension at another
+	// version is updated to it with ALTER EXTENSION ... UPDATE. When omitted,
+	// the image's default version is installed and never updated.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// PostgresTLSSpec configures where the server certificate of a
+// PostgresDatabase comes from. An empty block uses an operator-generated
+// self-signed CA.
+type PostgresTLSSpec struct {
+	// IssuerRef names a cert-manager issuer that signs the server certificate
+	// through a Certificate the operator creates. The issuer must publish its
+	// CA in the certificate Secret's ca.crt key, as CA and Vault issuers do.
+	// When omitted, the operator generates a CA for the instance and signs the
+	// certificate itself.
+	// +optional
+	IssuerRef *CertManagerIssuerRef `json:"issuerRef,omitempty"`
+}
+
+// CertManagerIssuerRef references a cert-manager Issuer or ClusterIssuer.
+type CertManagerIssuerRef struct {
+	// Name is the name of the issuer.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind is the kind of the issuer: Issuer (in the same namespace) or
+	// ClusterIssuer, or the kind of an external issuer.
+	// +kubebuilder:default=Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group is the API group of the issuer. Set it for external issuers.
+	// +kubebuilder:default="cert-manager.io"
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// PostgresBackupSpec configures scheduled logical backups for a PostgresDatabase.
+// +kubebuilder:validation:XValidation:rule="has(self.pvc) != has(self.s3)",message="exactly one of pvc or s3 must be set"
+type PostgresBackupSpec struct {
+	// Schedule is the cron expression on which backups run (e.g. "0 3 * * *").
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Retention is the number of backup artifacts to keep at the destination.
+	// Older artifacts are pruned after each successful backup.
+	// +kubebuilder:default=7
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention int32 `json:"retention,omitempty"`
+
+	// PVC writes backup artifacts to an existing PersistentVolumeClaim in the
+	// same namespace.
+	// +optional
+	PVC *PostgresBackupPVC `json:"pvc,omitempty"`
+
+	// S3 uploads backup artifacts to an S3-compatible object store.
+	// +optional
+	S3 *PostgresBackupS3 `json:"s3,omitempty"`
+}
+
+// PostgresBackupPVC is a PersistentVolumeClaim backup destination. Artifacts are
+// written under a directory named after the PostgresDatabase.
+type PostgresBackupPVC struct {
+	// ClaimName is the name of an existing PersistentVolumeClaim in the same namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+}
+
+// PostgresBackupS3 is an S3-compatible object store backup destination.
+type PostgresBackupS3 struct {
+	// Endpoint is the URL of the S3-compatible API (e.g. "https://minio.example.com:9000").
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the name of the bucket artifacts are uploaded to.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Prefix is prepended to every object key. Defaults to the PostgresDatabase name.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region is the bucket region passed to the S3 client.
+	// +kubebuilder:default="us-east-1"
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecret is the name of a Secret in the same namespace holding
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// BackupResult is the outcome of a single backup run.
+// +kubebuilder:validation:Enum=Succeeded;Failed
+type BackupResult string
+
+const (
+	// BackupResultSucceeded means the backup Job completed and the artifact was written.
+	BackupResultSucceeded BackupResult = "Succeeded"
+	// BackupResultFailed means the backup Job exhausted its retries.
+	BackupResultFailed BackupResult = "Failed"
+)
+
+// PostgresBackupRecord describes one completed backup run.
+type PostgresBackupRecord struct {
+	// JobName is the name of the Job that produced the backup.
+	JobName string `json:"jobName"`
+
+	// Artifact is the artifact path relative to the destination: the PVC root
+	// for pvc destinations, or the object key for s3 destinations.
+	// +optional
+	Artifact string `json:"artifact,omitempty"`
+
+	// Result is whether the backup succeeded.
+	Result BackupResult `json:"result"`
+
+	// StartTime is when the backup Job started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the backup Job finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// PostgresFencingStatus identifies the former primary pod a failover is
+// waiting on.
+type PostgresFencingStatus struct {
+	// Ordinal is the StatefulSet ordinal of the former primary.
+	Ordinal int32 `json:"ordinal"`
+
+	// PodUID is the UID of the former primary pod. A pod with the same name
+	// but another UID is its replacement, which starts as a standby.
+	PodUID string `json:"podUID"`
 }
 
 // PostgresDatabaseStatus defines the observed state of PostgresDatabase.
@@ -43,17 +359,98 @@ type PostgresDatabaseStatus struct {
 	SecretName string `json:"secretName,omitempty"`
 
 	// Conditions contains detailed status conditions for the PostgresDatabase.
+	// A "Failover" condition records the most recent automatic promotion, or
+	// the fencing of the primary that precedes one, and
+	// "ParametersApplied" reports whether running pods use spec.parameters,
+	// and "ExtensionsReady" whether spec.databases extensions are installed.
 	// +listType=map
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// PostgresVersion is the major version of the data directory currently
+	// deployed. It trails spec.postgresVersion until an upgrade completes.
+	// +optional
+	PostgresVersion string `json:"postgresVersion,omitempty"`
+
+	// PrimaryOrdinal is the StatefulSet ordinal of the pod currently serving as
+	// the primary. It changes only when a failover promotes a standby.
+	// +optional
+	PrimaryOrdinal int32 `json:"primaryOrdinal,omitempty"`
+
+	// Fencing is set while a failover waits for the former primary pod to stop
+	// before it promotes the standby at PrimaryOrdinal.
+	// +optional
+	Fencing *PostgresFencingStatus `json:"fencing,omitempty"`
+
+	// BackupHistory lists the most recent completed backup runs, newest first.
+	// At most spec.backup.retention entries are kept.
+	// +optional
+	BackupHistory []PostgresBackupRecord `json:"backupHistory,omitempty"`
+
+	// AdminPasswordRotatedAt is when the admin password was last rotated.
+	// +optional
+	AdminPasswordRotatedAt *metav1.Time `json:"adminPasswordRotatedAt,omitempty"`
+
+	// AdminPasswordRotationRequest is the value of the
+	// RotateAdminPasswordAnnotation that the last on-demand rotation acted on.
+	// +optional
+	AdminPasswordRotationRequest string `json:"adminPasswordRotationRequest,omitempty"`
+
+	// Databases lists every logical database in the instance apart from the
+	// postgres maintenance database and templates.
+	// +optional
+	Databases []PostgresLogicalDatabaseStatus `json:"databases,omitempty"`
+}
+
+// PostgresLogicalDatabaseStatus is the observed state of a logical database.
+type PostgresLogicalDatabaseStatus struct {
+	// Name is the name of the logical database.
+	Name string `json:"name"`
+
+	// Owner is the role that owns the database.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// Size is the disk space the database uses.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Credentials lists the PostgresCredentials whose permissions name the
+	// database.
+	// +optional
+	Credentials []string `json:"credentials,omitempty"`
+
+	// UnreferencedSince is when the database was first seen without any
+	// PostgresCredential, PostgresRole or spec.databases entry naming it. With
+	// spec.dropUnreferencedDatabases set it is dropped once the grace period
+	// has passed since then.
+	// +optional
+	UnreferencedSince *metav1.Time `json:"unreferencedSince,omitempty"`
+
+	// Extensions lists every extension installed in the database, including
+	// ones not declared in spec.databases.
+	// +optional
+	Extensions []PostgresExtensionStatus `json:"extensions,omitempty"`
+}
+
+// PostgresExtensionStatus is an extension installed in a logical database.
+type PostgresExtensionStatus struct {
+	// Name is the extension name.
+	Name string `json:"name"`
+
+	// Version is the installed version.
+	Version string `json:"version"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=pgdb,categories=games-hub
 // +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.postgresVersion`
+// +kubebuilder:printcolumn:name="Running",type=string,JSONPath=`.status.postgresVersion`,priority=1
 // +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.storageSize`
+// +kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.spec.replicas`
+// +kubebuilder:printcolumn:name="Primary",type=integer,JSONPath=`.status.primaryOrdinal`,priority=1
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
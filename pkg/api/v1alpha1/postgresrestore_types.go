@@ -0,0 +1,146 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestorePhase represents the current lifecycle phase of a PostgresRestore.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type RestorePhase string
+
+const (
+	// RestorePhasePending means the restore Job has not started running yet.
+	RestorePhasePending RestorePhase = "Pending"
+	// RestorePhaseRunning means the restore Job is replaying the backup.
+	RestorePhaseRunning RestorePhase = "Running"
+	// RestorePhaseSucceeded means the backup was replayed into the database.
+	RestorePhaseSucceeded RestorePhase = "Succeeded"
+	// RestorePhaseFailed means the restore Job exhausted its retries.
+	RestorePhaseFailed RestorePhase = "Failed"
+)
+
+// PostgresRestorePVCSource locates a backup artifact on a PersistentVolumeClaim.
+type PostgresRestorePVCSource struct {
+	// ClaimName is the name of an existing PersistentVolumeClaim in the same namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// Path is the artifact path relative to the claim root, as reported in
+	// PostgresDatabase status.backupHistory[*].artifact (e.g. "my-postgres/my-postgres-backup-29000000.sql.gz").
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+}
+
+// PostgresRestoreS3Source locates a backup artifact in an S3-compatible object store.
+type PostgresRestoreS3Source struct {
+	// Endpoint is the URL of the S3-compatible API (e.g. "https://minio.example.com:9000").
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the name of the bucket holding the artifact.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Key is the object key of the artifact, as reported in PostgresDatabase
+	// status.backupHistory[*].artifact.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// Region is the bucket region passed to the S3 client.
+	// +kubebuilder:default="us-east-1"
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecret is the name of a Secret in the same namespace holding
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// PostgresRestoreSource identifies the backup artifact to restore. Exactly one
+// of pvc or s3 must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.pvc) != has(self.s3)",message="exactly one of pvc or s3 must be set"
+type PostgresRestoreSource struct {
+	// PVC reads the artifact from a PersistentVolumeClaim.
+	// +optional
+	PVC *PostgresRestorePVCSource `json:"pvc,omitempty"`
+
+	// S3 downloads the artifact from an S3-compatible object store.
+	// +optional
+	S3 *PostgresRestoreS3Source `json:"s3,omitempty"`
+}
+
+// PostgresRestoreSpec defines the desired state of PostgresRestore.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable; create a new PostgresRestore instead"
+type PostgresRestoreSpec struct {
+	// DatabaseRef is the name of the PostgresDatabase resource in the same namespace
+	// to restore into.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	DatabaseRef string `json:"databaseRef"`
+
+	// Source is the backup artifact to replay.
+	// +kubebuilder:validation:Required
+	Source PostgresRestoreSource `json:"source"`
+}
+
+// PostgresRestoreStatus defines the observed state of PostgresRestore.
+type PostgresRestoreStatus struct {
+	// Phase is the current lifecycle phase of the restore.
+	// +kubebuilder:default=Pending
+	Phase RestorePhase `json:"phase,omitempty"`
+
+	// JobName is the name of the Job running the restore.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// StartTime is when the restore Job started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the restore Job finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Conditions contains detailed status conditions for the PostgresRestore.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=pgrestore,categories=games-hub
+// +kubebuilder:printcolumn:name="Database",type=string,JSONPath=`.spec.databaseRef`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PostgresRestore is the Schema for the postgresrestores API.
+// It replays a backup artifact into a PostgresDatabase once.
+type PostgresRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresRestoreSpec   `json:"spec,omitempty"`
+	Status PostgresRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PostgresRestoreList contains a list of PostgresRestore.
+type PostgresRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresRestore{}, &PostgresRestoreList{})
+}
@@ -0,0 +1,87 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresRolePhase represents the current lifecycle phase of a PostgresRole.
+// +kubebuilder:validation:Enum=Pending;Ready;Failed
+type PostgresRolePhase string
+
+const (
+	// PostgresRolePhasePending means the role is being provisioned.
+	PostgresRolePhasePending PostgresRolePhase = "Pending"
+	// PostgresRolePhaseReady means the role exists and holds its permissions.
+	PostgresRolePhaseReady PostgresRolePhase = "Ready"
+	// PostgresRolePhaseFailed means the role could not be provisioned.
+	PostgresRolePhaseFailed PostgresRolePhase = "Failed"
+)
+
+// PostgresRoleSpec defines the desired state of PostgresRole.
+type PostgresRoleSpec struct {
+	// DatabaseRef is the name of the PostgresDatabase resource in the same namespace
+	// that this role targets.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	DatabaseRef string `json:"databaseRef"`
+
+	// RoleName is the PostgreSQL group role to create inside the target database.
+	// The role cannot log in; credentials gain its privileges by listing this
+	// PostgresRole in spec.memberOf. Must be 1–63 characters, and cannot be
+	// changed once set.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="roleName is immutable"
+	RoleName string `json:"roleName"`
+
+	// Permissions is the list of per-database privilege entries for this role,
+	// with the same meaning as on a PostgresCredential.
+	// +optional
+	Permissions []DatabasePermissionEntry `json:"permissions,omitempty"`
+}
+
+// PostgresRoleStatus defines the observed state of PostgresRole.
+type PostgresRoleStatus struct {
+	// Phase is the current lifecycle phase of the role.
+	// +kubebuilder:default=Pending
+	Phase PostgresRolePhase `json:"phase,omitempty"`
+
+	// Conditions contains detailed status conditions for the PostgresRole.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=pgrole,categories=games-hub
+// +kubebuilder:printcolumn:name="Database",type=string,JSONPath=`.spec.databaseRef`
+// +kubebuilder:printcolumn:name="Role",type=string,JSONPath=`.spec.roleName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PostgresRole is the Schema for the postgresroles API.
+// It defines a PostgreSQL group role that shares a set of permissions with
+// every PostgresCredential that is a member of it.
+type PostgresRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresRoleSpec   `json:"spec,omitempty"`
+	Status PostgresRoleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PostgresRoleList contains a list of PostgresRole.
+type PostgresRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresRole{}, &PostgresRoleList{})
+}
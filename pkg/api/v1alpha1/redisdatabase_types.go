@@ -18,12 +18,200 @@ const (
 	RedisDatabasePhaseFailed RedisDatabasePhase = "Failed"
 )
 
+// RedisAppendFsync is how often Redis flushes the append-only file to disk.
+// +kubebuilder:validation:Enum=always;everysec;no
+type RedisAppendFsync string
+
+const (
+	// RedisAppendFsyncAlways flushes after every write: slowest, safest.
+	RedisAppendFsyncAlways RedisAppendFsync = "always"
+	// RedisAppendFsyncEverySec flushes once a second, so at most a second of
+	// writes is lost on a crash.
+	RedisAppendFsyncEverySec RedisAppendFsync = "everysec"
+	// RedisAppendFsyncNo leaves flushing to the operating system.
+	RedisAppendFsyncNo RedisAppendFsync = "no"
+)
+
+// RedisPersistenceSpec configures how a RedisDatabase persists data to its
+// volume. Setting neither rdb nor aof persists nothing, for a pure cache that
+// starts empty after every restart.
+type RedisPersistenceSpec struct {
+	// RDB writes point-in-time snapshots of the dataset.
+	// +optional
+	RDB *RedisRDBSpec `json:"rdb,omitempty"`
+
+	// AOF logs every write to an append-only file that is replayed on start.
+	// +optional
+	AOF *RedisAOFSpec `json:"aof,omitempty"`
+}
+
+// RedisRDBSpec configures RDB snapshots.
+type RedisRDBSpec struct {
+	// SavePoints trigger a snapshot whenever any one of them is met.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	SavePoints []RedisSavePoint `json:"savePoints"`
+}
+
+// RedisSavePoint triggers an RDB snapshot once at least Changes writes have
+// happened within Seconds.
+type RedisSavePoint struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	Seconds int32 `json:"seconds"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	Changes int32 `json:"changes"`
+}
+
+// RedisAOFSpec configures the append-only file.
+type RedisAOFSpec struct {
+	// Fsync is the appendfsync policy.
+	// +kubebuilder:default=everysec
+	// +optional
+	Fsync RedisAppendFsync `json:"fsync,omitempty"`
+}
+
+// RedisEvictionPolicy is how Redis picks keys to evict once it reaches
+// maxmemory.
+// +kubebuilder:validation:Enum=noeviction;allkeys-lru;allkeys-lfu;allkeys-random;volatile-lru;volatile-lfu;volatile-random;volatile-ttl
+type RedisEvictionPolicy string
+
+const (
+	// RedisEvictionNone rejects writes that need more memory.
+	RedisEvictionNone RedisEvictionPolicy = "noeviction"
+	// RedisEvictionAllKeysLRU evicts the least recently used keys.
+	RedisEvictionAllKeysLRU RedisEvictionPolicy = "allkeys-lru"
+	// RedisEvictionAllKeysLFU evicts the least frequently used keys.
+	RedisEvictionAllKeysLFU RedisEvictionPolicy = "allkeys-lfu"
+	// RedisEvictionAllKeysRandom evicts random keys.
+	RedisEvictionAllKeysRandom RedisEvictionPolicy = "allkeys-random"
+	// RedisEvictionVolatileLRU evicts the least recently used keys that have
+	// an expiry set.
+	RedisEvictionVolatileLRU RedisEvictionPolicy = "volatile-lru"
+	// RedisEvictionVolatileLFU evicts the least frequently used keys that have
+	// an expiry set.
+	RedisEvictionVolatileLFU RedisEvictionPolicy = "volatile-lfu"
+	// RedisEvictionVolatileRandom evicts random keys that have an expiry set.
+	RedisEvictionVolatileRandom RedisEvictionPolicy = "volatile-random"
+	// RedisEvictionVolatileTTL evicts the keys closest to expiring.
+	RedisEvictionVolatileTTL RedisEvictionPolicy = "volatile-ttl"
+)
+
+// RedisMode is how a RedisDatabase lays out its data over its pods.
+// +kubebuilder:validation:Enum=standalone;cluster
+type RedisMode string
+
+const (
+	// RedisModeStandalone keeps the whole dataset on one master, optionally
+	// with replicas and Sentinel.
+	RedisModeStandalone RedisMode = "standalone"
+	// RedisModeCluster runs Redis Cluster, spreading the hash slots over
+	// several masters (shards), each with its own replicas.
+	RedisModeCluster RedisMode = "cluster"
+)
+
+// RedisClusterSpec sizes a RedisDatabase in cluster mode. Both fields can
+// only grow: the operator adds the new pods to the cluster and moves hash
+// slots onto new shards, but does not drain shards or replicas away.
+type RedisClusterSpec struct {
+	// Shards is the number of masters the hash slots are divided between.
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=3
+	// +kubebuilder:validation:Maximum=16
+	// +kubebuilder:validation:XValidation:rule="self >= oldSelf",message="shards cannot be reduced"
+	// +optional
+	Shards int32 `json:"shards,omitempty"`
+
+	// ReplicasPerShard is the number of replicas that follow each master and
+	// take over when it fails.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=2
+	// +kubebuilder:validation:XValidation:rule="self >= oldSelf",message="replicasPerShard cannot be reduced"
+	// +optional
+	ReplicasPerShard *int32 `json:"replicasPerShard,omitempty"`
+}
+
 // RedisDatabaseSpec defines the desired state of RedisDatabase.
+// +kubebuilder:validation:XValidation:rule="!has(self.cluster) || (has(self.mode) && self.mode == 'cluster')",message="cluster requires mode: cluster"
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.cluster) || has(self.cluster)",message="cluster cannot be removed"
+// +kubebuilder:validation:XValidation:rule="has(self.storageClassName) == has(oldSelf.storageClassName) && (!has(self.storageClassName) || self.storageClassName == oldSelf.storageClassName)",message="storageClassName is immutable"
+// +kubebuilder:validation:XValidation:rule="!has(self.mode) || self.mode != 'cluster' || ((!has(self.sentinel) || !self.sentinel) && (!has(self.replicas) || self.replicas == 1))",message="mode: cluster sizes itself with cluster.shards and cluster.replicasPerShard, and cannot use sentinel or replicas"
 type RedisDatabaseSpec struct {
 	// StorageSize is the size of the PersistentVolume requested for this instance
-	// (e.g. "1Gi", "10Gi").
+	// (e.g. "1Gi", "10Gi"). Increasing it expands the existing volume in place
+	// when its StorageClass allows volume expansion, and otherwise leaves it at
+	// its current size. Decreasing it recreates the volume and loses its data.
 	// +kubebuilder:validation:Required
 	StorageSize resource.Quantity `json:"storageSize"`
+
+	// StorageClassName is the StorageClass of the instance's volume. When
+	// omitted, the cluster's default StorageClass is used. It cannot be changed
+	// after creation.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Mode is standalone or cluster. It cannot be changed after creation.
+	// +kubebuilder:default=standalone
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="mode is immutable"
+	// +optional
+	Mode RedisMode `json:"mode,omitempty"`
+
+	// Cluster sizes the database in cluster mode. When omitted in cluster
+	// mode, it defaults to three shards with one replica each.
+	// +optional
+	Cluster *RedisClusterSpec `json:"cluster,omitempty"`
+
+	// Replicas is the total number of Redis pods. One pod (initially ordinal
+	// 0) is the master; every other pod replicates from it and serves reads.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=9
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Sentinel runs three Redis Sentinel pods that monitor the master and
+	// promote a replica when it fails. Without Sentinel the master is always
+	// ordinal 0 and a failed master is only replaced by its pod restarting.
+	// +optional
+	Sentinel bool `json:"sentinel,omitempty"`
+
+	// PodTemplate customises the Redis pod: resources, scheduling
+	// constraints, and extra labels and annotations.
+	// +optional
+	PodTemplate *PodTemplateOverrides `json:"podTemplate,omitempty"`
+
+	// AdminPasswordRotation replaces the requirepass password of the default
+	// user on a schedule. The RotateAdminPasswordAnnotation triggers a
+	// rotation on demand whether or not this is set.
+	// +optional
+	AdminPasswordRotation *AdminPasswordRotation `json:"adminPasswordRotation,omitempty"`
+
+	// Persistence configures RDB snapshots and the append-only file. When
+	// omitted, Redis's built-in defaults apply: RDB snapshots and no
+	// append-only file. Changing it restarts the pod.
+	// +optional
+	Persistence *RedisPersistenceSpec `json:"persistence,omitempty"`
+
+	// MaxMemory caps the memory Redis uses for data. When omitted it defaults
+	// to 75% of the Redis container's memory limit in podTemplate.resources,
+	// leaving headroom for buffers and the fork used to persist, or to no cap
+	// when there is no limit. Changes are applied without a restart.
+	// +optional
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+
+	// EvictionPolicy is how Redis frees memory once it reaches MaxMemory.
+	// Changes are applied without a restart.
+	// +kubebuilder:default=noeviction
+	// +optional
+	EvictionPolicy RedisEvictionPolicy `json:"evictionPolicy,omitempty"`
+
+	// Metrics runs redis_exporter in the Redis pod and exposes it on the
+	// Service's "metrics" port.
+	// +optional
+	Metrics *MetricsSpec `json:"metrics,omitempty"`
 }
 
 // RedisDatabaseStatus defines the observed state of RedisDatabase.
@@ -42,12 +230,41 @@ type RedisDatabaseStatus struct {
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// AdminPasswordRotatedAt is when the admin password was last rotated.
+	// +optional
+	AdminPasswordRotatedAt *metav1.Time `json:"adminPasswordRotatedAt,omitempty"`
+
+	// AdminPasswordRotationRequest is the value of the
+	// RotateAdminPasswordAnnotation that the last on-demand rotation acted on.
+	// +optional
+	AdminPasswordRotationRequest string `json:"adminPasswordRotationRequest,omitempty"`
+
+	// PreviousAdminPasswordExpiresAt is when the admin password replaced by
+	// the last rotation stops being accepted. Until then Redis accepts both,
+	// so pods and probes still using the old one keep working.
+	// +optional
+	PreviousAdminPasswordExpiresAt *metav1.Time `json:"previousAdminPasswordExpiresAt,omitempty"`
+
+	// MasterOrdinal is the StatefulSet ordinal of the pod currently serving as
+	// the master. It changes only when Sentinel promotes a replica.
+	// +optional
+	MasterOrdinal int32 `json:"masterOrdinal,omitempty"`
+
+	// ClusterShards is the number of shards in cluster mode, as last observed
+	// by the operator: masters that own hash slots, or that replicas already
+	// follow while slots are still being moved onto them.
+	// +optional
+	ClusterShards int32 `json:"clusterShards,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=rdb,categories=games-hub
 // +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.storageSize`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.spec.replicas`
+// +kubebuilder:printcolumn:name="Master",type=integer,JSONPath=`.status.masterOrdinal`,priority=1
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 